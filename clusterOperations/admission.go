@@ -0,0 +1,271 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clusterOperations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"kufast/tools"
+	"net/http"
+	"strings"
+)
+
+// PodPolicy configures which pods the kufast admission webhook admits in tenant-targets.
+type PodPolicy struct {
+	// AllowedImages are the prefixes of the images tenants may use, e.g. registry.example.com/. All images are
+	// allowed, if it is empty.
+	AllowedImages []string
+}
+
+// requiredLimits are the resources every container in a tenant-target has to limit.
+var requiredLimits = []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory}
+
+// ValidatePod checks a pod against the policies of kufast and returns all violations. Pods outside of tenant-targets
+// are not checked. Pods are rejected, if they escape the node selector of their tenant-target, omit resource limits,
+// exceed the limits of their tenant-target or use an image, which is not allowed.
+func (c *Client) ValidatePod(pod *v1.Pod, policy PodPolicy) ([]string, error) {
+	namespace, err := c.clientset.CoreV1().Namespaces().Get(context.TODO(), pod.Namespace, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if namespace.Labels[tools.KUFAST_TENANT_LABEL] == "" {
+		return nil, nil
+	}
+
+	var violations []string
+
+	//Node selector of the tenant-target
+	selector, err := labels.ConvertSelectorToLabelsMap(namespace.Annotations[tools.KUFAST_NAMESPACE_NODE_SELECTOR_ANNOTATION])
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range selector {
+		if pod.Spec.NodeSelector[key] != value {
+			violations = append(violations, "the pod must select the nodes of its tenant-target with the node selector "+key+"="+value)
+		}
+	}
+
+	//Resource limits
+	containers := podContainers(pod)
+	for _, container := range containers {
+		for _, name := range requiredLimits {
+			if _, ok := container.Resources.Limits[name]; !ok {
+				violations = append(violations, "container "+container.Name+" has no "+string(name)+" limit")
+			}
+		}
+	}
+
+	violations = append(violations, imageViolations(containers, policy)...)
+
+	limitViolations, err := c.validatePodLimits(pod, containers)
+	if err != nil {
+		return nil, err
+	}
+	violations = append(violations, limitViolations...)
+
+	return violations, nil
+}
+
+// validatePodLimits checks the limits of the containers of a pod against the limit range and the quota of its
+// tenant-target. A single pod can never use more than the whole tenant-target.
+func (c *Client) validatePodLimits(pod *v1.Pod, containers []v1.Container) ([]string, error) {
+	var violations []string
+
+	limitRange, err := c.clientset.CoreV1().LimitRanges(pod.Namespace).Get(context.TODO(), pod.Namespace+"-limitrange", metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	if err == nil {
+		for _, item := range limitRange.Spec.Limits {
+			if item.Type != v1.LimitTypeContainer {
+				continue
+			}
+			for _, container := range containers {
+				for name, max := range item.Max {
+					if limit, ok := container.Resources.Limits[name]; ok && limit.Cmp(max) > 0 {
+						violations = append(violations, fmt.Sprintf("container %s exceeds the %s limit of %s per container", container.Name, name, max.String()))
+					}
+				}
+			}
+		}
+	}
+
+	quota, err := c.clientset.CoreV1().ResourceQuotas(pod.Namespace).Get(context.TODO(), pod.Namespace+"-limits", metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	if err == nil {
+		for name, limit := range podEffectiveLimits(pod) {
+			hard, ok := quota.Spec.Hard[v1.ResourceName("limits."+string(name))]
+			if ok && limit.Cmp(hard) > 0 {
+				violations = append(violations, fmt.Sprintf("the pod exceeds the %s limit of %s of its tenant-target", name, hard.String()))
+			}
+		}
+	}
+
+	return violations, nil
+}
+
+// podEffectiveLimits returns the limits of a pod the way the scheduler and the quota count them. Init containers run
+// one after another before the containers, so the larger of the largest init container and the sum of all
+// containers counts. The overhead of the runtime class is added.
+func podEffectiveLimits(pod *v1.Pod) v1.ResourceList {
+	total := v1.ResourceList{}
+	for _, container := range pod.Spec.Containers {
+		for name, limit := range container.Resources.Limits {
+			sum := total[name]
+			sum.Add(limit)
+			total[name] = sum
+		}
+	}
+	for _, container := range pod.Spec.InitContainers {
+		for name, limit := range container.Resources.Limits {
+			if current, ok := total[name]; !ok || limit.Cmp(current) > 0 {
+				total[name] = limit.DeepCopy()
+			}
+		}
+	}
+	for name, overhead := range pod.Spec.Overhead {
+		if sum, ok := total[name]; ok {
+			sum.Add(overhead)
+			total[name] = sum
+		}
+	}
+	return total
+}
+
+// imageViolations returns a violation for every container, whose image does not start with one of the allowed
+// prefixes of the policy.
+func imageViolations(containers []v1.Container, policy PodPolicy) []string {
+	if len(policy.AllowedImages) == 0 {
+		return nil
+	}
+
+	var violations []string
+	for _, container := range containers {
+		allowed := false
+		for _, prefix := range policy.AllowedImages {
+			if strings.HasPrefix(container.Image, prefix) {
+				allowed = true
+			}
+		}
+		if !allowed {
+			violations = append(violations, "container "+container.Name+" uses the image "+container.Image+", which is not allowed")
+		}
+	}
+	return violations
+}
+
+// podContainers returns the init containers and containers of a pod.
+func podContainers(pod *v1.Pod) []v1.Container {
+	return append(append([]v1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+}
+
+// NewAdmissionHandler creates the HTTP handler of the kufast admission webhook. It answers admission.k8s.io/v1
+// AdmissionReviews for pods and rejects pods violating the policy.
+func (c *Client) NewAdmissionHandler(policy PodPolicy) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Only POST requests are supported.", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var review admissionv1.AdmissionReview
+		err := json.NewDecoder(r.Body).Decode(&review)
+		if err != nil || review.Request == nil {
+			http.Error(w, "The request is no valid AdmissionReview.", http.StatusBadRequest)
+			return
+		}
+
+		review.Response = c.reviewPod(review.Request, policy)
+		review.Response.UID = review.Request.UID
+		review.Request = nil
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(review)
+	})
+}
+
+// reviewPod decides about a single admission request.
+func (c *Client) reviewPod(request *admissionv1.AdmissionRequest, policy PodPolicy) *admissionv1.AdmissionResponse {
+	if request.Kind.Kind != "Pod" || (request.SubResource != "" && request.SubResource != "ephemeralcontainers") {
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+
+	var pod v1.Pod
+	err := json.Unmarshal(request.Object.Raw, &pod)
+	if err != nil {
+		return deniedResponse(http.StatusBadRequest, errors.New("The pod cannot be decoded: "+err.Error()))
+	}
+	if pod.Namespace == "" {
+		pod.Namespace = request.Namespace
+	}
+
+	var violations []string
+	if request.Operation == admissionv1.Update {
+		//Only the images of existing pods can be changed. Ephemeral containers cannot have resource limits, so they
+		//are not allowed in tenant-targets at all.
+		namespace, err := c.clientset.CoreV1().Namespaces().Get(context.TODO(), pod.Namespace, metav1.GetOptions{})
+		if err != nil {
+			return deniedResponse(http.StatusInternalServerError, err)
+		}
+		if namespace.Labels[tools.KUFAST_TENANT_LABEL] != "" {
+			violations = imageViolations(podContainers(&pod), policy)
+			for _, container := range pod.Spec.EphemeralContainers {
+				violations = append(violations, "ephemeral container "+container.Name+" cannot be limited and is not allowed in tenant-targets")
+			}
+		}
+	} else {
+		violations, err = c.ValidatePod(&pod, policy)
+		if err != nil {
+			return deniedResponse(http.StatusInternalServerError, err)
+		}
+	}
+
+	if len(violations) > 0 {
+		return deniedResponse(http.StatusForbidden, errors.New("kufast denied the pod: "+strings.Join(violations, "; ")))
+	}
+	return &admissionv1.AdmissionResponse{Allowed: true}
+}
+
+// deniedResponse creates an admission response rejecting a request with the error.
+func deniedResponse(code int32, err error) *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    code,
+			Reason:  metav1.StatusReason(http.StatusText(int(code))),
+			Message: err.Error(),
+		},
+	}
+}
@@ -0,0 +1,198 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clusterOperations
+
+import (
+	"bytes"
+	"encoding/json"
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newAdmissionTestClient creates a client with the tenant-target tenant1-w1, which is limited to 500m CPU.
+func newAdmissionTestClient(t *testing.T) *Client {
+	t.Helper()
+	client, _ := newTestClient(t, "", newTestNode("w1", nil), newTestTenant("tenant1", ""),
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}})
+	awaitResult(t, client.CreateTenantTarget("tenant1", "w1", TenantTargetOptions{Memory: "1Gi", CPU: "500m", Storage: "10Gi", MinStorage: "1Gi", Pods: "2"}))
+	return client
+}
+
+// newAdmissionTestPod creates a pod in tenant1-w1, which complies with all policies.
+func newAdmissionTestPod() *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "tenant1-w1"},
+		Spec: v1.PodSpec{
+			NodeSelector: map[string]string{"kubernetes.io/hostname": "w1"},
+			Containers: []v1.Container{{
+				Name:  "nginx",
+				Image: "registry.example.com/nginx:1.25",
+				Resources: v1.ResourceRequirements{Limits: v1.ResourceList{
+					v1.ResourceCPU:    resource.MustParse("200m"),
+					v1.ResourceMemory: resource.MustParse("256Mi"),
+				}},
+			}},
+		},
+	}
+}
+
+// addAdmissionTestInitContainer adds an init container with the given CPU limit to the pod.
+func addAdmissionTestInitContainer(pod *v1.Pod, cpu string) {
+	pod.Spec.InitContainers = append(pod.Spec.InitContainers, v1.Container{
+		Name:  "init",
+		Image: "registry.example.com/busybox:1.36",
+		Resources: v1.ResourceRequirements{Limits: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse(cpu),
+			v1.ResourceMemory: resource.MustParse("64Mi"),
+		}},
+	})
+}
+
+func TestValidatePod(t *testing.T) {
+	client := newAdmissionTestClient(t)
+	policy := PodPolicy{AllowedImages: []string{"registry.example.com/"}}
+
+	tests := []struct {
+		name      string
+		modify    func(pod *v1.Pod)
+		violation string
+	}{
+		{"valid pod", func(pod *v1.Pod) {}, ""},
+		{"other namespace", func(pod *v1.Pod) { pod.Namespace = "default"; pod.Spec.NodeSelector = nil }, ""},
+		{"escaped node selector", func(pod *v1.Pod) { pod.Spec.NodeSelector["kubernetes.io/hostname"] = "w2" }, "node selector kubernetes.io/hostname=w1"},
+		{"missing limit", func(pod *v1.Pod) { delete(pod.Spec.Containers[0].Resources.Limits, v1.ResourceMemory) }, "has no memory limit"},
+		{"exceeded limit", func(pod *v1.Pod) { pod.Spec.Containers[0].Resources.Limits[v1.ResourceCPU] = resource.MustParse("1") }, "exceeds the cpu limit of 500m"},
+		{"exceeded container limit", func(pod *v1.Pod) {
+			pod.Spec.Containers[0].Resources.Limits[v1.ResourceEphemeralStorage] = resource.MustParse("20Gi")
+		}, "exceeds the ephemeral-storage limit of 10Gi per container"},
+		{"disallowed image", func(pod *v1.Pod) { pod.Spec.Containers[0].Image = "nginx" }, "uses the image nginx"},
+		{"exceeded limit by init container", func(pod *v1.Pod) { addAdmissionTestInitContainer(pod, "600m") }, "exceeds the cpu limit of 500m of its tenant-target"},
+		{"init container runs before containers", func(pod *v1.Pod) { addAdmissionTestInitContainer(pod, "400m") }, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pod := newAdmissionTestPod()
+			test.modify(pod)
+
+			violations, err := client.ValidatePod(pod, policy)
+			if err != nil {
+				t.Fatalf("ValidatePod: %v", err)
+			}
+			if test.violation == "" && len(violations) != 0 {
+				t.Errorf("expected no violations, got %v", violations)
+			}
+			if test.violation != "" && !strings.Contains(strings.Join(violations, "; "), test.violation) {
+				t.Errorf("expected a violation containing %q, got %v", test.violation, violations)
+			}
+		})
+	}
+}
+
+// postAdmissionReview sends an admission request for the pod or one of its subresources to the handler and returns
+// the response.
+func postAdmissionReview(t *testing.T, handler http.Handler, operation admissionv1.Operation, subResource string, pod *v1.Pod) *admissionv1.AdmissionResponse {
+	t.Helper()
+
+	raw, _ := json.Marshal(pod)
+	review := admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+		Request: &admissionv1.AdmissionRequest{
+			UID:         "uid-1",
+			Kind:        metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+			Namespace:   pod.Namespace,
+			Operation:   operation,
+			SubResource: subResource,
+			Object:      runtime.RawExtension{Raw: raw},
+		},
+	}
+	body, _ := json.Marshal(review)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/validate-pods", bytes.NewReader(body)))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	var result admissionv1.AdmissionReview
+	if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if result.Response == nil || result.Response.UID != "uid-1" {
+		t.Fatalf("expected a response for uid-1, got %+v", result.Response)
+	}
+	return result.Response
+}
+
+func TestAdmissionHandler(t *testing.T) {
+	client := newAdmissionTestClient(t)
+	handler := client.NewAdmissionHandler(PodPolicy{AllowedImages: []string{"registry.example.com/"}})
+
+	if response := postAdmissionReview(t, handler, admissionv1.Create, "", newAdmissionTestPod()); !response.Allowed {
+		t.Errorf("expected a valid pod to be allowed, got %v", response.Result)
+	}
+
+	pod := newAdmissionTestPod()
+	pod.Spec.NodeSelector = nil
+	response := postAdmissionReview(t, handler, admissionv1.Create, "", pod)
+	if response.Allowed || response.Result.Code != http.StatusForbidden {
+		t.Errorf("expected a pod escaping its node selector to be denied, got %+v", response)
+	}
+
+	//Existing pods are only checked for their images
+	if response := postAdmissionReview(t, handler, admissionv1.Update, "", pod); !response.Allowed {
+		t.Errorf("expected the update of an existing pod to be allowed, got %v", response.Result)
+	}
+	pod.Spec.Containers[0].Image = "nginx"
+	if response := postAdmissionReview(t, handler, admissionv1.Update, "", pod); response.Allowed {
+		t.Errorf("expected an update to a disallowed image to be denied")
+	}
+
+	//Ephemeral containers cannot be limited
+	pod = newAdmissionTestPod()
+	if response := postAdmissionReview(t, handler, admissionv1.Update, "status", pod); !response.Allowed {
+		t.Errorf("expected a status update to be allowed, got %v", response.Result)
+	}
+	pod.Spec.EphemeralContainers = []v1.EphemeralContainer{{EphemeralContainerCommon: v1.EphemeralContainerCommon{
+		Name: "debugger", Image: "registry.example.com/busybox",
+	}}}
+	response = postAdmissionReview(t, handler, admissionv1.Update, "ephemeralcontainers", pod)
+	if response.Allowed || !strings.Contains(response.Result.Message, "ephemeral container debugger") {
+		t.Errorf("expected an ephemeral container to be denied, got %+v", response)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/validate-pods", strings.NewReader("{}")))
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an empty review, got %d", recorder.Code)
+	}
+}
@@ -0,0 +1,507 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clusterOperations
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"kufast/tools"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// API_PREFIX is the path prefix of the current version of the kufast REST API.
+const API_PREFIX = "/api/v1/"
+
+// CreateTenantRequest is the body of a request creating a tenant via the REST API.
+type CreateTenantRequest struct {
+	Name    string              `json:"name"`
+	Targets []string            `json:"targets,omitempty"`
+	Limits  tools.ResourcesView `json:"limits,omitempty"`
+	// MinStorage is the amount of storage each pod of the tenant-targets must consume.
+	MinStorage string `json:"minStorage,omitempty"`
+	Upsert     bool   `json:"upsert,omitempty"`
+}
+
+// CreatePodRequest is the body of a request creating a pod in a tenant-target via the REST API.
+type CreatePodRequest struct {
+	Name         string   `json:"name"`
+	Image        string   `json:"image"`
+	Memory       string   `json:"memory,omitempty"`
+	CPU          string   `json:"cpu,omitempty"`
+	Storage      string   `json:"storage,omitempty"`
+	KeepAlive    bool     `json:"keepAlive,omitempty"`
+	Secrets      []string `json:"secrets,omitempty"`
+	DeploySecret string   `json:"deploySecret,omitempty"`
+	Ports        []int32  `json:"ports,omitempty"`
+	Command      []string `json:"command,omitempty"`
+}
+
+// CreateSecretRequest is the body of a request creating a secret in a tenant-target via the REST API.
+type CreateSecretRequest struct {
+	Name string `json:"name"`
+	Data string `json:"data"`
+}
+
+// apiError is the body of all failed requests.
+type apiError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// apiHandler serves the kufast REST API. Every request is executed by a client authenticated with the bearer token
+// of the caller, so callers have exactly the permissions of their token.
+type apiHandler struct {
+	newClient func(token string) (*Client, error)
+}
+
+// NewAPIHandler creates the HTTP handler of the kufast REST API. Requests are executed against the cluster of the
+// config, but with the bearer token of the caller instead of the credentials of the config.
+func NewAPIHandler(config *rest.Config) http.Handler {
+	return &apiHandler{newClient: func(token string) (*Client, error) {
+		callerConfig := rest.AnonymousClientConfig(config)
+		callerConfig.BearerToken = token
+		//The logging of API calls is kept, the credentials of the config are not
+		callerConfig.WrapTransport = config.WrapTransport
+		return NewClient(callerConfig, "")
+	}}
+}
+
+// ServeHTTP routes a request to the operation of its path. All paths start with API_PREFIX and address tenants,
+// tenant-targets and the pods and secrets within them:
+//
+//	/api/v1/tenants[/<tenant>[/credentials]]
+//	/api/v1/tenants/<tenant>/tenant-targets[/<target>]
+//	/api/v1/tenants/<tenant>/tenant-targets/<target>/pods[/<pod>[/logs]]
+//	/api/v1/tenants/<tenant>/tenant-targets/<target>/secrets[/<secret>]
+func (h *apiHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" || token == r.Header.Get("Authorization") {
+		writeAPIError(w, http.StatusUnauthorized, errors.New("A bearer token is required."))
+		return
+	}
+	if !strings.HasPrefix(r.URL.Path, API_PREFIX) {
+		writeAPIError(w, http.StatusNotFound, errors.New("Unknown API version. Use "+API_PREFIX+"."))
+		return
+	}
+
+	client, err := h.newClient(token)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if r.URL.Query().Get("wait") == "false" {
+		client.SetWaitOptions(WaitOptions{Wait: false, Timeout: DefaultWaitOptions.Timeout})
+	}
+
+	path := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, API_PREFIX), "/"), "/")
+	if path[0] != "tenants" {
+		writeAPIError(w, http.StatusNotFound, errors.New("Unknown resource "+path[0]+"."))
+		return
+	}
+
+	switch {
+	case len(path) == 1:
+		h.serveTenants(w, r, client)
+	case len(path) == 2:
+		h.serveTenant(w, r, client, path[1])
+	case len(path) == 3 && path[2] == "credentials":
+		h.serveCredentials(w, r, client, path[1])
+	case len(path) == 3 && path[2] == "tenant-targets":
+		h.serveTenantTargets(w, r, client, path[1], "")
+	case len(path) == 4 && path[2] == "tenant-targets":
+		h.serveTenantTargets(w, r, client, path[1], path[3])
+	case len(path) >= 5 && path[2] == "tenant-targets" && path[4] == "pods":
+		h.servePods(w, r, client, ScopeOptions{Tenant: path[1], Target: path[3]}, path[5:])
+	case len(path) >= 5 && path[2] == "tenant-targets" && path[4] == "secrets":
+		h.serveSecrets(w, r, client, ScopeOptions{Tenant: path[1], Target: path[3]}, path[5:])
+	default:
+		writeAPIError(w, http.StatusNotFound, errors.New("Unknown path "+r.URL.Path+"."))
+	}
+}
+
+// serveTenants lists and creates tenants.
+func (h *apiHandler) serveTenants(w http.ResponseWriter, r *http.Request, client *Client) {
+	switch r.Method {
+	case http.MethodGet:
+		tenants, err := client.ListTenants()
+		if err != nil {
+			writeAPIError(w, apiErrorCode(err), err)
+			return
+		}
+		var views []tools.View
+		for _, tenant := range tenants {
+			targets, err := client.ListTargets(tenant.Labels[tools.KUFAST_TENANT_LABEL], false)
+			if err != nil {
+				writeAPIError(w, apiErrorCode(err), err)
+				return
+			}
+			views = append(views, tools.NewTenantView(&tenant, targets))
+		}
+		writeAPIList(w, views)
+
+	case http.MethodPost:
+		var request CreateTenantRequest
+		if !readAPIRequest(w, r, &request) {
+			return
+		}
+		if !tools.IsAlphaNumeric(request.Name) {
+			writeAPIError(w, http.StatusBadRequest, tools.CreateAlphaNumericError(request.Name))
+			return
+		}
+
+		err := client.CreateTenant(request.Name, TenantOptions{Upsert: request.Upsert})
+		if err != nil {
+			writeAPIError(w, apiErrorCode(err), err)
+			return
+		}
+		opts := TenantTargetOptions{
+			Memory:     valueOrDefault(request.Limits.Memory, defaultTenantTargetOptions.Memory),
+			CPU:        valueOrDefault(request.Limits.CPU, defaultTenantTargetOptions.CPU),
+			Storage:    valueOrDefault(request.Limits.Storage, defaultTenantTargetOptions.Storage),
+			MinStorage: valueOrDefault(request.MinStorage, defaultTenantTargetOptions.MinStorage),
+			Pods:       valueOrDefault(request.Limits.Pods, defaultTenantTargetOptions.Pods),
+			Upsert:     request.Upsert,
+		}
+		for _, targetName := range request.Targets {
+			if res := <-client.CreateTenantTarget(request.Name, targetName, opts); res != nil {
+				writeAPIError(w, apiErrorCode(res), res)
+				return
+			}
+		}
+		h.writeTenant(w, client, request.Name, http.StatusCreated)
+
+	default:
+		writeAPIError(w, http.StatusMethodNotAllowed, errors.New("Use GET or POST."))
+	}
+}
+
+// serveTenant returns a single tenant.
+func (h *apiHandler) serveTenant(w http.ResponseWriter, r *http.Request, client *Client, tenantName string) {
+	if r.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, errors.New("Use GET."))
+		return
+	}
+	h.writeTenant(w, client, tenantName, http.StatusOK)
+}
+
+// writeTenant writes the view of a tenant with the given status code.
+func (h *apiHandler) writeTenant(w http.ResponseWriter, client *Client, tenantName string, code int) {
+	tenant, err := client.GetTenant(tenantName)
+	if err != nil {
+		writeAPIError(w, apiErrorCode(err), err)
+		return
+	}
+	targets, err := client.ListTargets(tenantName, false)
+	if err != nil {
+		writeAPIError(w, apiErrorCode(err), err)
+		return
+	}
+	writeAPIResponse(w, code, tools.NewTenantView(tenant, targets))
+}
+
+// serveCredentials returns the kubeconfig of a tenant. The lifetime is read from the duration query parameter.
+func (h *apiHandler) serveCredentials(w http.ResponseWriter, r *http.Request, client *Client, tenantName string) {
+	if r.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, errors.New("Use GET."))
+		return
+	}
+
+	opts := CredentialOptions{Duration: 8760 * time.Hour}
+	if duration := r.URL.Query().Get("duration"); duration != "" {
+		var err error
+		opts.Duration, err = time.ParseDuration(duration)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	config, err := client.GetTenantKubeconfig(tenantName, opts)
+	if err != nil {
+		writeAPIError(w, apiErrorCode(err), err)
+		return
+	}
+	data, err := clientcmd.Write(*config)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/yaml")
+	_, _ = w.Write(data)
+}
+
+// serveTenantTargets lists the tenant-targets of a tenant or returns a single one, if targetName is not empty.
+func (h *apiHandler) serveTenantTargets(w http.ResponseWriter, r *http.Request, client *Client, tenantName string, targetName string) {
+	if r.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, errors.New("Use GET."))
+		return
+	}
+
+	var views []tools.View
+	if targetName != "" {
+		namespace, err := client.GetTenantTarget(tenantName, targetName)
+		if err != nil {
+			writeAPIError(w, apiErrorCode(err), err)
+			return
+		}
+		view, err := tenantTargetView(client, tenantName, targetName, namespace)
+		if err != nil {
+			writeAPIError(w, apiErrorCode(err), err)
+			return
+		}
+		writeAPIResponse(w, http.StatusOK, view)
+		return
+	}
+
+	namespaces, err := client.ListTenantTargets(tenantName)
+	if err != nil {
+		writeAPIError(w, apiErrorCode(err), err)
+		return
+	}
+	for _, namespace := range namespaces {
+		view, err := tenantTargetView(client, tenantName, strings.TrimPrefix(namespace.Name, tenantName+"-"), namespace)
+		if err != nil {
+			writeAPIError(w, apiErrorCode(err), err)
+			return
+		}
+		views = append(views, view)
+	}
+	writeAPIList(w, views)
+}
+
+// servePods lists, creates, returns and deletes pods of a tenant-target and returns their logs.
+func (h *apiHandler) servePods(w http.ResponseWriter, r *http.Request, client *Client, scope ScopeOptions, path []string) {
+	switch {
+	case len(path) == 0 && r.Method == http.MethodGet:
+		pods, err := client.ListTenantTargetPods(scope.Tenant, scope.Target)
+		if err != nil {
+			writeAPIError(w, apiErrorCode(err), err)
+			return
+		}
+		var views []tools.View
+		for _, pod := range pods {
+			views = append(views, tools.NewPodView(&pod, nil))
+		}
+		writeAPIList(w, views)
+
+	case len(path) == 0 && r.Method == http.MethodPost:
+		var request CreatePodRequest
+		if !readAPIRequest(w, r, &request) {
+			return
+		}
+		if res := <-client.CreatePod(CreatePodOptions{
+			ScopeOptions: scope,
+			Name:         request.Name,
+			Image:        request.Image,
+			Memory:       request.Memory,
+			CPU:          request.CPU,
+			Storage:      request.Storage,
+			KeepAlive:    request.KeepAlive,
+			Secrets:      request.Secrets,
+			DeploySecret: request.DeploySecret,
+			Ports:        request.Ports,
+			Command:      request.Command,
+		}); res != nil {
+			writeAPIError(w, apiErrorCode(res), res)
+			return
+		}
+		h.writePod(w, client, scope, request.Name, http.StatusCreated)
+
+	case len(path) == 1 && r.Method == http.MethodGet:
+		h.writePod(w, client, scope, path[0], http.StatusOK)
+
+	case len(path) == 1 && r.Method == http.MethodDelete:
+		if res := <-client.DeletePod(path[0], scope); res != nil {
+			writeAPIError(w, apiErrorCode(res), res)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	case len(path) == 2 && path[1] == "logs" && r.Method == http.MethodGet:
+		tailLines := int64(100)
+		if lines := r.URL.Query().Get("tailLines"); lines != "" {
+			var err error
+			tailLines, err = strconv.ParseInt(lines, 10, 64)
+			if err != nil {
+				writeAPIError(w, http.StatusBadRequest, err)
+				return
+			}
+		}
+		logs, err := client.GetPodLogs(path[0], scope, tailLines, false)
+		if err != nil {
+			writeAPIError(w, apiErrorCode(err), err)
+			return
+		}
+		defer logs.Close()
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = io.Copy(w, logs)
+
+	default:
+		writeAPIError(w, http.StatusNotFound, errors.New("Unknown path or method "+r.Method+" "+r.URL.Path+"."))
+	}
+}
+
+// writePod writes the view of a pod including its events with the given status code.
+func (h *apiHandler) writePod(w http.ResponseWriter, client *Client, scope ScopeOptions, podName string, code int) {
+	pod, err := client.GetPod(podName, scope)
+	if err != nil {
+		writeAPIError(w, apiErrorCode(err), err)
+		return
+	}
+	events, err := client.GetPodEvents(podName, scope)
+	if err != nil {
+		writeAPIError(w, apiErrorCode(err), err)
+		return
+	}
+	writeAPIResponse(w, code, tools.NewPodView(pod, events))
+}
+
+// serveSecrets lists, creates, returns and deletes the secrets of a tenant-target.
+func (h *apiHandler) serveSecrets(w http.ResponseWriter, r *http.Request, client *Client, scope ScopeOptions, path []string) {
+	switch {
+	case len(path) == 0 && r.Method == http.MethodGet:
+		namespaceName, err := client.GetTenantTargetName(scope)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, err)
+			return
+		}
+		secrets, err := client.ListSecrets(scope.Tenant)
+		if err != nil {
+			writeAPIError(w, apiErrorCode(err), err)
+			return
+		}
+		var views []tools.View
+		for _, secret := range secrets {
+			if secret.Namespace == namespaceName {
+				views = append(views, tools.NewSecretView(&secret, ""))
+			}
+		}
+		writeAPIList(w, views)
+
+	case len(path) == 0 && r.Method == http.MethodPost:
+		var request CreateSecretRequest
+		if !readAPIRequest(w, r, &request) {
+			return
+		}
+		err := client.CreateSecret(request.Name, request.Data, scope)
+		if err != nil {
+			writeAPIError(w, apiErrorCode(err), err)
+			return
+		}
+		secret, err := client.GetSecret(request.Name, scope)
+		if err != nil {
+			writeAPIError(w, apiErrorCode(err), err)
+			return
+		}
+		writeAPIResponse(w, http.StatusCreated, tools.NewSecretView(secret, ""))
+
+	case len(path) == 1 && r.Method == http.MethodGet:
+		secret, err := client.GetSecret(path[0], scope)
+		if err != nil {
+			writeAPIError(w, apiErrorCode(err), err)
+			return
+		}
+		writeAPIResponse(w, http.StatusOK, tools.NewSecretView(secret, "secret"))
+
+	case len(path) == 1 && r.Method == http.MethodDelete:
+		if res := <-client.DeleteSecret(path[0], scope); res != nil {
+			writeAPIError(w, apiErrorCode(res), res)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeAPIError(w, http.StatusNotFound, errors.New("Unknown path or method "+r.Method+" "+r.URL.Path+"."))
+	}
+}
+
+// tenantTargetView creates the view of a tenant-target. A missing quota is shown as missing limits.
+func tenantTargetView(client *Client, tenantName string, targetName string, namespace *v1.Namespace) (tools.TenantTargetView, error) {
+	quota, err := client.GetTenantTargetQuota(tenantName, targetName)
+	if err != nil {
+		quota = nil
+	}
+	pods, err := client.ListTenantTargetPods(tenantName, targetName)
+	if err != nil {
+		return tools.TenantTargetView{}, err
+	}
+	return tools.NewTenantTargetView(namespace, quota, pods), nil
+}
+
+// readAPIRequest decodes the JSON body of a request. If it is invalid, an error is written and false is returned.
+func readAPIRequest(w http.ResponseWriter, r *http.Request, request interface{}) bool {
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, errors.New("Invalid request body: "+err.Error()))
+		return false
+	}
+	return true
+}
+
+// writeAPIList writes a list of views in the same format as the json output of the CLI.
+func writeAPIList(w http.ResponseWriter, views []tools.View) {
+	if views == nil {
+		views = []tools.View{}
+	}
+	writeAPIResponse(w, http.StatusOK, tools.ViewList{Kind: "List", Items: views})
+}
+
+// writeAPIResponse writes obj as JSON with the given status code.
+func writeAPIResponse(w http.ResponseWriter, code int, obj interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(obj)
+}
+
+// writeAPIError writes an error with the given status code.
+func writeAPIError(w http.ResponseWriter, code int, err error) {
+	writeAPIResponse(w, code, apiError{Code: code, Message: err.Error()})
+}
+
+// apiErrorCode returns the HTTP status code of an error. Errors of the Kubernetes API keep their code, so callers
+// see, if they lack permissions or an object does not exist.
+func apiErrorCode(err error) int {
+	var status apierrors.APIStatus
+	if errors.As(err, &status) && status.Status().Code != 0 {
+		return int(status.Status().Code)
+	}
+	return http.StatusInternalServerError
+}
+
+// valueOrDefault returns the value or the default value, if the value is empty.
+func valueOrDefault(value string, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,148 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clusterOperations
+
+import (
+	"bytes"
+	"encoding/json"
+	"kufast/tools"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newAPITestHandler creates an API handler, whose requests are all executed by the client.
+func newAPITestHandler(client *Client) (*apiHandler, *[]string) {
+	var tokens []string
+	return &apiHandler{newClient: func(token string) (*Client, error) {
+		tokens = append(tokens, token)
+		return client, nil
+	}}, &tokens
+}
+
+// callAPI sends a request with a bearer token to the handler and decodes the JSON response into result.
+func callAPI(t *testing.T, handler http.Handler, method string, path string, body interface{}, result interface{}) int {
+	t.Helper()
+
+	var reader bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&reader).Encode(body)
+	}
+	request := httptest.NewRequest(method, path, &reader)
+	request.Header.Set("Authorization", "Bearer token-caller")
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	if result != nil && recorder.Body.Len() > 0 {
+		if err := json.Unmarshal(recorder.Body.Bytes(), result); err != nil {
+			t.Fatalf("decode response of %s %s: %v", method, path, err)
+		}
+	}
+	return recorder.Code
+}
+
+func TestAPIAuthentication(t *testing.T) {
+	client, _ := newTestClient(t, "")
+	handler, tokens := newAPITestHandler(client)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/tenants", nil))
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401 without token, got %d", recorder.Code)
+	}
+
+	if code := callAPI(t, handler, http.MethodGet, "/api/v1/tenants", nil, nil); code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", code)
+	}
+	if len(*tokens) != 1 || (*tokens)[0] != "token-caller" {
+		t.Errorf("expected the request to be executed with the token of the caller, got %v", *tokens)
+	}
+}
+
+func TestAPITenants(t *testing.T) {
+	client, _ := newTestClient(t, "", newTestNode("w1", nil))
+	handler, _ := newAPITestHandler(client)
+
+	var tenant tools.TenantView
+	code := callAPI(t, handler, http.MethodPost, "/api/v1/tenants", CreateTenantRequest{Name: "tenant1", Targets: []string{"w1"}}, &tenant)
+	if code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", code)
+	}
+	if tenant.Name != "tenant1" || tenant.DefaultTarget != "w1" || len(tenant.Targets) != 1 {
+		t.Errorf("unexpected tenant %+v", tenant)
+	}
+
+	var tenantTargets struct{ Items []tools.TenantTargetView }
+	if code := callAPI(t, handler, http.MethodGet, "/api/v1/tenants/tenant1/tenant-targets", nil, &tenantTargets); code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", code)
+	}
+	if len(tenantTargets.Items) != 1 || tenantTargets.Items[0].Limits.CPU != defaultTenantTargetOptions.CPU {
+		t.Errorf("expected tenant1-w1 with the default limits, got %+v", tenantTargets.Items)
+	}
+
+	var apiErr apiError
+	if code := callAPI(t, handler, http.MethodGet, "/api/v1/tenants/tenant9", nil, &apiErr); code != http.StatusNotFound || apiErr.Message == "" {
+		t.Errorf("expected status 404 with a message for an unknown tenant, got %d %+v", code, apiErr)
+	}
+	if code := callAPI(t, handler, http.MethodPost, "/api/v1/tenants", CreateTenantRequest{Name: "tenant_1"}, nil); code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an invalid name, got %d", code)
+	}
+}
+
+func TestAPIPodsAndSecrets(t *testing.T) {
+	client, _ := newTestClient(t, "", newTestNode("w1", nil), newTestTenant("tenant1", ""))
+	awaitResult(t, client.CreateTenantTarget("tenant1", "w1", TenantTargetOptions{}))
+	handler, _ := newAPITestHandler(client)
+	base := "/api/v1/tenants/tenant1/tenant-targets/w1"
+
+	var pod tools.PodView
+	code := callAPI(t, handler, http.MethodPost, base+"/pods", CreatePodRequest{Name: "nginx", Image: "nginx", CPU: "200m"}, &pod)
+	if code != http.StatusCreated || pod.Name != "nginx" || pod.Status != "Running" {
+		t.Fatalf("expected the running pod nginx, got %d %+v", code, pod)
+	}
+	var pods struct{ Items []tools.PodView }
+	callAPI(t, handler, http.MethodGet, base+"/pods", nil, &pods)
+	if len(pods.Items) != 1 {
+		t.Errorf("expected 1 pod, got %+v", pods.Items)
+	}
+	if code := callAPI(t, handler, http.MethodDelete, base+"/pods/nginx", nil, nil); code != http.StatusNoContent {
+		t.Errorf("expected status 204, got %d", code)
+	}
+
+	if code := callAPI(t, handler, http.MethodPost, base+"/secrets", CreateSecretRequest{Name: "password", Data: "s3cret"}, nil); code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", code)
+	}
+	var secrets struct{ Items []tools.SecretView }
+	callAPI(t, handler, http.MethodGet, base+"/secrets", nil, &secrets)
+	if len(secrets.Items) != 1 || secrets.Items[0].Data != "" {
+		t.Errorf("expected 1 secret without data, got %+v", secrets.Items)
+	}
+	if code := callAPI(t, handler, http.MethodDelete, base+"/secrets/password", nil, nil); code != http.StatusNoContent {
+		t.Errorf("expected status 204, got %d", code)
+	}
+	if code := callAPI(t, handler, http.MethodGet, base+"/volumes", nil, nil); code != http.StatusNotFound {
+		t.Errorf("expected status 404 for an unknown path, got %d", code)
+	}
+}
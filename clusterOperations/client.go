@@ -0,0 +1,278 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clusterOperations
+
+import (
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"kufast/tools"
+	"strings"
+)
+
+// Client bundles the connection to a Kubernetes cluster. All cluster operations of kufast are executed through a
+// Client, so it only needs to be created once per program run.
+type Client struct {
+	clientset kubernetes.Interface
+	dynamic   dynamic.Interface
+	config    *rest.Config
+	namespace string
+	cluster   string
+	wait      WaitOptions
+	rateLimit RateLimitOptions
+	dryRun    string
+}
+
+// ScopeOptions selects the tenant and target an operation works on. Empty values are resolved from the namespace
+// of the client, which is usually the namespace encoded in the tenants .kubeconfig file.
+type ScopeOptions struct {
+	Tenant string
+	Target string
+}
+
+// RateLimitOptions limits the load the operations of a client put on the cluster.
+type RateLimitOptions struct {
+	// QPS is the number of requests per second sent to the cluster on average.
+	QPS float32
+	// Burst is the number of requests, which may be sent at once before QPS applies.
+	Burst int
+	// Concurrency limits how many tenant-targets are queried at the same time by operations spanning several
+	// tenant-targets.
+	Concurrency int
+}
+
+// DefaultRateLimitOptions are the rate limits of a new Client. They allow listing a tenant with many tenant-targets
+// without being throttled by the low defaults of client-go.
+var DefaultRateLimitOptions = RateLimitOptions{QPS: 50, Burst: 100, Concurrency: 10}
+
+// NewClient creates a new Client from a rest config. The namespace is used to resolve tenant and target, if an
+// operation does not specify them explicitly. If the config does not limit the rate of requests itself, the
+// DefaultRateLimitOptions are used.
+func NewClient(config *rest.Config, namespace string) (*Client, error) {
+	if config.QPS == 0 && config.Burst == 0 && config.RateLimiter == nil {
+		config = rest.CopyConfig(config)
+		config.QPS = DefaultRateLimitOptions.QPS
+		config.Burst = DefaultRateLimitOptions.Burst
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{clientset: clientset, dynamic: dynamicClient, config: config, namespace: namespace,
+		wait: DefaultWaitOptions, rateLimit: DefaultRateLimitOptions}, nil
+}
+
+// NewClientFromInterface creates a new Client from an existing clientset. Operations that need a rest config
+// (exec, the generation of tenant credentials and custom resources) are not available on such a client.
+func NewClientFromInterface(clientset kubernetes.Interface, namespace string) *Client {
+	return &Client{clientset: clientset, namespace: namespace, wait: DefaultWaitOptions, rateLimit: DefaultRateLimitOptions}
+}
+
+// NewClientFromCmd creates a new Client based on the credentials the user entered when using this program. Commands
+// create it once and pass it to all operations, so the kubeconfig is parsed only once per program run.
+func NewClientFromCmd(cmd *cobra.Command) (*Client, error) {
+	config, namespace, err := tools.GetClusterConfig(cmd, "")
+	if err != nil {
+		return nil, err
+	}
+	return newClientFromCmd(cmd, config, namespace, "")
+}
+
+// NewClientsFromCmd creates one Client per cluster selected with the --clusters flag. Without the flag, a single
+// Client for the current context of the kubeconfig is returned.
+func NewClientsFromCmd(cmd *cobra.Command) ([]*Client, error) {
+	clusters, err := tools.GetClusterNames(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if len(clusters) == 0 {
+		client, err := NewClientFromCmd(cmd)
+		if err != nil {
+			return nil, err
+		}
+		return []*Client{client}, nil
+	}
+
+	var clients []*Client
+	for _, cluster := range clusters {
+		config, namespace, err := tools.GetClusterConfig(cmd, cluster)
+		if err != nil {
+			return nil, err
+		}
+		client, err := newClientFromCmd(cmd, config, namespace, cluster)
+		if err != nil {
+			return nil, err
+		}
+		clients = append(clients, client)
+	}
+	return clients, nil
+}
+
+// newClientFromCmd creates the Client of a command for a rest config. The rate limits and the logging selected with
+// the flags of the command are applied to the config first, so the clientset is only created once.
+func newClientFromCmd(cmd *cobra.Command, config *rest.Config, namespace string, cluster string) (*Client, error) {
+	rateLimit, err := rateLimitOptionsFromCmd(cmd)
+	if err != nil {
+		return nil, err
+	}
+	logOpts, err := LogOptionsFromCmd(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	config = rest.CopyConfig(config)
+	config.QPS = rateLimit.QPS
+	config.Burst = rateLimit.Burst
+	WrapLogging(config, logOpts)
+
+	client, err := NewClient(config, namespace)
+	if err != nil {
+		return nil, err
+	}
+	client.cluster = cluster
+	client.wait = waitOptionsFromCmd(cmd)
+	client.rateLimit = rateLimit
+
+	err = setDryRunFromCmd(client, cmd)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// SetRateLimitOptions changes the rate limits of the client. The clientset of the client is recreated for new QPS
+// and burst values.
+func (c *Client) SetRateLimitOptions(opts RateLimitOptions) error {
+	if c.config != nil && (opts.QPS != c.config.QPS || opts.Burst != c.config.Burst) {
+		config := rest.CopyConfig(c.config)
+		config.QPS = opts.QPS
+		config.Burst = opts.Burst
+		err := c.setConfig(config)
+		if err != nil {
+			return err
+		}
+	}
+	c.rateLimit = opts
+	return nil
+}
+
+// setConfig replaces the rest config of the client and recreates its clientset and dynamic client from it.
+func (c *Client) setConfig(config *rest.Config) error {
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	c.config = config
+	c.clientset = clientset
+	c.dynamic = dynamicClient
+	return nil
+}
+
+// rateLimitOptionsFromCmd reads the rate limits from the global qps and burst flags of the command.
+func rateLimitOptionsFromCmd(cmd *cobra.Command) (RateLimitOptions, error) {
+	opts := DefaultRateLimitOptions
+	if cmd.Flags().Lookup("qps") != nil {
+		opts.QPS, _ = cmd.Flags().GetFloat32("qps")
+	}
+	if cmd.Flags().Lookup("burst") != nil {
+		opts.Burst, _ = cmd.Flags().GetInt("burst")
+	}
+	if opts.QPS <= 0 || opts.Burst <= 0 {
+		return opts, tools.NewError(tools.ERROR_KIND_USAGE, "--qps and --burst have to be greater than 0.")
+	}
+	return opts, nil
+}
+
+// waitOptionsFromCmd reads the wait options from the global flags of the command.
+func waitOptionsFromCmd(cmd *cobra.Command) WaitOptions {
+	opts := DefaultWaitOptions
+	if cmd.Flags().Lookup("wait") != nil {
+		wait, _ := cmd.Flags().GetBool("wait")
+		noWait, _ := cmd.Flags().GetBool("no-wait")
+		opts.Wait = wait && !noWait
+	}
+	if cmd.Flags().Lookup("timeout") != nil {
+		opts.Timeout, _ = cmd.Flags().GetDuration("timeout")
+	}
+	return opts
+}
+
+// setDryRunFromCmd configures the dry run of a client from the dry-run and print-manifests flags of the command.
+// The output of the dry run is printed by the command once it has finished.
+func setDryRunFromCmd(client *Client, cmd *cobra.Command) error {
+	mode, err := tools.GetDryRunMode(cmd)
+	if err != nil {
+		return err
+	}
+	return client.SetDryRun(DryRunOptions{Mode: mode, PrintManifests: tools.GetPrintManifests(cmd), Out: tools.DryRunOutput()})
+}
+
+// Clientset returns the Kubernetes clientset used by this client.
+func (c *Client) Clientset() kubernetes.Interface {
+	return c.clientset
+}
+
+// Cluster returns the name of the cluster of this client. It is empty for clients of the current context.
+func (c *Client) Cluster() string {
+	return c.cluster
+}
+
+// GetTargetName returns the name of the target of the client, which is read from its namespace. It is empty, if
+// the namespace does not select a tenant-target.
+func (c *Client) GetTargetName() string {
+	tenantName := tools.GetTenantFromNamespace(c.namespace)
+	if c.namespace == tenantName {
+		return ""
+	}
+	return strings.TrimPrefix(c.namespace, tenantName+"-")
+}
+
+// SetCluster sets the name of the cluster of this client. The name is used for the contexts of tenant credentials
+// spanning several clusters.
+func (c *Client) SetCluster(cluster string) {
+	c.cluster = cluster
+}
+
+// GetTenantName returns the name of the tenant passed to it. If it is empty, the tenant is read from the namespace
+// of the client.
+func (c *Client) GetTenantName(tenantName string) (string, error) {
+	if tenantName != "" {
+		return tenantName, nil
+	}
+	if c.namespace == "" {
+		return "", tools.NewError(tools.ERROR_KIND_USAGE, "No tenant specified and no tenant found in your .kubeconfig file.")
+	}
+	return tools.GetTenantFromNamespace(c.namespace), nil
+}
@@ -0,0 +1,139 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clusterOperations
+
+import (
+	"errors"
+	"fmt"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFanOutLimitsConcurrency(t *testing.T) {
+	client := NewClientFromInterface(fake.NewSimpleClientset(), "")
+	if err := client.SetRateLimitOptions(RateLimitOptions{QPS: 50, Burst: 100, Concurrency: 3}); err != nil {
+		t.Fatalf("SetRateLimitOptions: %v", err)
+	}
+
+	var mu sync.Mutex
+	active, maxActive := 0, 0
+	called := make([]bool, 20)
+	err := client.fanOut(len(called), func(i int) error {
+		mu.Lock()
+		active++
+		if active > maxActive {
+			maxActive = active
+		}
+		called[i] = true
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("fanOut: %v", err)
+	}
+	if maxActive > 3 {
+		t.Errorf("expected at most 3 concurrent calls, got %d", maxActive)
+	}
+	for i, ok := range called {
+		if !ok {
+			t.Errorf("expected fn to be called for index %d", i)
+		}
+	}
+}
+
+func TestFanOutReturnsFirstError(t *testing.T) {
+	client := NewClientFromInterface(fake.NewSimpleClientset(), "")
+
+	err := client.fanOut(10, func(i int) error {
+		if i == 2 || i == 7 {
+			return errors.New(fmt.Sprint("failed ", i))
+		}
+		return nil
+	})
+	if err == nil || err.Error() != "failed 2" {
+		t.Errorf("expected the error of the lowest index, got %v", err)
+	}
+}
+
+func TestListTenantPodsManyTargets(t *testing.T) {
+	var targets []string
+	objects := []runtime.Object{}
+	for i := 0; i < 30; i++ {
+		target := fmt.Sprintf("w%02d", i)
+		targets = append(targets, target)
+		objects = append(objects, &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "tenant1-" + target}})
+	}
+	objects = append(objects, newTestTenant("tenant1", "w00", targets...))
+	client, _ := newTestClient(t, "tenant1-w00", objects...)
+
+	pods, err := client.ListTenantPods("tenant1")
+	if err != nil {
+		t.Fatalf("ListTenantPods: %v", err)
+	}
+	namespaces := map[string]bool{}
+	for _, pod := range pods {
+		namespaces[pod.Namespace] = true
+	}
+	if len(pods) != 30 || len(namespaces) != 30 {
+		t.Errorf("expected one pod in each of the 30 tenant-targets, got %d pods in %d namespaces", len(pods), len(namespaces))
+	}
+}
+
+func TestNewClientRateLimits(t *testing.T) {
+	client, err := NewClient(&rest.Config{Host: "https://127.0.0.1:1"}, "")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if client.config.QPS != DefaultRateLimitOptions.QPS || client.config.Burst != DefaultRateLimitOptions.Burst {
+		t.Errorf("expected the default rate limits, got qps %v and burst %d", client.config.QPS, client.config.Burst)
+	}
+
+	if err := client.SetRateLimitOptions(RateLimitOptions{QPS: 5, Burst: 10, Concurrency: 2}); err != nil {
+		t.Fatalf("SetRateLimitOptions: %v", err)
+	}
+	if client.config.QPS != 5 || client.config.Burst != 10 || client.rateLimit.Concurrency != 2 {
+		t.Errorf("expected the new rate limits, got qps %v, burst %d and concurrency %d", client.config.QPS, client.config.Burst, client.rateLimit.Concurrency)
+	}
+
+	client, err = NewClient(&rest.Config{Host: "https://127.0.0.1:1", QPS: 20, Burst: 40}, "")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if client.config.QPS != 20 || client.config.Burst != 40 {
+		t.Errorf("expected the rate limits of the config to be kept, got qps %v and burst %d", client.config.QPS, client.config.Burst)
+	}
+}
@@ -0,0 +1,364 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clusterOperations
+
+import (
+	"context"
+	"errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"kufast/objectFactory"
+	"kufast/tools"
+	"log"
+	"strings"
+	"time"
+)
+
+// The phases the controller reports in the status of custom resources.
+const (
+	resourcePhaseReady       = "Ready"
+	resourcePhaseFailed      = "Failed"
+	resourcePhaseTerminating = "Terminating"
+)
+
+// controllerKey identifies a custom resource in the queue of the controller.
+type controllerKey struct {
+	resource schema.GroupVersionResource
+	name     string
+}
+
+// Controller reconciles the kufast custom resources. For every Tenant, TenantTarget and TargetGroup it creates the
+// same objects the kufast CLI creates and removes them again, once the custom resource is deleted.
+type Controller struct {
+	client *Client
+	queue  workqueue.RateLimitingInterface
+	resync time.Duration
+}
+
+// NewController creates a new controller working through the client. All custom resources are reconciled again
+// after the resync period, which restores objects that have been modified outside of kufast.
+func NewController(client *Client, resync time.Duration) *Controller {
+	return &Controller{
+		client: client,
+		queue:  workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		resync: resync,
+	}
+}
+
+// Run watches the custom resources and reconciles them with the given number of workers, until the context is done.
+func (ct *Controller) Run(ctx context.Context, workers int) error {
+	if ct.client.dynamic == nil {
+		return errors.New("Custom resources are not available on this client.")
+	}
+	defer ct.queue.ShutDown()
+
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(ct.client.dynamic, ct.resync)
+	for _, resource := range []schema.GroupVersionResource{objectFactory.TargetGroupResource, objectFactory.TenantResource, objectFactory.TenantTargetResource} {
+		resource := resource
+		enqueue := func(obj interface{}) {
+			if object, ok := obj.(*unstructured.Unstructured); ok {
+				ct.queue.Add(controllerKey{resource: resource, name: object.GetName()})
+			}
+		}
+		_, err := factory.ForResource(resource).Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    enqueue,
+			UpdateFunc: func(_, obj interface{}) { enqueue(obj) },
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	factory.Start(ctx.Done())
+	for resource, synced := range factory.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			return errors.New("Failed to watch " + resource.Resource + ". Are the custom resources installed?")
+		}
+	}
+
+	for i := 0; i < workers; i++ {
+		go wait.UntilWithContext(ctx, ct.runWorker, time.Second)
+	}
+	<-ctx.Done()
+	return nil
+}
+
+// runWorker reconciles custom resources from the queue, until the queue is shut down.
+func (ct *Controller) runWorker(ctx context.Context) {
+	for {
+		item, shutdown := ct.queue.Get()
+		if shutdown {
+			return
+		}
+		key := item.(controllerKey)
+
+		err := ct.reconcile(ctx, key)
+		if err != nil {
+			log.Printf("Failed to reconcile %s %s: %v", key.resource.Resource, key.name, err)
+			ct.queue.AddRateLimited(key)
+		} else {
+			ct.queue.Forget(key)
+		}
+		ct.queue.Done(key)
+	}
+}
+
+// reconcile brings the objects of a single custom resource in line with its spec and reports the result in its status.
+func (ct *Controller) reconcile(ctx context.Context, key controllerKey) error {
+	resources := ct.client.dynamic.Resource(key.resource)
+	object, err := resources.Get(ctx, key.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	//Deleted custom resources are only removed, once their objects are gone
+	if object.GetDeletionTimestamp() != nil {
+		if !hasFinalizer(object) {
+			return nil
+		}
+		err = ct.finalize(key.resource, object)
+		if err != nil {
+			_ = ct.setStatus(ctx, key.resource, object, resourcePhaseTerminating, err.Error())
+			return err
+		}
+		object.SetFinalizers(removeFinalizer(object.GetFinalizers()))
+		_, err = resources.Update(ctx, object, metav1.UpdateOptions{})
+		return err
+	}
+
+	if !hasFinalizer(object) {
+		object.SetFinalizers(append(object.GetFinalizers(), tools.KUFAST_FINALIZER))
+		object, err = resources.Update(ctx, object, metav1.UpdateOptions{})
+		if err != nil {
+			return err
+		}
+	}
+
+	message, err := ct.apply(key.resource, object)
+	if err != nil {
+		statusErr := ct.setStatus(ctx, key.resource, object, resourcePhaseFailed, err.Error())
+		if statusErr != nil {
+			log.Printf("Failed to update the status of %s %s: %v", key.resource.Resource, key.name, statusErr)
+		}
+		return err
+	}
+	if message != "" {
+		log.Printf("%s %s: %s", key.resource.Resource, key.name, message)
+	}
+	return ct.setStatus(ctx, key.resource, object, resourcePhaseReady, message)
+}
+
+// apply creates or updates the objects of a custom resource. Existing objects are updated to the spec, so limits
+// changed outside of kufast are reverted on every resync. The returned message reports such reverted changes.
+func (ct *Controller) apply(resource schema.GroupVersionResource, object *unstructured.Unstructured) (string, error) {
+	switch resource {
+	case objectFactory.TenantResource:
+		var tenant objectFactory.Tenant
+		if err := objectFactory.FromUnstructured(object, &tenant); err != nil {
+			return "", err
+		}
+		err := ct.client.CreateTenant(tenant.Name, TenantOptions{Upsert: true})
+		if err != nil {
+			return "", err
+		}
+		if tenant.Spec.DefaultTarget == "" {
+			return "", nil
+		}
+		if !ct.client.IsValidTarget(tenant.Spec.DefaultTarget, tenant.Name, false) {
+			return "", errors.New("The tenant has no access to its default target " + tenant.Spec.DefaultTarget + ".")
+		}
+		return "", ct.client.UpdateTenantDefaultDeployTarget(tenant.Spec.DefaultTarget, tenant.Name)
+
+	case objectFactory.TenantTargetResource:
+		var tenantTarget objectFactory.TenantTarget
+		if err := objectFactory.FromUnstructured(object, &tenantTarget); err != nil {
+			return "", err
+		}
+		limits := tenantTarget.Spec.Limits
+		opts := TenantTargetOptions{
+			Memory:     limits.Memory,
+			CPU:        limits.CPU,
+			Storage:    limits.Storage,
+			MinStorage: limits.MinStorage,
+			Pods:       limits.Pods,
+			Upsert:     true,
+		}
+
+		//Limits of an existing quota, which differ from the spec, have been changed outside of kufast
+		var message string
+		desired := LayoutTenantTarget{Memory: limits.Memory, CPU: limits.CPU, Storage: limits.Storage, Pods: limits.Pods}.options()
+		if details, err := ct.client.diffTenantTargetLimits(tenantTarget.Spec.Tenant, tenantTarget.Spec.Target, desired); err == nil && len(details) > 0 {
+			message = "Reverted limits changed outside of kufast: " + strings.Join(details, ", ")
+		}
+
+		if res := <-ct.client.CreateTenantTarget(tenantTarget.Spec.Tenant, tenantTarget.Spec.Target, opts); res != nil {
+			return "", res
+		}
+		return message, nil
+
+	case objectFactory.TargetGroupResource:
+		var targetGroup objectFactory.TargetGroup
+		if err := objectFactory.FromUnstructured(object, &targetGroup); err != nil {
+			return "", err
+		}
+		return "", ct.client.SetTargetGroupToNodes(targetGroup.Name, targetGroup.Spec.Nodes)
+	}
+	return "", errors.New("Unknown resource " + resource.Resource)
+}
+
+// finalize deletes the objects of a deleted custom resource. Objects that are already gone are skipped.
+func (ct *Controller) finalize(resource schema.GroupVersionResource, object *unstructured.Unstructured) error {
+	switch resource {
+	case objectFactory.TenantResource:
+		return ct.finalizeTenant(object.GetName())
+
+	case objectFactory.TenantTargetResource:
+		var tenantTarget objectFactory.TenantTarget
+		if err := objectFactory.FromUnstructured(object, &tenantTarget); err != nil {
+			return err
+		}
+		tenantName, targetName := tenantTarget.Spec.Tenant, tenantTarget.Spec.Target
+		if _, err := ct.client.GetTenantTarget(tenantName, targetName); err == nil {
+			if res := <-ct.client.DeleteTenantTarget(targetName, tenantName); res != nil {
+				return res
+			}
+		} else if !apierrors.IsNotFound(err) {
+			return err
+		}
+		if ct.client.IsValidTarget(targetName, tenantName, false) {
+			return ct.client.DeleteTargetFromTenant(targetName, tenantName)
+		}
+		return nil
+
+	case objectFactory.TargetGroupResource:
+		return ct.client.DeleteTargetGroupFromNodes(object.GetName())
+	}
+	return errors.New("Unknown resource " + resource.Resource)
+}
+
+// finalizeTenant deletes a tenant along with its tenant-targets, so no namespace of the tenant keeps running without
+// its tenant. The TenantTarget custom resources of the tenant are deleted first and the tenant is only deleted, once
+// the controller has finalized them.
+func (ct *Controller) finalizeTenant(tenantName string) error {
+	resources := ct.client.dynamic.Resource(objectFactory.TenantTargetResource)
+	selector := metav1.ListOptions{LabelSelector: tools.KUFAST_TENANT_LABEL + "=" + tenantName}
+	tenantTargets, err := resources.List(context.TODO(), selector)
+	if err != nil {
+		return err
+	}
+	for _, tenantTarget := range tenantTargets.Items {
+		if tenantTarget.GetDeletionTimestamp() != nil {
+			continue
+		}
+		err = resources.Delete(context.TODO(), tenantTarget.GetName(), metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	tenantTargets, err = resources.List(context.TODO(), selector)
+	if err != nil {
+		return err
+	}
+	if len(tenantTargets.Items) > 0 {
+		return errors.New("Waiting for the tenant-targets of tenant " + tenantName + " to be deleted.")
+	}
+
+	if _, err := ct.client.GetTenant(tenantName); apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	//Tenant-targets created without custom resources
+	namespaces, err := ct.client.listExistingTenantTargets(tenantName)
+	if err != nil {
+		return err
+	}
+	var deleteTargetOps []<-chan error
+	for _, namespace := range namespaces {
+		deleteTargetOps = append(deleteTargetOps, ct.client.DeleteTenantTarget(strings.TrimPrefix(namespace.Name, tenantName+"-"), tenantName))
+	}
+	var deleteErr error
+	for _, op := range deleteTargetOps {
+		if res := <-op; res != nil && deleteErr == nil {
+			deleteErr = res
+		}
+	}
+	if deleteErr != nil {
+		return deleteErr
+	}
+	return ct.client.DeleteTenant(tenantName)
+}
+
+// setStatus reports the phase of a custom resource along with a message, e.g. the error that occurred. The status is
+// only written, if it changed.
+func (ct *Controller) setStatus(ctx context.Context, resource schema.GroupVersionResource, object *unstructured.Unstructured, phase string, message string) error {
+	status := objectFactory.ResourceStatus{Phase: phase, Message: message, ObservedGeneration: object.GetGeneration()}
+
+	var current objectFactory.ResourceStatus
+	if existing, ok := object.Object["status"].(map[string]interface{}); ok {
+		_ = objectFactory.FromUnstructured(&unstructured.Unstructured{Object: existing}, &current)
+	}
+	if current == status {
+		return nil
+	}
+
+	content, convErr := objectFactory.ToUnstructured(&status)
+	if convErr != nil {
+		return convErr
+	}
+	object.Object["status"] = content.Object
+	_, updateErr := ct.client.dynamic.Resource(resource).UpdateStatus(ctx, object, metav1.UpdateOptions{})
+	return updateErr
+}
+
+// hasFinalizer returns true, if the kufast controller still has to clean up after the custom resource.
+func hasFinalizer(object *unstructured.Unstructured) bool {
+	for _, finalizer := range object.GetFinalizers() {
+		if finalizer == tools.KUFAST_FINALIZER {
+			return true
+		}
+	}
+	return false
+}
+
+// removeFinalizer returns the finalizers without the finalizer of the kufast controller.
+func removeFinalizer(finalizers []string) []string {
+	var results []string
+	for _, finalizer := range finalizers {
+		if finalizer != tools.KUFAST_FINALIZER {
+			results = append(results, finalizer)
+		}
+	}
+	return results
+}
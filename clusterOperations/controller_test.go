@@ -0,0 +1,240 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clusterOperations
+
+import (
+	"context"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"kufast/objectFactory"
+	"testing"
+)
+
+// reconcileResource reconciles a single custom resource and fails the test on errors.
+func reconcileResource(t *testing.T, controller *Controller, resource schema.GroupVersionResource, name string) {
+	t.Helper()
+	if err := controller.reconcile(context.TODO(), controllerKey{resource: resource, name: name}); err != nil {
+		t.Fatalf("reconcile %s %s: %v", resource.Resource, name, err)
+	}
+}
+
+// getResource returns a custom resource from the fake dynamic client.
+func getResource(t *testing.T, client *Client, resource schema.GroupVersionResource, name string) *unstructured.Unstructured {
+	t.Helper()
+	object, err := client.dynamic.Resource(resource).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get %s %s: %v", resource.Resource, name, err)
+	}
+	return object
+}
+
+func TestControllerReconcilesResources(t *testing.T) {
+	client, _ := newTestClient(t, "", newTestNode("w1", nil), newTestNode("w2", nil))
+	controller := NewController(client, 0)
+
+	if err := client.SetTargetGroupResource("edge", []string{"w2"}); err != nil {
+		t.Fatalf("SetTargetGroupResource: %v", err)
+	}
+	if err := client.CreateTenantResource("tenant1", TenantOptions{}); err != nil {
+		t.Fatalf("CreateTenantResource: %v", err)
+	}
+	if err := client.CreateTenantTargetResource("tenant1", "edge", defaultTenantTargetOptions); err != nil {
+		t.Fatalf("CreateTenantTargetResource: %v", err)
+	}
+
+	reconcileResource(t, controller, objectFactory.TargetGroupResource, "edge")
+	reconcileResource(t, controller, objectFactory.TenantResource, "tenant1")
+	reconcileResource(t, controller, objectFactory.TenantTargetResource, "tenant1-edge")
+
+	groups, err := client.ListTargetGroups()
+	if err != nil {
+		t.Fatalf("ListTargetGroups: %v", err)
+	}
+	if len(groups["edge"]) != 1 || groups["edge"][0] != "w2" {
+		t.Errorf("expected target-group edge on w2, got %v", groups)
+	}
+	if !client.IsValidTarget("edge", "tenant1", false) {
+		t.Errorf("expected tenant1 to have access to edge")
+	}
+	drifts, err := client.CheckTenant("tenant1")
+	if err != nil {
+		t.Fatalf("CheckTenant: %v", err)
+	}
+	if len(drifts) != 0 {
+		t.Errorf("expected the controller to create all objects, got drift %v", drifts)
+	}
+
+	tenantTarget := getResource(t, client, objectFactory.TenantTargetResource, "tenant1-edge")
+	if phase, _, _ := unstructured.NestedString(tenantTarget.Object, "status", "phase"); phase != resourcePhaseReady {
+		t.Errorf("expected phase %s, got %q", resourcePhaseReady, phase)
+	}
+	if !hasFinalizer(tenantTarget) {
+		t.Errorf("expected the finalizer to be added, got %v", tenantTarget.GetFinalizers())
+	}
+}
+
+func TestControllerReportsFailures(t *testing.T) {
+	client, _ := newTestClient(t, "", newTestNode("w1", nil))
+	controller := NewController(client, 0)
+
+	if err := client.CreateTenantTargetResource("tenant1", "w1", defaultTenantTargetOptions); err != nil {
+		t.Fatalf("CreateTenantTargetResource: %v", err)
+	}
+	err := controller.reconcile(context.TODO(), controllerKey{resource: objectFactory.TenantTargetResource, name: "tenant1-w1"})
+	if err == nil {
+		t.Fatalf("expected an error for a missing tenant")
+	}
+
+	tenantTarget := getResource(t, client, objectFactory.TenantTargetResource, "tenant1-w1")
+	phase, _, _ := unstructured.NestedString(tenantTarget.Object, "status", "phase")
+	message, _, _ := unstructured.NestedString(tenantTarget.Object, "status", "message")
+	if phase != resourcePhaseFailed || message != err.Error() {
+		t.Errorf("expected phase %s with message %q, got %s with %q", resourcePhaseFailed, err.Error(), phase, message)
+	}
+}
+
+func TestControllerFinalizesResources(t *testing.T) {
+	client, _ := newTestClient(t, "", newTestNode("w1", nil))
+	controller := NewController(client, 0)
+
+	_ = client.CreateTenantResource("tenant1", TenantOptions{})
+	_ = client.CreateTenantTargetResource("tenant1", "w1", defaultTenantTargetOptions)
+	reconcileResource(t, controller, objectFactory.TenantResource, "tenant1")
+	reconcileResource(t, controller, objectFactory.TenantTargetResource, "tenant1-w1")
+
+	//The fake dynamic client does not know finalizers, so the deletion is simulated
+	resources := client.dynamic.Resource(objectFactory.TenantTargetResource)
+	tenantTarget := getResource(t, client, objectFactory.TenantTargetResource, "tenant1-w1")
+	now := metav1.Now()
+	tenantTarget.SetDeletionTimestamp(&now)
+	if _, err := resources.Update(context.TODO(), tenantTarget, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("update tenant-target: %v", err)
+	}
+	reconcileResource(t, controller, objectFactory.TenantTargetResource, "tenant1-w1")
+
+	if _, err := client.GetTenantTarget("tenant1", "w1"); err == nil {
+		t.Errorf("expected the tenant-target to be deleted")
+	}
+	if client.IsValidTarget("w1", "tenant1", false) {
+		t.Errorf("expected the access of tenant1 to w1 to be removed")
+	}
+	tenantTarget = getResource(t, client, objectFactory.TenantTargetResource, "tenant1-w1")
+	if hasFinalizer(tenantTarget) {
+		t.Errorf("expected the finalizer to be removed")
+	}
+
+	if err := client.DeleteTenantResource("tenant1"); err != nil {
+		t.Fatalf("DeleteTenantResource: %v", err)
+	}
+	if _, err := resources.Get(context.TODO(), "tenant1-w1", metav1.GetOptions{}); err == nil {
+		t.Errorf("expected the tenant-target resources of the tenant to be deleted")
+	}
+}
+
+func TestInstallCustomResourceDefinitions(t *testing.T) {
+	client, _ := newTestClient(t, "")
+
+	for i := 0; i < 2; i++ {
+		if err := client.InstallCustomResourceDefinitions(); err != nil {
+			t.Fatalf("InstallCustomResourceDefinitions: %v", err)
+		}
+	}
+	definitions, err := client.dynamic.Resource(objectFactory.CustomResourceDefinitionResource).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("list definitions: %v", err)
+	}
+	if len(definitions.Items) != 3 {
+		t.Errorf("expected 3 definitions, got %d", len(definitions.Items))
+	}
+}
+
+func TestControllerFinalizesTenantTargets(t *testing.T) {
+	client, _ := newTestClient(t, "", newTestNode("w1", nil), newTestNode("w2", nil))
+	controller := NewController(client, 0)
+
+	_ = client.CreateTenantResource("tenant1", TenantOptions{})
+	_ = client.CreateTenantTargetResource("tenant1", "w1", defaultTenantTargetOptions)
+	reconcileResource(t, controller, objectFactory.TenantResource, "tenant1")
+	reconcileResource(t, controller, objectFactory.TenantTargetResource, "tenant1-w1")
+	//A tenant-target created without a custom resource
+	if err := client.AddTargetToTenant("w2", "tenant1"); err != nil {
+		t.Fatalf("AddTargetToTenant: %v", err)
+	}
+	awaitResult(t, client.CreateTenantTarget("tenant1", "w2", defaultTenantTargetOptions))
+
+	resources := client.dynamic.Resource(objectFactory.TenantResource)
+	tenant := getResource(t, client, objectFactory.TenantResource, "tenant1")
+	now := metav1.Now()
+	tenant.SetDeletionTimestamp(&now)
+	if _, err := resources.Update(context.TODO(), tenant, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("update tenant: %v", err)
+	}
+	reconcileResource(t, controller, objectFactory.TenantResource, "tenant1")
+
+	for _, target := range []string{"w1", "w2"} {
+		if _, err := client.GetTenantTarget("tenant1", target); err == nil {
+			t.Errorf("expected the tenant-target tenant1-%s to be deleted", target)
+		}
+	}
+	if _, err := client.GetTenant("tenant1"); err == nil {
+		t.Errorf("expected the tenant to be deleted")
+	}
+	if _, err := client.dynamic.Resource(objectFactory.TenantTargetResource).Get(context.TODO(), "tenant1-w1", metav1.GetOptions{}); err == nil {
+		t.Errorf("expected the tenant-target resource to be deleted")
+	}
+}
+
+func TestControllerReportsRevertedLimits(t *testing.T) {
+	client, clientset := newTestClient(t, "", newTestNode("w1", nil))
+	controller := NewController(client, 0)
+
+	_ = client.CreateTenantResource("tenant1", TenantOptions{})
+	_ = client.CreateTenantTargetResource("tenant1", "w1", defaultTenantTargetOptions)
+	reconcileResource(t, controller, objectFactory.TenantResource, "tenant1")
+	reconcileResource(t, controller, objectFactory.TenantTargetResource, "tenant1-w1")
+
+	quota, err := client.GetTenantTargetQuota("tenant1", "w1")
+	if err != nil {
+		t.Fatalf("GetTenantTargetQuota: %v", err)
+	}
+	quota.Spec.Hard[v1.ResourceLimitsMemory] = resource.MustParse("8Gi")
+	if _, err := clientset.CoreV1().ResourceQuotas("tenant1-w1").Update(context.TODO(), quota, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("update quota: %v", err)
+	}
+	reconcileResource(t, controller, objectFactory.TenantTargetResource, "tenant1-w1")
+
+	tenantTarget := getResource(t, client, objectFactory.TenantTargetResource, "tenant1-w1")
+	message, _, _ := unstructured.NestedString(tenantTarget.Object, "status", "message")
+	if message != "Reverted limits changed outside of kufast: memory: 8Gi -> 1Gi" {
+		t.Errorf("expected the reverted limit in the status, got %q", message)
+	}
+	quota, _ = client.GetTenantTargetQuota("tenant1", "w1")
+	if memory := quota.Spec.Hard[v1.ResourceLimitsMemory]; memory.String() != "1Gi" {
+		t.Errorf("expected the limit to be reverted, got %s", memory.String())
+	}
+}
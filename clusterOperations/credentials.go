@@ -0,0 +1,250 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clusterOperations
+
+import (
+	"context"
+	"errors"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/util/retry"
+	"kufast/objectFactory"
+	"kufast/tools"
+	"os"
+	"sigs.k8s.io/yaml"
+	"time"
+)
+
+// CredentialOptions holds the parameters for issuing the credentials of a tenant.
+type CredentialOptions struct {
+	// Duration is the lifetime of a token minted by the TokenRequest API. A duration of 0 issues a long-lived
+	// token, which is stored in a service-account-token secret.
+	Duration time.Duration
+}
+
+// GetTenantKubeconfig creates the credentials of a tenant. If the tenant has no tenant-target yet,
+// the default namespace is set to the tenant-target user.
+func (c *Client) GetTenantKubeconfig(tenantName string, opts CredentialOptions) (*api.Config, error) {
+	if c.config == nil {
+		return nil, errors.New("This operation requires a client created from a rest config.")
+	}
+
+	tenant, err := c.clientset.CoreV1().ServiceAccounts("default").Get(context.TODO(), tenantName+"-user", metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	token, caData, err := c.getTenantToken(tenantName, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	newConfig := &api.Config{
+		Kind:       "Config",
+		APIVersion: "v1",
+		Clusters: map[string]*api.Cluster{
+			"default-cluster": {
+				Server:                   c.config.Host,
+				CertificateAuthorityData: caData,
+			},
+		},
+		AuthInfos: map[string]*api.AuthInfo{
+			tenantName + "-user": {
+				Token: token,
+			},
+		},
+		Contexts: map[string]*api.Context{
+			"default-context": {
+				Cluster:   "default-cluster",
+				Namespace: tenantName,
+				AuthInfo:  tenantName + "-user",
+			},
+		},
+		CurrentContext: "default-context",
+	}
+
+	if tenant.ObjectMeta.Labels[tools.KUFAST_TENANT_DEFAULT_LABEL] != "" {
+		newConfig.Contexts["default-context"].Namespace = tenantName + "-" + tenant.ObjectMeta.Labels[tools.KUFAST_TENANT_DEFAULT_LABEL]
+	}
+
+	return newConfig, nil
+}
+
+// getTenantToken returns a token of the tenant user and the CA data of the cluster. Tokens with a duration are
+// minted by the TokenRequest API. If the duration is 0 or the cluster does not support the TokenRequest API,
+// a long-lived token is read from a service-account-token secret.
+func (c *Client) getTenantToken(tenantName string, opts CredentialOptions) (string, []byte, error) {
+	if opts.Duration > 0 {
+		expirationSeconds := int64(opts.Duration.Seconds())
+		tokenRequest, err := c.clientset.CoreV1().ServiceAccounts("default").CreateToken(context.TODO(), tenantName+"-user",
+			&authenticationv1.TokenRequest{
+				Spec: authenticationv1.TokenRequestSpec{ExpirationSeconds: &expirationSeconds},
+			}, metav1.CreateOptions{})
+		if err == nil {
+			caData, err := c.getClusterCAData()
+			if err != nil {
+				return "", nil, err
+			}
+			return tokenRequest.Status.Token, caData, nil
+		} else if !apierrors.IsNotFound(err) && !apierrors.IsMethodNotSupported(err) {
+			return "", nil, err
+		}
+	}
+
+	secret, err := c.getTenantTokenSecret(tenantName)
+	if err != nil {
+		return "", nil, err
+	}
+	caData := secret.Data[v1.ServiceAccountRootCAKey]
+	if len(caData) == 0 {
+		caData, err = c.getClusterCAData()
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	return string(secret.Data[v1.ServiceAccountTokenKey]), caData, nil
+}
+
+// getTenantTokenSecret creates the service-account-token secret of a tenant, if it does not exist yet, and
+// waits until the cluster has populated its token.
+func (c *Client) getTenantTokenSecret(tenantName string) (*v1.Secret, error) {
+	_, err := c.clientset.CoreV1().Secrets("default").Create(context.TODO(), objectFactory.NewTenantTokenSecret(tenantName, "default"), metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return nil, err
+	}
+
+	var secret *v1.Secret
+	err = c.waitForObject("default", tenantName+"-user-token", &v1.Secret{},
+		func(options metav1.ListOptions) (runtime.Object, error) {
+			return c.clientset.CoreV1().Secrets("default").List(context.TODO(), options)
+		},
+		func(options metav1.ListOptions) (watch.Interface, error) {
+			return c.clientset.CoreV1().Secrets("default").Watch(context.TODO(), options)
+		},
+		func(obj runtime.Object) (bool, error) {
+			if obj == nil {
+				return false, nil
+			}
+			secret = obj.(*v1.Secret)
+			return len(secret.Data[v1.ServiceAccountTokenKey]) > 0, nil
+		})
+	if err == wait.ErrWaitTimeout {
+		return nil, tools.NewError(tools.ERROR_KIND_TIMEOUT, `Operation Timeout. The token of tenant `+tenantName+` has not been populated yet. 
+Please ensure it is fully initialized and get its credentials from 'kufast get tenant-creds'`)
+	} else if err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// getClusterCAData returns the CA data of the cluster. It is taken from the client config if possible, otherwise
+// from the kube-root-ca.crt config map, which is published to every namespace. Credentials without CA data fail on
+// their first use, so an error is returned, if neither holds the CA.
+func (c *Client) getClusterCAData() ([]byte, error) {
+	if len(c.config.CAData) > 0 {
+		return c.config.CAData, nil
+	}
+	if c.config.CAFile != "" {
+		return os.ReadFile(c.config.CAFile)
+	}
+
+	configMap, err := c.clientset.CoreV1().ConfigMaps("default").Get(context.TODO(), "kube-root-ca.crt", metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	if err != nil || configMap.Data[v1.ServiceAccountRootCAKey] == "" {
+		return nil, tools.NewError(tools.ERROR_KIND_NOT_FOUND, "The CA of the cluster could not be found. Please add certificate-authority-data to your kubeconfig.")
+	}
+	return []byte(configMap.Data[v1.ServiceAccountRootCAKey]), nil
+}
+
+// RevokeTenantCredentials invalidates all credentials of a tenant. Token secrets are deleted and the service account
+// is recreated, which revokes all tokens minted by the TokenRequest API, as they are bound to the service account.
+// The time of the revocation is recorded on the new service account. The service account is recreated from the
+// deleted one, so the targets of the tenant are kept. If this fails even after retrying, the returned error contains
+// the manifest to restore it.
+func (c *Client) RevokeTenantCredentials(tenantName string) error {
+	tenant, err := c.clientset.CoreV1().ServiceAccounts("default").Get(context.TODO(), tenantName+"-user", metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	secrets, err := c.clientset.CoreV1().Secrets("default").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, secret := range secrets.Items {
+		if secret.Type == v1.SecretTypeServiceAccountToken && secret.Annotations[v1.ServiceAccountNameKey] == tenant.Name {
+			err = c.clientset.CoreV1().Secrets("default").Delete(context.TODO(), secret.Name, metav1.DeleteOptions{})
+			if err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+		}
+	}
+
+	err = c.clientset.CoreV1().ServiceAccounts("default").Delete(context.TODO(), tenant.Name, metav1.DeleteOptions{})
+	if err != nil {
+		return err
+	}
+
+	//Keep labels and annotations, as they hold the targets of the tenant
+	newTenant := &v1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{
+		Name:        tenant.Name,
+		Namespace:   tenant.Namespace,
+		Labels:      tenant.Labels,
+		Annotations: tenant.Annotations,
+	}}
+	if newTenant.ObjectMeta.Annotations == nil {
+		newTenant.ObjectMeta.Annotations = map[string]string{}
+	}
+	newTenant.ObjectMeta.Annotations[tools.KUFAST_TENANT_CREDENTIALS_ROTATED_ANNOTATION] = time.Now().UTC().Format(time.RFC3339)
+
+	//The old service account may still be terminating, so the creation is retried on all errors
+	err = retry.OnError(retry.DefaultBackoff, func(error) bool { return true }, func() error {
+		_, err := c.clientset.CoreV1().ServiceAccounts("default").Create(context.TODO(), newTenant, metav1.CreateOptions{})
+		return err
+	})
+	if err != nil {
+		manifest, _ := yaml.Marshal(newTenant)
+		return tools.WithMessage(err, "The credentials of tenant "+tenantName+" have been revoked, but its service account could not be recreated: "+
+			err.Error()+"\nThe service account must be restored, e.g. with 'kubectl apply -f', from this manifest:\n"+string(manifest))
+	}
+	return nil
+}
+
+// RotateTenantCredentials revokes all credentials of a tenant and issues new ones.
+func (c *Client) RotateTenantCredentials(tenantName string, opts CredentialOptions) (*api.Config, error) {
+	err := c.RevokeTenantCredentials(tenantName)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetTenantKubeconfig(tenantName, opts)
+}
@@ -0,0 +1,228 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clusterOperations
+
+import (
+	"context"
+	"errors"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/clientcmd"
+	"kufast/tools"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetTenantKubeconfig(t *testing.T) {
+	client, _ := newTestClient(t, "")
+	if err := client.CreateTenant("tenant1", TenantOptions{}); err != nil {
+		t.Fatalf("CreateTenant: %v", err)
+	}
+	if err := client.UpdateTenantDefaultDeployTarget("w1", "tenant1"); err != nil {
+		t.Fatalf("UpdateTenantDefaultDeployTarget: %v", err)
+	}
+
+	config, err := client.GetTenantKubeconfig("tenant1", CredentialOptions{})
+	if err != nil {
+		t.Fatalf("GetTenantKubeconfig: %v", err)
+	}
+	data, err := clientcmd.Write(*config)
+	if err != nil {
+		t.Fatalf("write kubeconfig: %v", err)
+	}
+	compareGolden(t, "tenant-kubeconfig", data)
+}
+
+func TestGetTenantKubeconfigTokenRequest(t *testing.T) {
+	client, clientset := newTestClient(t, "")
+	client.config.CAData = []byte("ca")
+	if err := client.CreateTenant("tenant1", TenantOptions{}); err != nil {
+		t.Fatalf("CreateTenant: %v", err)
+	}
+
+	var expirationSeconds int64
+	clientset.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() == "token" {
+			tokenRequest := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenRequest)
+			expirationSeconds = *tokenRequest.Spec.ExpirationSeconds
+		}
+		return false, nil, nil
+	})
+
+	config, err := client.GetTenantKubeconfig("tenant1", CredentialOptions{Duration: time.Hour})
+	if err != nil {
+		t.Fatalf("GetTenantKubeconfig: %v", err)
+	}
+	if expirationSeconds != 3600 {
+		t.Errorf("expected a token request for 3600 seconds, got %d", expirationSeconds)
+	}
+	if token := config.AuthInfos["tenant1-user"].Token; token != "token-tenant1-user" {
+		t.Errorf("unexpected token %q", token)
+	}
+	if ca := string(config.Clusters["default-cluster"].CertificateAuthorityData); ca != "ca" {
+		t.Errorf("unexpected CA data %q", ca)
+	}
+
+	secrets, _ := clientset.CoreV1().Secrets("default").List(context.TODO(), metav1.ListOptions{})
+	if len(secrets.Items) != 0 {
+		t.Errorf("expected no token secret, got %d secrets", len(secrets.Items))
+	}
+}
+
+func TestGetTenantKubeconfigTokenRequestFallback(t *testing.T) {
+	client, clientset := newTestClient(t, "")
+	if err := client.CreateTenant("tenant1", TenantOptions{}); err != nil {
+		t.Fatalf("CreateTenant: %v", err)
+	}
+
+	clientset.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() == "token" {
+			return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "serviceaccounts/token"}, "tenant1-user")
+		}
+		return false, nil, nil
+	})
+
+	config, err := client.GetTenantKubeconfig("tenant1", CredentialOptions{Duration: time.Hour})
+	if err != nil {
+		t.Fatalf("GetTenantKubeconfig: %v", err)
+	}
+	if token := config.AuthInfos["tenant1-user"].Token; token != "token-tenant1-user" {
+		t.Errorf("unexpected token %q", token)
+	}
+	if _, err := clientset.CoreV1().Secrets("default").Get(context.TODO(), "tenant1-user-token", metav1.GetOptions{}); err != nil {
+		t.Errorf("expected a token secret: %v", err)
+	}
+
+	clientset.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("forbidden")
+	})
+	if _, err := client.GetTenantKubeconfig("tenant1", CredentialOptions{Duration: time.Hour}); err == nil {
+		t.Errorf("expected other TokenRequest errors to be returned")
+	}
+}
+
+func TestGetTenantKubeconfigMissingCA(t *testing.T) {
+	client, clientset := newTestClient(t, "", newTestTenant("tenant1", ""))
+	client.config.CAData = nil
+
+	if _, err := client.GetTenantKubeconfig("tenant1", CredentialOptions{Duration: time.Hour}); tools.GetErrorKind(err) != tools.ERROR_KIND_NOT_FOUND {
+		t.Errorf("expected an error for a cluster without CA, got %v", err)
+	}
+
+	_, err := clientset.CoreV1().ConfigMaps("default").Create(context.TODO(), &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "kube-root-ca.crt", Namespace: "default"},
+		Data:       map[string]string{v1.ServiceAccountRootCAKey: "root-ca"},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("create config map: %v", err)
+	}
+	config, err := client.GetTenantKubeconfig("tenant1", CredentialOptions{Duration: time.Hour})
+	if err != nil {
+		t.Fatalf("GetTenantKubeconfig: %v", err)
+	}
+	if ca := string(config.Clusters["default-cluster"].CertificateAuthorityData); ca != "root-ca" {
+		t.Errorf("expected the CA of kube-root-ca.crt, got %q", ca)
+	}
+}
+
+func TestRevokeTenantCredentials(t *testing.T) {
+	client, clientset := newTestClient(t, "", newTestNode("w1", nil), newTestTenant("tenant1", "w1", "w1"))
+	if _, err := client.GetTenantKubeconfig("tenant1", CredentialOptions{}); err != nil {
+		t.Fatalf("GetTenantKubeconfig: %v", err)
+	}
+
+	if err := client.RevokeTenantCredentials("tenant1"); err != nil {
+		t.Fatalf("RevokeTenantCredentials: %v", err)
+	}
+
+	if _, err := clientset.CoreV1().Secrets("default").Get(context.TODO(), "tenant1-user-token", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the token secret to be deleted, got %v", err)
+	}
+	tenant, err := client.GetTenant("tenant1")
+	if err != nil {
+		t.Fatalf("GetTenant: %v", err)
+	}
+	if tenant.Labels[tools.KUFAST_TENANT_NODEACCESS_LABEL+"w1"] != "true" || tenant.Labels[tools.KUFAST_TENANT_DEFAULT_LABEL] != "w1" {
+		t.Errorf("expected the targets of the tenant to be kept, got %v", tenant.Labels)
+	}
+	if _, err := time.Parse(time.RFC3339, tenant.Annotations[tools.KUFAST_TENANT_CREDENTIALS_ROTATED_ANNOTATION]); err != nil {
+		t.Errorf("expected the rotation time to be recorded: %v", err)
+	}
+}
+
+func TestRevokeTenantCredentialsRecreateFails(t *testing.T) {
+	client, clientset := newTestClient(t, "", newTestNode("w1", nil), newTestTenant("tenant1", "w1", "w1"))
+
+	//The first attempt fails, as the old service account is still terminating
+	attempts := 0
+	clientset.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "" {
+			return false, nil, nil
+		}
+		attempts++
+		if attempts == 1 {
+			return true, nil, apierrors.NewAlreadyExists(schema.GroupResource{Resource: "serviceaccounts"}, "tenant1-user")
+		}
+		return false, nil, nil
+	})
+	if err := client.RevokeTenantCredentials("tenant1"); err != nil {
+		t.Fatalf("RevokeTenantCredentials: %v", err)
+	}
+	if tenant, err := client.GetTenant("tenant1"); err != nil || tenant.Labels[tools.KUFAST_TENANT_NODEACCESS_LABEL+"w1"] != "true" {
+		t.Errorf("expected the service account to be recreated with its targets, got %v, %v", tenant, err)
+	}
+
+	clientset.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "" {
+			return false, nil, nil
+		}
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "serviceaccounts"}, "tenant1-user", errors.New("denied by webhook"))
+	})
+	err := client.RevokeTenantCredentials("tenant1")
+	if err == nil || !strings.Contains(err.Error(), "must be restored") || !strings.Contains(err.Error(), tools.KUFAST_TENANT_NODEACCESS_LABEL+"w1") {
+		t.Errorf("expected an error with the manifest of the service account, got %v", err)
+	}
+}
+
+func TestRotateTenantCredentials(t *testing.T) {
+	client, _ := newTestClient(t, "", newTestTenant("tenant1", ""))
+
+	config, err := client.RotateTenantCredentials("tenant1", CredentialOptions{Duration: time.Hour})
+	if err != nil {
+		t.Fatalf("RotateTenantCredentials: %v", err)
+	}
+	if token := config.AuthInfos["tenant1-user"].Token; token != "token-tenant1-user" {
+		t.Errorf("unexpected token %q", token)
+	}
+	if _, err := client.RotateTenantCredentials("tenant2", CredentialOptions{}); err == nil {
+		t.Errorf("expected an error for an unknown tenant")
+	}
+}
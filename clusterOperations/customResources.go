@@ -0,0 +1,190 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clusterOperations
+
+import (
+	"context"
+	"errors"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"kufast/objectFactory"
+	"kufast/tools"
+)
+
+// resources returns the dynamic client for a kufast custom resource.
+func (c *Client) resources(resource schema.GroupVersionResource) (dynamic.NamespaceableResourceInterface, error) {
+	if c.dynamic == nil {
+		return nil, errors.New("Custom resources are not available on this client.")
+	}
+	return c.dynamic.Resource(resource), nil
+}
+
+// InstallCustomResourceDefinitions creates or updates the CustomResourceDefinitions of all kufast custom resources.
+func (c *Client) InstallCustomResourceDefinitions() error {
+	definitions, err := objectFactory.NewCustomResourceDefinitions()
+	if err != nil {
+		return err
+	}
+
+	for _, definition := range definitions {
+		err = c.applyResource(objectFactory.CustomResourceDefinitionResource, definition, true)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateTenantResource creates the Tenant custom resource of a tenant. The kufast controller then creates the tenant.
+func (c *Client) CreateTenantResource(tenantName string, opts TenantOptions) error {
+	object, err := objectFactory.ToUnstructured(objectFactory.NewTenantResource(tenantName, ""))
+	if err != nil {
+		return err
+	}
+	return c.applyResource(objectFactory.TenantResource, object, opts.Upsert)
+}
+
+// CreateTenantTargetResource creates the TenantTarget custom resource of a tenant-target. The kufast controller then
+// creates the tenant-target and grants the tenant access to it.
+func (c *Client) CreateTenantTargetResource(tenantName string, targetName string, opts TenantTargetOptions) error {
+	limits := objectFactory.TenantTargetLimits{
+		Memory:     opts.Memory,
+		CPU:        opts.CPU,
+		Storage:    opts.Storage,
+		MinStorage: opts.MinStorage,
+		Pods:       opts.Pods,
+	}
+	object, err := objectFactory.ToUnstructured(objectFactory.NewTenantTargetResource(tenantName, targetName, limits))
+	if err != nil {
+		return err
+	}
+	return c.applyResource(objectFactory.TenantTargetResource, object, opts.Upsert)
+}
+
+// SetTargetGroupResource creates or updates the TargetGroup custom resource of a target-group. Overwrites previous
+// config. The kufast controller then labels the nodes.
+func (c *Client) SetTargetGroupResource(targetName string, targetNodes []string) error {
+	object, err := objectFactory.ToUnstructured(objectFactory.NewTargetGroupResource(targetName, targetNodes))
+	if err != nil {
+		return err
+	}
+	return c.applyResource(objectFactory.TargetGroupResource, object, true)
+}
+
+// DeleteTenantResource deletes the Tenant custom resource of a tenant along with the TenantTarget custom resources of
+// the tenant. The kufast controller then deletes the tenant and its tenant-targets.
+func (c *Client) DeleteTenantResource(tenantName string) error {
+	resources, err := c.resources(objectFactory.TenantTargetResource)
+	if err != nil {
+		return err
+	}
+	tenantTargets, err := resources.List(context.TODO(), metav1.ListOptions{LabelSelector: tools.KUFAST_TENANT_LABEL + "=" + tenantName})
+	if err != nil {
+		return err
+	}
+	for _, tenantTarget := range tenantTargets.Items {
+		err = c.deleteResource(objectFactory.TenantTargetResource, tenantTarget.GetName())
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return c.deleteResource(objectFactory.TenantResource, tenantName)
+}
+
+// WaitForTenant waits until the kufast controller created the tenant of a Tenant custom resource. The credentials of
+// a tenant can only be generated afterwards, so the client waits even if waiting is disabled,
+// unless it is a dry run.
+func (c *Client) WaitForTenant(tenantName string) error {
+	if c.IsDryRun() {
+		return nil
+	}
+
+	err := c.waitForObject("default", tenantName+"-user", &v1.ServiceAccount{},
+		func(options metav1.ListOptions) (runtime.Object, error) {
+			return c.clientset.CoreV1().ServiceAccounts("default").List(context.TODO(), options)
+		},
+		func(options metav1.ListOptions) (watch.Interface, error) {
+			return c.clientset.CoreV1().ServiceAccounts("default").Watch(context.TODO(), options)
+		},
+		func(obj runtime.Object) (bool, error) {
+			return obj != nil, nil
+		})
+	if err == wait.ErrWaitTimeout {
+		return tools.NewError(tools.ERROR_KIND_TIMEOUT, "Operation timeout after "+c.wait.Timeout.String()+". The tenant "+tenantName+" has not been created yet. Is the kufast controller running?")
+	}
+	return err
+}
+
+// DeleteTenantTargetResource deletes the TenantTarget custom resource of a tenant-target. The kufast controller then
+// deletes the tenant-target.
+func (c *Client) DeleteTenantTargetResource(tenantName string, targetName string) error {
+	return c.deleteResource(objectFactory.TenantTargetResource, tenantName+"-"+targetName)
+}
+
+// DeleteTargetGroupResource deletes the TargetGroup custom resource of a target-group. The kufast controller then
+// removes the target-group from all nodes.
+func (c *Client) DeleteTargetGroupResource(targetName string) error {
+	return c.deleteResource(objectFactory.TargetGroupResource, targetName)
+}
+
+// applyResource creates a cluster scoped object with the dynamic client. If it already exists and upsert is set, its
+// spec and labels are updated instead.
+func (c *Client) applyResource(resource schema.GroupVersionResource, object *unstructured.Unstructured, upsert bool) error {
+	resources, err := c.resources(resource)
+	if err != nil {
+		return err
+	}
+
+	_, err = resources.Create(context.TODO(), object, metav1.CreateOptions{})
+	if !apierrors.IsAlreadyExists(err) || !upsert {
+		return err
+	}
+
+	existing, err := resources.Get(context.TODO(), object.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	existing.Object["spec"] = object.Object["spec"]
+	existing.SetLabels(mergeMaps(existing.GetLabels(), object.GetLabels()))
+	_, err = resources.Update(context.TODO(), existing, metav1.UpdateOptions{})
+	return err
+}
+
+// deleteResource deletes a cluster scoped object with the dynamic client.
+func (c *Client) deleteResource(resource schema.GroupVersionResource, name string) error {
+	resources, err := c.resources(resource)
+	if err != nil {
+		return err
+	}
+	return resources.Delete(context.TODO(), name, metav1.DeleteOptions{})
+}
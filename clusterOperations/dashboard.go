@@ -0,0 +1,116 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clusterOperations
+
+import (
+	"context"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kufast/tools"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ListVisibleTenantTargets lists the tenant-targets of a tenant along with their limits and usage. If tenantName is
+// empty, the tenant-targets of all tenants are listed for admins and the tenant-targets of the tenant of the client
+// for tenants.
+func (c *Client) ListVisibleTenantTargets(tenantName string) ([]tools.TenantTargetView, error) {
+	var tenantNames []string
+	if tenantName != "" {
+		tenantNames = append(tenantNames, tenantName)
+	} else if tenants, err := c.ListTenants(); err == nil {
+		for _, tenant := range tenants {
+			tenantNames = append(tenantNames, tenant.Labels[tools.KUFAST_TENANT_LABEL])
+		}
+	} else if !apierrors.IsForbidden(err) {
+		return nil, err
+	} else {
+		//Tenants are not allowed to list other tenants
+		tenantName, err = c.GetTenantName("")
+		if err != nil {
+			return nil, err
+		}
+		tenantNames = append(tenantNames, tenantName)
+	}
+	sort.Strings(tenantNames)
+
+	type tenantTarget struct {
+		tenant    string
+		namespace *v1.Namespace
+	}
+	var tenantTargets []tenantTarget
+	for _, name := range tenantNames {
+		namespaces, err := c.ListTenantTargets(name)
+		if err != nil {
+			return nil, err
+		}
+		sort.Slice(namespaces, func(i, j int) bool { return namespaces[i].Name < namespaces[j].Name })
+		for _, namespace := range namespaces {
+			tenantTargets = append(tenantTargets, tenantTarget{tenant: name, namespace: namespace})
+		}
+	}
+
+	views := make([]tools.TenantTargetView, len(tenantTargets))
+	err := c.fanOut(len(tenantTargets), func(i int) error {
+		tenantName := tenantTargets[i].tenant
+		namespace := tenantTargets[i].namespace
+		view, err := tenantTargetView(c, tenantName, strings.TrimPrefix(namespace.Name, tenantName+"-"), namespace)
+		views[i] = view
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return views, nil
+}
+
+// ListTenantTargetEvents lists the most recent events of a tenant-target, starting with the newest event. At most
+// limit events are returned, if limit is greater than 0.
+func (c *Client) ListTenantTargetEvents(tenantName string, targetName string, limit int) ([]v1.Event, error) {
+	events, err := c.clientset.CoreV1().Events(tenantName+"-"+targetName).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	results := events.Items
+	sort.SliceStable(results, func(i, j int) bool { return eventTime(results[i]).After(eventTime(results[j])) })
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// eventTime returns the time an event has been observed last.
+func eventTime(event v1.Event) time.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
@@ -0,0 +1,104 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clusterOperations
+
+import (
+	"context"
+	"errors"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stesting "k8s.io/client-go/testing"
+	"testing"
+	"time"
+)
+
+func TestListVisibleTenantTargets(t *testing.T) {
+	client, clientset := newTestClient(t, "tenant2-w1", newTestNode("w1", nil), newTestNode("w2", nil))
+	for _, tenantName := range []string{"tenant2", "tenant1"} {
+		if err := client.CreateTenant(tenantName, TenantOptions{}); err != nil {
+			t.Fatalf("CreateTenant: %v", err)
+		}
+		awaitResult(t, client.CreateTenantTarget(tenantName, "w1", TenantTargetOptions{CPU: "1", Memory: "1Gi", Pods: "2"}))
+	}
+	awaitResult(t, client.CreateTenantTarget("tenant1", "w2", TenantTargetOptions{}))
+
+	views, err := client.ListVisibleTenantTargets("")
+	if err != nil {
+		t.Fatalf("ListVisibleTenantTargets: %v", err)
+	}
+	var names []string
+	for _, view := range views {
+		names = append(names, view.Name)
+	}
+	if len(names) != 3 || names[0] != "tenant1-w1" || names[1] != "tenant1-w2" || names[2] != "tenant2-w1" {
+		t.Errorf("expected the tenant-targets of all tenants in order, got %v", names)
+	}
+	if views[0].Limits.CPU != "1" {
+		t.Errorf("expected the limits of the tenant-target, got %+v", views[0].Limits)
+	}
+
+	//Tenants cannot list other tenants and see their own tenant-targets
+	clientset.PrependReactor("list", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "serviceaccounts"}, "", nil)
+	})
+	views, err = client.ListVisibleTenantTargets("")
+	if err != nil {
+		t.Fatalf("ListVisibleTenantTargets as tenant: %v", err)
+	}
+	if len(views) != 1 || views[0].Name != "tenant2-w1" {
+		t.Errorf("expected the tenant-target of the tenant, got %v", views)
+	}
+
+	//Other errors are no reason to show admins only a part of the tenant-targets
+	clientset.PrependReactor("list", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewInternalError(errors.New("etcd unavailable"))
+	})
+	if _, err := client.ListVisibleTenantTargets(""); !apierrors.IsInternalError(err) {
+		t.Errorf("expected the error of listing the tenants, got %v", err)
+	}
+}
+
+func TestListTenantTargetEvents(t *testing.T) {
+	client, clientset := newTestClient(t, "")
+	now := time.Now()
+	for i, reason := range []string{"Scheduled", "Pulled", "Started"} {
+		_, _ = clientset.CoreV1().Events("tenant1-w1").Create(context.TODO(), &v1.Event{
+			ObjectMeta:    metav1.ObjectMeta{Name: reason, Namespace: "tenant1-w1"},
+			Reason:        reason,
+			LastTimestamp: metav1.NewTime(now.Add(time.Duration(i) * time.Second)),
+		}, metav1.CreateOptions{})
+	}
+
+	events, err := client.ListTenantTargetEvents("tenant1", "w1", 2)
+	if err != nil {
+		t.Fatalf("ListTenantTargetEvents: %v", err)
+	}
+	if len(events) != 2 || events[0].Reason != "Started" || events[1].Reason != "Pulled" {
+		t.Errorf("expected the two newest events, got %v", events)
+	}
+}
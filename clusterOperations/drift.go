@@ -0,0 +1,322 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clusterOperations
+
+import (
+	"context"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kufast/objectFactory"
+	"kufast/tools"
+	"strings"
+)
+
+// Drift describes an object of a tenant, which is missing or differs from the object kufast creates.
+type Drift struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Problem   string `json:"problem"`
+}
+
+// ViewName returns the name of the drifted object in the form <kind>/<name>.
+func (d Drift) ViewName() string {
+	return strings.ToLower(d.Kind) + "/" + d.Name
+}
+
+// tenantDrift is a drift along with the function restoring the object.
+type tenantDrift struct {
+	Drift
+	repair func() error
+}
+
+// CheckTenant compares the objects of a tenant and its tenant-targets with the objects kufast creates and returns
+// all missing or modified objects. Quotas are compared with the limits their tenant-targets have been created or
+// updated with.
+func (c *Client) CheckTenant(tenantName string) ([]Drift, error) {
+	drifts, err := c.detectTenantDrift(tenantName)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []Drift
+	for _, drift := range drifts {
+		results = append(results, drift.Drift)
+	}
+	return results, nil
+}
+
+// RepairTenant restores all missing or modified objects of a tenant and returns the repaired drifts. Quotas are
+// restored with the limits their tenant-targets have been created or updated with. Missing tenant-targets and limit
+// ranges are restored with the default limits of kufast.
+func (c *Client) RepairTenant(tenantName string) ([]Drift, error) {
+	var results []Drift
+
+	//Restoring the access to a tenant-target reveals the drift of its objects, so a second round is needed
+	for round := 0; round < 2; round++ {
+		drifts, err := c.detectTenantDrift(tenantName)
+		if err != nil {
+			return results, err
+		}
+
+		for _, drift := range drifts {
+			err = drift.repair()
+			if err != nil {
+				return results, err
+			}
+			results = append(results, drift.Drift)
+		}
+	}
+	return results, nil
+}
+
+// detectTenantDrift renders the objects of a tenant with the objectFactory and compares them to the live objects.
+func (c *Client) detectTenantDrift(tenantName string) ([]tenantDrift, error) {
+	tenant, err := c.GetTenant(tenantName)
+	if err != nil {
+		return nil, err
+	}
+
+	var drifts []tenantDrift
+
+	//Objects of the tenant itself
+	role := objectFactory.NewTenantDefaultRole(tenantName)
+	liveRole, err := c.clientset.RbacV1().Roles(role.Namespace).Get(context.TODO(), role.Name, metav1.GetOptions{})
+	if problem, err := compareObject(err, func() bool { return equality.Semantic.DeepEqual(liveRole.Rules, role.Rules) }); err != nil {
+		return nil, err
+	} else if problem != "" {
+		drifts = append(drifts, tenantDrift{Drift{"Role", role.Namespace, role.Name, problem}, func() error {
+			_, err := c.upsertRole(role, true)
+			return err
+		}})
+	}
+
+	binding := objectFactory.NewTenantDefaultRoleBinding(tenantName)
+	liveBinding, err := c.clientset.RbacV1().RoleBindings(binding.Namespace).Get(context.TODO(), binding.Name, metav1.GetOptions{})
+	if problem, err := compareObject(err, func() bool {
+		return equality.Semantic.DeepEqual(liveBinding.Subjects, binding.Subjects) && equality.Semantic.DeepEqual(liveBinding.RoleRef, binding.RoleRef)
+	}); err != nil {
+		return nil, err
+	} else if problem != "" {
+		drifts = append(drifts, tenantDrift{Drift{"RoleBinding", binding.Namespace, binding.Name, problem}, func() error {
+			_, err := c.upsertRoleBinding(binding, true)
+			return err
+		}})
+	}
+
+	//Request namespace of the tenant, which is missing for tenants created by older versions of kufast
+	requestNamespace := objectFactory.NewRequestNamespace(tenantName)
+	_, err = c.clientset.CoreV1().Namespaces().Get(context.TODO(), requestNamespace.Name, metav1.GetOptions{})
+	if problem, err := compareObject(err, func() bool { return true }); err != nil {
+		return nil, err
+	} else if problem != "" {
+		drifts = append(drifts, tenantDrift{Drift{"Namespace", "", requestNamespace.Name, problem}, func() error {
+			_, err := c.upsertNamespace(requestNamespace, true)
+			return err
+		}})
+	}
+
+	requestRole := objectFactory.NewTenantRequestRole(tenantName)
+	liveRequestRole, err := c.clientset.RbacV1().Roles(requestRole.Namespace).Get(context.TODO(), requestRole.Name, metav1.GetOptions{})
+	if problem, err := compareObject(err, func() bool { return equality.Semantic.DeepEqual(liveRequestRole.Rules, requestRole.Rules) }); err != nil {
+		return nil, err
+	} else if problem != "" {
+		drifts = append(drifts, tenantDrift{Drift{"Role", requestRole.Namespace, requestRole.Name, problem}, func() error {
+			_, err := c.upsertRole(requestRole, true)
+			return err
+		}})
+	}
+
+	requestBinding := objectFactory.NewTenantRequestRoleBinding(tenantName)
+	liveRequestBinding, err := c.clientset.RbacV1().RoleBindings(requestBinding.Namespace).Get(context.TODO(), requestBinding.Name, metav1.GetOptions{})
+	if problem, err := compareObject(err, func() bool {
+		return equality.Semantic.DeepEqual(liveRequestBinding.Subjects, requestBinding.Subjects) && equality.Semantic.DeepEqual(liveRequestBinding.RoleRef, requestBinding.RoleRef)
+	}); err != nil {
+		return nil, err
+	} else if problem != "" {
+		drifts = append(drifts, tenantDrift{Drift{"RoleBinding", requestBinding.Namespace, requestBinding.Name, problem}, func() error {
+			_, err := c.upsertRoleBinding(requestBinding, true)
+			return err
+		}})
+	}
+
+	//Tenant-targets the tenant has access to
+	targets, err := c.ListTargets(tenantName, false)
+	if err != nil {
+		return nil, err
+	}
+	accessible := map[string]bool{}
+	for _, target := range targets {
+		accessible[tenantName+"-"+target.Name] = true
+		targetDrifts, err := c.detectTenantTargetDrift(tenantName, target)
+		if err != nil {
+			return nil, err
+		}
+		drifts = append(drifts, targetDrifts...)
+	}
+
+	//Tenant-targets the tenant has lost access to
+	namespaces, err := c.clientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{LabelSelector: tools.KUFAST_TENANT_LABEL + "=" + tenantName})
+	if err != nil {
+		return nil, err
+	}
+	for _, namespace := range namespaces.Items {
+		targetName := strings.TrimPrefix(namespace.Name, tenantName+"-")
+		if accessible[namespace.Name] || namespace.Name == targetName || !c.IsValidTarget(targetName, "", true) {
+			continue
+		}
+		drifts = append(drifts, tenantDrift{Drift{"ServiceAccount", tenant.Namespace, tenant.Name, "access label for tenant-target " + namespace.Name + " missing"}, func() error {
+			return c.AddTargetToTenant(targetName, tenantName)
+		}})
+	}
+
+	return drifts, nil
+}
+
+// detectTenantTargetDrift compares the objects of a single tenant-target with the objects kufast creates.
+func (c *Client) detectTenantTargetDrift(tenantName string, target tools.Target) ([]tenantDrift, error) {
+	namespaceName := tenantName + "-" + target.Name
+	var drifts []tenantDrift
+
+	namespace := objectFactory.NewNamespace(tenantName, target)
+	liveNamespace, err := c.clientset.CoreV1().Namespaces().Get(context.TODO(), namespaceName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		//Without its namespace, all objects of the tenant-target are missing
+		drifts = append(drifts, tenantDrift{Drift{"Namespace", "", namespaceName, "missing"}, func() error {
+			opts := defaultTenantTargetOptions
+			opts.Upsert = true
+			return <-c.CreateTenantTarget(tenantName, target.Name, opts)
+		}})
+		return drifts, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if liveNamespace.Annotations[tools.KUFAST_NAMESPACE_NODE_SELECTOR_ANNOTATION] != namespace.Annotations[tools.KUFAST_NAMESPACE_NODE_SELECTOR_ANNOTATION] ||
+		liveNamespace.Labels[tools.KUFAST_TENANT_LABEL] != tenantName {
+		drifts = append(drifts, tenantDrift{Drift{"Namespace", "", namespaceName, "modified: node selector or tenant label differ"}, func() error {
+			_, err := c.upsertNamespace(namespace, true)
+			return err
+		}})
+	}
+
+	//The quota is compared with the limits the tenant-target has been created or updated with. Tenant-targets of older
+	//versions of kufast did not record them, so only the limits kufast always sets are compared.
+	limits, recorded := recordedLimits(liveNamespace)
+	if !recorded {
+		limits = defaultTenantTargetOptions
+	}
+	baseQuota := objectFactory.NewResourceQuota(namespaceName, "", "", "", "")
+	quota := objectFactory.NewResourceQuota(namespaceName, limits.Memory, limits.CPU, limits.Storage, limits.Pods)
+	liveQuota, err := c.clientset.CoreV1().ResourceQuotas(namespaceName).Get(context.TODO(), quota.Name, metav1.GetOptions{})
+	if problem, err := compareObject(err, func() bool {
+		if recorded {
+			return equality.Semantic.DeepEqual(liveQuota.Spec.Hard, quota.Spec.Hard)
+		}
+		for name := range baseQuota.Spec.Hard {
+			if _, ok := liveQuota.Spec.Hard[name]; !ok {
+				return false
+			}
+		}
+		return true
+	}); err != nil {
+		return nil, err
+	} else if problem != "" {
+		drifts = append(drifts, tenantDrift{Drift{"ResourceQuota", namespaceName, quota.Name, problem}, func() error {
+			//Without recorded limits, keep the limits chosen by the admin
+			if !recorded && liveQuota != nil {
+				for name, qty := range liveQuota.Spec.Hard {
+					quota.Spec.Hard[name] = qty
+				}
+			}
+			_, err := c.upsertResourceQuota(quota, true)
+			return err
+		}})
+	}
+
+	limitRange := objectFactory.NewLimitRange(namespaceName, defaultTenantTargetOptions.MinStorage, defaultTenantTargetOptions.Storage)
+	liveLimitRange, err := c.clientset.CoreV1().LimitRanges(namespaceName).Get(context.TODO(), limitRange.Name, metav1.GetOptions{})
+	if problem, err := compareObject(err, func() bool { return len(liveLimitRange.Spec.Limits) > 0 }); err != nil {
+		return nil, err
+	} else if problem != "" {
+		drifts = append(drifts, tenantDrift{Drift{"LimitRange", namespaceName, limitRange.Name, problem}, func() error {
+			_, err := c.upsertLimitRange(limitRange, true)
+			return err
+		}})
+	}
+
+	role := objectFactory.NewRole(namespaceName)
+	liveRole, err := c.clientset.RbacV1().Roles(namespaceName).Get(context.TODO(), role.Name, metav1.GetOptions{})
+	if problem, err := compareObject(err, func() bool { return equality.Semantic.DeepEqual(liveRole.Rules, role.Rules) }); err != nil {
+		return nil, err
+	} else if problem != "" {
+		drifts = append(drifts, tenantDrift{Drift{"Role", namespaceName, role.Name, problem}, func() error {
+			_, err := c.upsertRole(role, true)
+			return err
+		}})
+	}
+
+	networkPolicy := objectFactory.NewNetworkPolicy(namespaceName, tenantName)
+	liveNetworkPolicy, err := c.clientset.NetworkingV1().NetworkPolicies(namespaceName).Get(context.TODO(), networkPolicy.Name, metav1.GetOptions{})
+	if problem, err := compareObject(err, func() bool {
+		return equality.Semantic.DeepEqual(liveNetworkPolicy.Spec, networkPolicy.Spec)
+	}); err != nil {
+		return nil, err
+	} else if problem != "" {
+		drifts = append(drifts, tenantDrift{Drift{"NetworkPolicy", namespaceName, networkPolicy.Name, problem}, func() error {
+			_, err := c.upsertNetworkPolicy(networkPolicy, true)
+			return err
+		}})
+	}
+
+	binding := objectFactory.NewTenantRolebinding(namespaceName, tenantName)
+	liveBinding, err := c.clientset.RbacV1().RoleBindings(namespaceName).Get(context.TODO(), binding.Name, metav1.GetOptions{})
+	if problem, err := compareObject(err, func() bool {
+		return equality.Semantic.DeepEqual(liveBinding.Subjects, binding.Subjects) && equality.Semantic.DeepEqual(liveBinding.RoleRef, binding.RoleRef)
+	}); err != nil {
+		return nil, err
+	} else if problem != "" {
+		drifts = append(drifts, tenantDrift{Drift{"RoleBinding", namespaceName, binding.Name, problem}, func() error {
+			_, err := c.upsertRoleBinding(binding, true)
+			return err
+		}})
+	}
+
+	return drifts, nil
+}
+
+// compareObject returns the problem of a live object, which has been fetched with err. matches is only called for existing objects
+// and returns true, if the live object matches the rendered object.
+func compareObject(err error, matches func() bool) (string, error) {
+	if apierrors.IsNotFound(err) {
+		return "missing", nil
+	} else if err != nil {
+		return "", err
+	}
+	if !matches() {
+		return "modified", nil
+	}
+	return "", nil
+}
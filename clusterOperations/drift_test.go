@@ -0,0 +1,197 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clusterOperations
+
+import (
+	"context"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kufast/objectFactory"
+	"kufast/tools"
+	"testing"
+)
+
+func TestCheckTenantWithoutDrift(t *testing.T) {
+	client, _ := newTestClient(t, "", newTestNode("w1", nil))
+	if err := client.CreateTenant("tenant1", TenantOptions{}); err != nil {
+		t.Fatalf("CreateTenant: %v", err)
+	}
+	awaitResult(t, client.CreateTenantTarget("tenant1", "w1", TenantTargetOptions{}))
+
+	drifts, err := client.CheckTenant("tenant1")
+	if err != nil {
+		t.Fatalf("CheckTenant: %v", err)
+	}
+	if len(drifts) != 0 {
+		t.Errorf("expected no drift, got %v", drifts)
+	}
+}
+
+func TestCheckAndRepairTenant(t *testing.T) {
+	client, clientset := newTestClient(t, "", newTestNode("w1", nil), newTestNode("w2", nil))
+	if err := client.CreateTenant("tenant1", TenantOptions{}); err != nil {
+		t.Fatalf("CreateTenant: %v", err)
+	}
+	awaitResult(t, client.CreateTenantTarget("tenant1", "w1", TenantTargetOptions{Memory: "1Gi", CPU: "2", Storage: "10Gi", Pods: "1"}))
+
+	ctx := context.TODO()
+	_ = clientset.CoreV1().ResourceQuotas("tenant1-w1").Delete(ctx, "tenant1-w1-limits", metav1.DeleteOptions{})
+	_ = clientset.NetworkingV1().NetworkPolicies("tenant1-w1").Delete(ctx, "tenant1-w1-networkpolicy", metav1.DeleteOptions{})
+	role, _ := clientset.RbacV1().Roles("tenant1-w1").Get(ctx, "tenant1-w1-role", metav1.GetOptions{})
+	role.Rules[0].Verbs = append(role.Rules[0].Verbs, "escalate")
+	_, _ = clientset.RbacV1().Roles("tenant1-w1").Update(ctx, role, metav1.UpdateOptions{})
+	namespace, _ := clientset.CoreV1().Namespaces().Get(ctx, "tenant1-w1", metav1.GetOptions{})
+	namespace.Annotations[tools.KUFAST_NAMESPACE_NODE_SELECTOR_ANNOTATION] = "kubernetes.io/hostname=w2"
+	_, _ = clientset.CoreV1().Namespaces().Update(ctx, namespace, metav1.UpdateOptions{})
+	_, _ = clientset.CoreV1().Namespaces().Create(ctx, &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "tenant1-w2",
+		Labels: map[string]string{tools.KUFAST_TENANT_LABEL: "tenant1"},
+	}}, metav1.CreateOptions{})
+	_, _ = clientset.CoreV1().Namespaces().Create(ctx, &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "tenant1-w9",
+		Labels: map[string]string{tools.KUFAST_TENANT_LABEL: "tenant1"},
+	}}, metav1.CreateOptions{})
+
+	drifts, err := client.CheckTenant("tenant1")
+	if err != nil {
+		t.Fatalf("CheckTenant: %v", err)
+	}
+	expected := map[string]bool{
+		"namespace/tenant1-w1":                   true,
+		"resourcequota/tenant1-w1-limits":        true,
+		"role/tenant1-w1-role":                   true,
+		"networkpolicy/tenant1-w1-networkpolicy": true,
+		"serviceaccount/tenant1-user":            true,
+	}
+	for _, drift := range drifts {
+		if !expected[drift.ViewName()] {
+			t.Errorf("unexpected drift %v", drift)
+		}
+		delete(expected, drift.ViewName())
+	}
+	for name := range expected {
+		t.Errorf("expected drift of %s", name)
+	}
+
+	if _, err := client.RepairTenant("tenant1"); err != nil {
+		t.Fatalf("RepairTenant: %v", err)
+	}
+	drifts, err = client.CheckTenant("tenant1")
+	if err != nil {
+		t.Fatalf("CheckTenant: %v", err)
+	}
+	if len(drifts) != 0 {
+		t.Errorf("expected all drift to be repaired, got %v", drifts)
+	}
+	if !client.IsValidTarget("w2", "tenant1", false) {
+		t.Errorf("expected the access to tenant1-w2 to be restored")
+	}
+	namespace, _ = clientset.CoreV1().Namespaces().Get(ctx, "tenant1-w1", metav1.GetOptions{})
+	if namespace.Annotations[tools.KUFAST_NAMESPACE_NODE_SELECTOR_ANNOTATION] != "kubernetes.io/hostname=w1" {
+		t.Errorf("expected the node selector to be restored, got %v", namespace.Annotations)
+	}
+}
+
+func TestCheckAndRepairTenantQuotaLimits(t *testing.T) {
+	client, clientset := newTestClient(t, "", newTestNode("w1", nil))
+	if err := client.CreateTenant("tenant1", TenantOptions{}); err != nil {
+		t.Fatalf("CreateTenant: %v", err)
+	}
+	awaitResult(t, client.CreateTenantTarget("tenant1", "w1", TenantTargetOptions{Memory: "1Gi", CPU: "2", Storage: "10Gi", Pods: "1"}))
+	if err := client.UpdateTenantTarget("tenant1", "w1", TenantTargetOptions{Memory: "2Gi"}); err != nil {
+		t.Fatalf("UpdateTenantTarget: %v", err)
+	}
+	if drifts, _ := client.CheckTenant("tenant1"); len(drifts) != 0 {
+		t.Fatalf("expected no drift after updating the limits with kufast, got %v", drifts)
+	}
+
+	ctx := context.TODO()
+	quota, _ := clientset.CoreV1().ResourceQuotas("tenant1-w1").Get(ctx, "tenant1-w1-limits", metav1.GetOptions{})
+	quota.Spec.Hard["limits.cpu"] = resource.MustParse("8")
+	delete(quota.Spec.Hard, "pods")
+	_, _ = clientset.CoreV1().ResourceQuotas("tenant1-w1").Update(ctx, quota, metav1.UpdateOptions{})
+
+	drifts, err := client.CheckTenant("tenant1")
+	if err != nil {
+		t.Fatalf("CheckTenant: %v", err)
+	}
+	if len(drifts) != 1 || drifts[0].ViewName() != "resourcequota/tenant1-w1-limits" {
+		t.Fatalf("expected drift of the quota, got %v", drifts)
+	}
+
+	if _, err := client.RepairTenant("tenant1"); err != nil {
+		t.Fatalf("RepairTenant: %v", err)
+	}
+	quota, _ = clientset.CoreV1().ResourceQuotas("tenant1-w1").Get(ctx, "tenant1-w1-limits", metav1.GetOptions{})
+	for name, expected := range map[v1.ResourceName]string{"limits.cpu": "2", "limits.memory": "2Gi", "pods": "1"} {
+		if qty := quota.Spec.Hard[name]; qty.String() != expected {
+			t.Errorf("expected %s to be restored to %s, got %s", name, expected, qty.String())
+		}
+	}
+}
+
+func TestRepairTenantOfOlderVersion(t *testing.T) {
+	//Tenants of older versions could create config maps in the default namespace and had no request namespace
+	role := objectFactory.NewTenantDefaultRole("tenant1")
+	role.Rules = append(role.Rules, rbacv1.PolicyRule{Verbs: []string{"create"}, APIGroups: []string{""}, Resources: []string{"configmaps"}})
+	client, clientset := newTestClient(t, "", newTestTenant("tenant1", ""), role, objectFactory.NewTenantDefaultRoleBinding("tenant1"))
+
+	drifts, err := client.CheckTenant("tenant1")
+	if err != nil {
+		t.Fatalf("CheckTenant: %v", err)
+	}
+	expected := map[string]bool{
+		"role/tenant1-defaultrole":               true,
+		"namespace/kufast-requests-tenant1":      true,
+		"role/tenant1-requestrole":               true,
+		"rolebinding/tenant1-requestrolebinding": true,
+	}
+	for _, drift := range drifts {
+		if !expected[drift.ViewName()] {
+			t.Errorf("unexpected drift %v", drift)
+		}
+		delete(expected, drift.ViewName())
+	}
+	for name := range expected {
+		t.Errorf("expected drift of %s", name)
+	}
+
+	if _, err := client.RepairTenant("tenant1"); err != nil {
+		t.Fatalf("RepairTenant: %v", err)
+	}
+	role, _ = clientset.RbacV1().Roles("default").Get(context.TODO(), "tenant1-defaultrole", metav1.GetOptions{})
+	for _, rule := range role.Rules {
+		for _, resource := range rule.Resources {
+			if resource == "configmaps" {
+				t.Errorf("expected the tenant to lose access to the config maps of the default namespace, got %v", role.Rules)
+			}
+		}
+	}
+	if _, err := client.CreateTenantTargetRequest("tenant1", "w1", defaultTenantTargetOptions); err != nil {
+		t.Errorf("expected the repaired tenant to file requests, got %v", err)
+	}
+}
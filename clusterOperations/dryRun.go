@@ -0,0 +1,272 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clusterOperations
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"kufast/tools"
+	"net/http"
+	"sigs.k8s.io/yaml"
+	"strings"
+	"sync"
+)
+
+// DryRunOptions selects whether the changes of the operations of a client are persisted in the cluster.
+type DryRunOptions struct {
+	// Mode is one of tools.DRY_RUN_NONE, tools.DRY_RUN_CLIENT or tools.DRY_RUN_SERVER.
+	Mode string
+	// PrintManifests renders every object sent to the cluster as a YAML document.
+	PrintManifests bool
+	// Out receives the manifests and a line for every change of a dry run.
+	Out io.Writer
+}
+
+// dryRunVerbs are the verbs printed for the changes of a dry run by HTTP method.
+var dryRunVerbs = map[string]string{
+	http.MethodPost:   "created",
+	http.MethodPut:    "updated",
+	http.MethodPatch:  "patched",
+	http.MethodDelete: "deleted",
+}
+
+// SetDryRun changes whether the operations of the client persist their changes. As the changes are intercepted in
+// the transport of the client, all objects of the objectFactory are covered, no matter which operation sends them.
+// Nothing is waited for during a dry run, as the cluster never reaches the requested state.
+func (c *Client) SetDryRun(opts DryRunOptions) error {
+	if opts.Mode == "" {
+		opts.Mode = tools.DRY_RUN_NONE
+	}
+	if opts.Mode == tools.DRY_RUN_NONE && !opts.PrintManifests {
+		c.dryRun = opts.Mode
+		return nil
+	}
+	if c.config == nil {
+		return errors.New("This operation requires a client created from a rest config.")
+	}
+	if opts.Out == nil {
+		opts.Out = io.Discard
+	}
+
+	config := rest.CopyConfig(c.config)
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &dryRunTransport{next: rt, opts: opts, virtual: map[string]bool{}}
+	})
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	c.clientset = clientset
+	c.dynamic = dynamicClient
+	c.dryRun = opts.Mode
+	if c.IsDryRun() {
+		c.wait.Wait = false
+	}
+	return nil
+}
+
+// IsDryRun returns true, if the changes of the client are not persisted in the cluster.
+func (c *Client) IsDryRun() bool {
+	return c.dryRun != "" && c.dryRun != tools.DRY_RUN_NONE
+}
+
+// dryRunTransport intercepts all requests changing the cluster. Depending on the mode, they are answered by the
+// transport itself or sent to the cluster as server side dry run. Read requests are always sent to the cluster.
+type dryRunTransport struct {
+	next http.RoundTripper
+	opts DryRunOptions
+
+	mu sync.Mutex
+	// virtual holds the objects created or deleted during a server side dry run. The cluster does not know
+	// about these changes, so requests depending on them are answered by the transport.
+	virtual map[string]bool
+}
+
+// RoundTrip executes a single request to the cluster.
+func (t *dryRunTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	verb, mutating := dryRunVerbs[req.Method]
+	if !mutating {
+		return t.next.RoundTrip(req)
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	obj := newResourcePath(req.URL.Path, body)
+
+	t.mu.Lock()
+	dependsOnDryRun := t.virtual[obj.key()] || (obj.namespace != "" && t.virtual[resourcePath{resource: "namespaces", name: obj.namespace}.key()])
+	t.mu.Unlock()
+
+	var resp *http.Response
+	var err error
+	switch {
+	case t.opts.Mode == tools.DRY_RUN_CLIENT || (t.opts.Mode == tools.DRY_RUN_SERVER && dependsOnDryRun):
+		resp = echoResponse(req, body)
+	case t.opts.Mode == tools.DRY_RUN_SERVER:
+		dryRunReq := req.Clone(req.Context())
+		query := dryRunReq.URL.Query()
+		query.Set("dryRun", "All")
+		dryRunReq.URL.RawQuery = query.Encode()
+		dryRunReq.Body = io.NopCloser(bytes.NewReader(body))
+		resp, err = t.next.RoundTrip(dryRunReq)
+	default:
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		resp, err = t.next.RoundTrip(req)
+	}
+	if err != nil || resp.StatusCode >= http.StatusBadRequest {
+		return resp, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.opts.Mode != tools.DRY_RUN_NONE && obj.subresource == "" && (req.Method == http.MethodPost || req.Method == http.MethodDelete) {
+		t.virtual[obj.key()] = true
+	}
+	t.print(obj, verb, body)
+	return resp, nil
+}
+
+// print writes the manifest of a change or, if no manifests are printed, a line describing the change of a dry run.
+func (t *dryRunTransport) print(obj resourcePath, verb string, body []byte) {
+	if !t.opts.PrintManifests {
+		if t.opts.Mode != tools.DRY_RUN_NONE {
+			fmt.Fprintln(t.opts.Out, obj.String()+" "+verb+" ("+t.opts.Mode+" dry run)")
+		}
+		return
+	}
+	if len(body) == 0 || verb == dryRunVerbs[http.MethodDelete] {
+		fmt.Fprintln(t.opts.Out, "# "+obj.String()+" "+verb)
+		return
+	}
+	manifest, err := yaml.JSONToYAML(body)
+	if err != nil {
+		//Only JSON bodies can be rendered, so the change is at least named
+		fmt.Fprintln(t.opts.Out, "# "+obj.String()+" "+verb)
+		return
+	}
+	fmt.Fprint(t.opts.Out, "---\n"+string(manifest))
+}
+
+// echoResponse answers a request, as if the cluster had accepted it. The object sent is returned unchanged.
+func echoResponse(req *http.Request, body []byte) *http.Response {
+	status := http.StatusOK
+	if req.Method == http.MethodPost {
+		status = http.StatusCreated
+	}
+	if req.Method == http.MethodDelete || len(body) == 0 {
+		body = []byte(`{"kind":"Status","apiVersion":"v1","metadata":{},"status":"Success"}`)
+	}
+	return &http.Response{
+		Status:        http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// resourcePath identifies the object of a request by the path of the request.
+type resourcePath struct {
+	namespace   string
+	resource    string
+	name        string
+	subresource string
+}
+
+// newResourcePath parses the path of a request to the Kubernetes API, e.g.
+// /apis/rbac.authorization.k8s.io/v1/namespaces/<namespace>/roles/<name>. Requests creating an object do not contain
+// its name, so it is read from the body.
+func newResourcePath(path string, body []byte) resourcePath {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case len(parts) >= 2 && parts[0] == "api":
+		parts = parts[2:]
+	case len(parts) >= 3 && parts[0] == "apis":
+		parts = parts[3:]
+	}
+
+	var obj resourcePath
+	if len(parts) > 2 && parts[0] == "namespaces" {
+		obj.namespace = parts[1]
+		parts = parts[2:]
+	}
+	if len(parts) > 0 {
+		obj.resource = parts[0]
+	}
+	if len(parts) > 1 {
+		obj.name = parts[1]
+	}
+	if len(parts) > 2 {
+		obj.subresource = parts[2]
+	}
+
+	if obj.name == "" {
+		var meta struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+		}
+		if json.Unmarshal(body, &meta) == nil {
+			obj.name = meta.Metadata.Name
+		}
+	}
+	return obj
+}
+
+// key returns a key identifying the object in the cluster.
+func (p resourcePath) key() string {
+	return p.namespace + "/" + p.resource + "/" + p.name
+}
+
+// String returns the object as resource/name, as kubectl prints it.
+func (p resourcePath) String() string {
+	s := p.resource + "/" + p.name
+	if p.subresource != "" {
+		s += "/" + p.subresource
+	}
+	return s
+}
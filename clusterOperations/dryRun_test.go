@@ -0,0 +1,163 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clusterOperations
+
+import (
+	"bytes"
+	"context"
+	"io"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"kufast/objectFactory"
+	"kufast/tools"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newDryRunTestClient creates a Client for a fake API server, which returns every object sent to it. Pods are
+// rejected, as an admission webhook would do. All requests reaching the server are returned as "METHOD path?query".
+func newDryRunTestClient(t *testing.T, opts DryRunOptions) (*Client, *bytes.Buffer, func() []string) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/pods") {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","message":"admission webhook denied the request","reason":"Forbidden","code":403}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(&rest.Config{Host: server.URL}, "")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	out := &bytes.Buffer{}
+	opts.Out = out
+	if err := client.SetDryRun(opts); err != nil {
+		t.Fatalf("SetDryRun: %v", err)
+	}
+	return client, out, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), requests...)
+	}
+}
+
+func TestDryRunClient(t *testing.T) {
+	client, out, requests := newDryRunTestClient(t, DryRunOptions{Mode: tools.DRY_RUN_CLIENT})
+	if !client.IsDryRun() || client.wait.Wait {
+		t.Errorf("expected a dry run without waiting")
+	}
+
+	if err := client.CreateTenant("tenant1", TenantOptions{}); err != nil {
+		t.Fatalf("CreateTenant: %v", err)
+	}
+	if len(requests()) != 0 {
+		t.Errorf("expected no request to reach the cluster, got %v", requests())
+	}
+	expected := "serviceaccounts/tenant1-user created (client dry run)\n"
+	if !strings.HasPrefix(out.String(), expected) || strings.Count(out.String(), "created (client dry run)") != 6 {
+		t.Errorf("expected the service account, the request namespace and the roles and role bindings to be listed, got:\n%s", out.String())
+	}
+}
+
+func TestDryRunServerPrintManifests(t *testing.T) {
+	client, out, requests := newDryRunTestClient(t, DryRunOptions{Mode: tools.DRY_RUN_SERVER, PrintManifests: true})
+	ctx := context.TODO()
+
+	_, err := client.Clientset().CoreV1().Namespaces().Create(ctx, objectFactory.NewNamespace("tenant1", tools.Target{Name: "w1"}), metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("create namespace: %v", err)
+	}
+	_, err = client.Clientset().CoreV1().ResourceQuotas("tenant1-w1").Create(ctx, objectFactory.NewResourceQuota("tenant1-w1", "1Gi", "1", "10Gi", "1"), metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("create resource quota: %v", err)
+	}
+	err = client.Clientset().CoreV1().Namespaces().Delete(ctx, "tenant1-w1", metav1.DeleteOptions{})
+	if err != nil {
+		t.Fatalf("delete namespace: %v", err)
+	}
+
+	//The namespace does not exist in the cluster, so the objects depending on it are not sent
+	if r := requests(); len(r) != 1 || r[0] != "POST /api/v1/namespaces?dryRun=All" {
+		t.Errorf("expected a single server side dry run, got %v", r)
+	}
+	documents := strings.Split(out.String(), "---\n")
+	if len(documents) != 3 || !strings.Contains(documents[1], "kind: Namespace") || !strings.Contains(documents[2], "kind: ResourceQuota") {
+		t.Fatalf("expected the namespace and resource quota as YAML documents, got:\n%s", out.String())
+	}
+	if !strings.HasSuffix(documents[2], "# namespaces/tenant1-w1 deleted\n") {
+		t.Errorf("expected the deletion to be listed, got:\n%s", documents[2])
+	}
+}
+
+func TestDryRunServerAdmissionError(t *testing.T) {
+	client, out, requests := newDryRunTestClient(t, DryRunOptions{Mode: tools.DRY_RUN_SERVER, PrintManifests: true})
+
+	pod := objectFactory.NewPod("nginx", "nginx", "tenant1-w1", nil, "", "500m", "1Gi", "1Gi", false, nil, nil)
+	_, err := client.Clientset().CoreV1().Pods("tenant1-w1").Create(context.TODO(), pod, metav1.CreateOptions{})
+	if !apierrors.IsForbidden(err) {
+		t.Fatalf("expected the admission error, got %v", err)
+	}
+	if r := requests(); len(r) != 1 || r[0] != "POST /api/v1/namespaces/tenant1-w1/pods?dryRun=All" {
+		t.Errorf("expected the pod to be sent as server side dry run, got %v", r)
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected rejected objects not to be printed, got:\n%s", out.String())
+	}
+}
+
+func TestPrintManifestsWithoutDryRun(t *testing.T) {
+	client, out, requests := newDryRunTestClient(t, DryRunOptions{Mode: tools.DRY_RUN_NONE, PrintManifests: true})
+	if client.IsDryRun() {
+		t.Errorf("expected no dry run")
+	}
+
+	_, err := client.Clientset().CoreV1().Namespaces().Create(context.TODO(), objectFactory.NewNamespace("tenant1", tools.Target{Name: "w1"}), metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("create namespace: %v", err)
+	}
+	if r := requests(); len(r) != 1 || r[0] != "POST /api/v1/namespaces?" {
+		t.Errorf("expected the namespace to be created, got %v", r)
+	}
+	if !strings.HasPrefix(out.String(), "---\napiVersion: v1\nkind: Namespace\n") {
+		t.Errorf("expected the namespace manifest, got:\n%s", out.String())
+	}
+}
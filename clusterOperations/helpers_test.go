@@ -0,0 +1,156 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clusterOperations
+
+import (
+	"flag"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	"kufast/objectFactory"
+	"kufast/tools"
+	"os"
+	"path/filepath"
+	"sigs.k8s.io/yaml"
+	"testing"
+)
+
+// update rewrites the golden files in testdata instead of comparing against them.
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// newTestClient creates a Client backed by a fake clientset containing the given objects and a fake dynamic client
+// for custom resources. The fake clientset
+// simulates the controllers kufast waits for: namespaces become active, pods start running, the TokenRequest API
+// mints tokens and service-account-token secrets get populated.
+func newTestClient(t *testing.T, namespace string, objects ...runtime.Object) (*Client, *fake.Clientset) {
+	t.Helper()
+
+	clientset := fake.NewSimpleClientset(objects...)
+
+	clientset.PrependReactor("create", "namespaces", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		ns := action.(k8stesting.CreateAction).GetObject().(*v1.Namespace)
+		ns.Status.Phase = v1.NamespaceActive
+		return false, nil, nil
+	})
+	clientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		pod := action.(k8stesting.CreateAction).GetObject().(*v1.Pod)
+		pod.Status.Phase = v1.PodRunning
+		return false, nil, nil
+	})
+	clientset.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "token" {
+			return false, nil, nil
+		}
+		create := action.(k8stesting.CreateAction)
+		tokenRequest := create.GetObject().(*authenticationv1.TokenRequest).DeepCopy()
+		tokenRequest.Status.Token = "token-" + create.(k8stesting.CreateActionImpl).Name
+		return true, tokenRequest, nil
+	})
+	clientset.PrependReactor("create", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		secret := action.(k8stesting.CreateAction).GetObject().(*v1.Secret)
+		if secret.Type == v1.SecretTypeServiceAccountToken {
+			secret.Data = map[string][]byte{
+				v1.ServiceAccountTokenKey:  []byte("token-" + secret.Annotations[v1.ServiceAccountNameKey]),
+				v1.ServiceAccountRootCAKey: []byte("ca"),
+			}
+		}
+		return false, nil, nil
+	})
+
+	client := NewClientFromInterface(clientset, namespace)
+	client.config = &rest.Config{Host: "https://kufast.test:6443", TLSClientConfig: rest.TLSClientConfig{CAData: []byte("ca")}}
+	client.dynamic = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		objectFactory.TenantResource:                   "TenantList",
+		objectFactory.TenantTargetResource:             "TenantTargetList",
+		objectFactory.TargetGroupResource:              "TargetGroupList",
+		objectFactory.CustomResourceDefinitionResource: "CustomResourceDefinitionList",
+	})
+	return client, clientset
+}
+
+// newTestNode creates a node object with the given hostname and additional labels.
+func newTestNode(name string, labels map[string]string) *v1.Node {
+	nodeLabels := map[string]string{tools.KUFAST_NODE_HOSTNAME_LABEL: name}
+	for key, value := range labels {
+		nodeLabels[key] = value
+	}
+	return &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: nodeLabels}}
+}
+
+// newTestTenant creates the service account of a tenant with access to the given node targets.
+func newTestTenant(tenantName string, defaultTarget string, nodeTargets ...string) *v1.ServiceAccount {
+	tenant := objectFactory.NewTenantUser(tenantName, "default")
+	tenant.ObjectMeta.Labels[tools.KUFAST_TENANT_DEFAULT_LABEL] = defaultTarget
+	for _, target := range nodeTargets {
+		tenant.ObjectMeta.Labels[tools.KUFAST_TENANT_NODEACCESS_LABEL+target] = "true"
+	}
+	return tenant
+}
+
+// assertGolden compares the YAML representation of obj with the golden file testdata/<name>.yaml.
+func assertGolden(t *testing.T, name string, obj interface{}) {
+	t.Helper()
+
+	actual, err := yaml.Marshal(obj)
+	if err != nil {
+		t.Fatalf("marshal %s: %v", name, err)
+	}
+	compareGolden(t, name, actual)
+}
+
+// compareGolden compares actual with the golden file testdata/<name>.yaml.
+func compareGolden(t *testing.T, name string, actual []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".yaml")
+	if *update {
+		if err := os.WriteFile(path, actual, 0644); err != nil {
+			t.Fatalf("update golden file %s: %v", path, err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file %s: %v", path, err)
+	}
+	if string(expected) != string(actual) {
+		t.Errorf("%s does not match golden file %s\n--- expected\n%s\n--- actual\n%s", name, path, expected, actual)
+	}
+}
+
+// awaitResult reads the result of an async operation and fails the test on an error.
+func awaitResult(t *testing.T, op <-chan error) {
+	t.Helper()
+	if err := <-op; err != nil {
+		t.Fatalf("operation failed: %v", err)
+	}
+}
@@ -0,0 +1,462 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clusterOperations
+
+import (
+	"context"
+	"errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kufast/tools"
+	"sigs.k8s.io/yaml"
+	"sort"
+	"strings"
+)
+
+// Layout is the declarative description of the target-groups, tenants and tenant-targets of a cluster.
+type Layout struct {
+	TargetGroups []LayoutTargetGroup `json:"targetGroups,omitempty"`
+	Tenants      []LayoutTenant      `json:"tenants,omitempty"`
+}
+
+// LayoutTargetGroup describes a target-group and the names of its nodes.
+type LayoutTargetGroup struct {
+	Name  string   `json:"name"`
+	Nodes []string `json:"nodes"`
+}
+
+// LayoutTenant describes a tenant with its tenant-targets. If no default target is set, the first tenant-target
+// becomes the default target of a new tenant.
+type LayoutTenant struct {
+	Name          string               `json:"name"`
+	DefaultTarget string               `json:"defaultTarget,omitempty"`
+	Targets       []LayoutTenantTarget `json:"targets,omitempty"`
+}
+
+// LayoutTenantTarget describes a tenant-target and its limits. Missing limits are set to the defaults of
+// 'kufast create tenant-target'. The minimum storage is only applied, when the tenant-target is created.
+type LayoutTenantTarget struct {
+	Name       string `json:"name"`
+	Memory     string `json:"memory,omitempty"`
+	CPU        string `json:"cpu,omitempty"`
+	Storage    string `json:"storage,omitempty"`
+	MinStorage string `json:"minStorage,omitempty"`
+	Pods       string `json:"pods,omitempty"`
+}
+
+// defaultTenantTargetOptions holds the limits of a tenant-target, which are not set in a layout.
+var defaultTenantTargetOptions = TenantTargetOptions{
+	Memory:     "1Gi",
+	CPU:        "500m",
+	Storage:    "10Gi",
+	MinStorage: "1Gi",
+	Pods:       "1",
+}
+
+// ParseLayout parses and validates a layout in YAML or JSON format.
+func ParseLayout(data []byte) (*Layout, error) {
+	var layout Layout
+	err := yaml.UnmarshalStrict(data, &layout)
+	if err != nil {
+		return nil, tools.NewError(tools.ERROR_KIND_USAGE, "Invalid layout: "+err.Error())
+	}
+
+	groupNames := map[string]bool{}
+	for _, group := range layout.TargetGroups {
+		if !tools.IsAlphaNumeric(group.Name) {
+			return nil, tools.CreateAlphaNumericError(group.Name)
+		}
+		if groupNames[group.Name] {
+			return nil, tools.NewError(tools.ERROR_KIND_USAGE, "Invalid layout: target-group "+group.Name+" is defined more than once.")
+		}
+		if len(group.Nodes) == 0 {
+			return nil, tools.NewError(tools.ERROR_KIND_USAGE, "Invalid layout: target-group "+group.Name+" has no nodes.")
+		}
+		groupNames[group.Name] = true
+	}
+
+	tenantNames := map[string]bool{}
+	for _, tenant := range layout.Tenants {
+		if !tools.IsAlphaNumeric(tenant.Name) {
+			return nil, tools.CreateAlphaNumericError(tenant.Name)
+		}
+		if tenantNames[tenant.Name] {
+			return nil, tools.NewError(tools.ERROR_KIND_USAGE, "Invalid layout: tenant "+tenant.Name+" is defined more than once.")
+		}
+		tenantNames[tenant.Name] = true
+
+		targetNames := map[string]bool{}
+		for _, target := range tenant.Targets {
+			if !tools.IsAlphaNumeric(target.Name) {
+				return nil, tools.CreateAlphaNumericError(target.Name)
+			}
+			if targetNames[target.Name] {
+				return nil, tools.NewError(tools.ERROR_KIND_USAGE, "Invalid layout: tenant-target "+tenant.Name+"-"+target.Name+" is defined more than once.")
+			}
+			targetNames[target.Name] = true
+
+			for _, qty := range []string{target.Memory, target.CPU, target.Storage, target.MinStorage, target.Pods} {
+				if _, err := resource.ParseQuantity(qty); qty != "" && err != nil {
+					return nil, tools.NewError(tools.ERROR_KIND_USAGE, "Invalid layout: tenant-target "+tenant.Name+"-"+target.Name+" has an invalid limit "+qty)
+				}
+			}
+		}
+		if tenant.DefaultTarget != "" && !targetNames[tenant.DefaultTarget] {
+			return nil, tools.NewError(tools.ERROR_KIND_USAGE, "Invalid layout: the default target "+tenant.DefaultTarget+" of tenant "+tenant.Name+" is not one of its tenant-targets.")
+		}
+	}
+
+	return &layout, nil
+}
+
+// options returns the limits of a tenant-target, using the defaults for missing limits.
+func (t LayoutTenantTarget) options() TenantTargetOptions {
+	opts := TenantTargetOptions{Memory: t.Memory, CPU: t.CPU, Storage: t.Storage, MinStorage: t.MinStorage, Pods: t.Pods}
+	if opts.Memory == "" {
+		opts.Memory = defaultTenantTargetOptions.Memory
+	}
+	if opts.CPU == "" {
+		opts.CPU = defaultTenantTargetOptions.CPU
+	}
+	if opts.Storage == "" {
+		opts.Storage = defaultTenantTargetOptions.Storage
+	}
+	if opts.MinStorage == "" {
+		opts.MinStorage = defaultTenantTargetOptions.MinStorage
+	}
+	if opts.Pods == "" {
+		opts.Pods = defaultTenantTargetOptions.Pods
+	}
+	return opts
+}
+
+// LayoutChange is a single change to the cluster, which is required to converge it to a layout.
+type LayoutChange struct {
+	// Action is one of create, update or delete.
+	Action string
+	// Kind is one of target-group, tenant or tenant-target.
+	Kind string
+	Name string
+	// Details lists the changed attributes in a human-readable form.
+	Details []string
+
+	tenant        string
+	target        string
+	opts          TenantTargetOptions
+	nodes         []string
+	defaultTarget string
+	targets       []string
+}
+
+// String returns the change in a diff-like form, prefixed by +, ~ or - for creations, updates and deletions.
+func (c LayoutChange) String() string {
+	prefix := map[string]string{"create": "+", "update": "~", "delete": "-"}[c.Action]
+	result := prefix + " " + c.Kind + " " + c.Name
+	for _, detail := range c.Details {
+		result += "\n    " + detail
+	}
+	return result
+}
+
+// LayoutPlan is the ordered list of changes, which converges the cluster to a layout.
+type LayoutPlan struct {
+	Changes []LayoutChange
+}
+
+// IsEmpty returns true, if the cluster already matches the layout.
+func (p *LayoutPlan) IsEmpty() bool {
+	return len(p.Changes) == 0
+}
+
+// String returns all changes of the plan in a diff-like form.
+func (p *LayoutPlan) String() string {
+	var lines []string
+	for _, change := range p.Changes {
+		lines = append(lines, change.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// PlanLayout compares the cluster with a layout and returns the changes required to converge the cluster. Objects
+// missing in the layout are only deleted, if prune is true.
+func (c *Client) PlanLayout(layout *Layout, prune bool) (*LayoutPlan, error) {
+	var groupChanges, tenantChanges, targetChanges, updateChanges, defaultChanges, deleteChanges []LayoutChange
+
+	//Target-groups
+	currentGroups, err := c.ListTargetGroups()
+	if err != nil {
+		return nil, err
+	}
+	allTargets, err := c.ListTargets("", true)
+	if err != nil {
+		return nil, err
+	}
+	validTargets := map[string]bool{}
+	for _, target := range allTargets {
+		validTargets[target.Name] = true
+	}
+	nodes, err := c.listNodeNames()
+	if err != nil {
+		return nil, err
+	}
+
+	layoutGroups := map[string]bool{}
+	for _, group := range layout.TargetGroups {
+		layoutGroups[group.Name] = true
+		validTargets[group.Name] = true
+
+		desiredNodes := append([]string{}, group.Nodes...)
+		sort.Strings(desiredNodes)
+		for _, node := range desiredNodes {
+			if !nodes[node] {
+				return nil, tools.NewError(tools.ERROR_KIND_NOT_FOUND, "The node "+node+" of target-group "+group.Name+" does not exist.")
+			}
+		}
+
+		currentNodes, ok := currentGroups[group.Name]
+		if !ok {
+			groupChanges = append(groupChanges, LayoutChange{Action: "create", Kind: "target-group", Name: group.Name,
+				Details: []string{"nodes: " + strings.Join(desiredNodes, ",")}, target: group.Name, nodes: desiredNodes})
+		} else if strings.Join(currentNodes, ",") != strings.Join(desiredNodes, ",") {
+			groupChanges = append(groupChanges, LayoutChange{Action: "update", Kind: "target-group", Name: group.Name,
+				Details: []string{"nodes: " + strings.Join(currentNodes, ",") + " -> " + strings.Join(desiredNodes, ",")},
+				target:  group.Name, nodes: desiredNodes})
+		}
+	}
+
+	//Tenants and their tenant-targets
+	currentTenants, err := c.ListTenants()
+	if err != nil {
+		return nil, err
+	}
+	currentDefaults := map[string]string{}
+	for _, tenant := range currentTenants {
+		currentDefaults[tenant.ObjectMeta.Labels[tools.KUFAST_TENANT_LABEL]] = tenant.ObjectMeta.Labels[tools.KUFAST_TENANT_DEFAULT_LABEL]
+	}
+
+	layoutTenants := map[string]bool{}
+	for _, tenant := range layout.Tenants {
+		layoutTenants[tenant.Name] = true
+
+		currentDefault, exists := currentDefaults[tenant.Name]
+		currentTargets := map[string]bool{}
+		if exists {
+			//Tenant-targets whose namespace has been deleted are planned to be created again
+			namespaces, err := c.listExistingTenantTargets(tenant.Name)
+			if err != nil {
+				return nil, err
+			}
+			for _, namespace := range namespaces {
+				currentTargets[strings.TrimPrefix(namespace.Name, tenant.Name+"-")] = true
+			}
+		} else {
+			tenantChanges = append(tenantChanges, LayoutChange{Action: "create", Kind: "tenant", Name: tenant.Name, tenant: tenant.Name})
+		}
+
+		layoutTargets := map[string]bool{}
+		for _, target := range tenant.Targets {
+			layoutTargets[target.Name] = true
+			if !validTargets[target.Name] {
+				return nil, tools.NewError(tools.ERROR_KIND_INVALID_TARGET, "The target "+target.Name+" of tenant "+tenant.Name+" does not exist in the cluster.")
+			}
+
+			opts := target.options()
+			if !currentTargets[target.Name] {
+				targetChanges = append(targetChanges, LayoutChange{Action: "create", Kind: "tenant-target", Name: tenant.Name + "-" + target.Name,
+					Details: []string{"memory: " + opts.Memory, "cpu: " + opts.CPU, "storage: " + opts.Storage, "min storage: " + opts.MinStorage, "pods: " + opts.Pods},
+					tenant:  tenant.Name, target: target.Name, opts: opts})
+				continue
+			}
+
+			details, err := c.diffTenantTargetLimits(tenant.Name, target.Name, opts)
+			if err != nil {
+				return nil, err
+			}
+			if len(details) > 0 {
+				updateChanges = append(updateChanges, LayoutChange{Action: "update", Kind: "tenant-target", Name: tenant.Name + "-" + target.Name,
+					Details: details, tenant: tenant.Name, target: target.Name, opts: opts})
+			}
+		}
+
+		desiredDefault := tenant.DefaultTarget
+		if desiredDefault == "" && len(tenant.Targets) > 0 && (!exists || !layoutTargets[currentDefault]) {
+			desiredDefault = tenant.Targets[0].Name
+		}
+		if desiredDefault != "" && desiredDefault != currentDefault {
+			defaultChanges = append(defaultChanges, LayoutChange{Action: "update", Kind: "tenant", Name: tenant.Name,
+				Details: []string{"default target: " + currentDefault + " -> " + desiredDefault}, tenant: tenant.Name, defaultTarget: desiredDefault})
+		}
+
+		if prune {
+			var pruned []string
+			for targetName := range currentTargets {
+				if !layoutTargets[targetName] {
+					pruned = append(pruned, targetName)
+				}
+			}
+			sort.Strings(pruned)
+			for _, targetName := range pruned {
+				deleteChanges = append(deleteChanges, LayoutChange{Action: "delete", Kind: "tenant-target", Name: tenant.Name + "-" + targetName,
+					tenant: tenant.Name, target: targetName})
+			}
+		}
+	}
+
+	if prune {
+		var prunedTenants []string
+		for tenantName := range currentDefaults {
+			if !layoutTenants[tenantName] {
+				prunedTenants = append(prunedTenants, tenantName)
+			}
+		}
+		sort.Strings(prunedTenants)
+		for _, tenantName := range prunedTenants {
+			namespaces, err := c.listExistingTenantTargets(tenantName)
+			if err != nil {
+				return nil, err
+			}
+			change := LayoutChange{Action: "delete", Kind: "tenant", Name: tenantName, tenant: tenantName}
+			for _, namespace := range namespaces {
+				change.targets = append(change.targets, strings.TrimPrefix(namespace.Name, tenantName+"-"))
+			}
+			if len(change.targets) > 0 {
+				change.Details = []string{"tenant-targets: " + strings.Join(change.targets, ",")}
+			}
+			deleteChanges = append(deleteChanges, change)
+		}
+
+		var prunedGroups []string
+		for groupName := range currentGroups {
+			if !layoutGroups[groupName] {
+				prunedGroups = append(prunedGroups, groupName)
+			}
+		}
+		sort.Strings(prunedGroups)
+		for _, groupName := range prunedGroups {
+			deleteChanges = append(deleteChanges, LayoutChange{Action: "delete", Kind: "target-group", Name: groupName, target: groupName})
+		}
+	}
+
+	plan := &LayoutPlan{}
+	for _, changes := range [][]LayoutChange{groupChanges, tenantChanges, targetChanges, updateChanges, defaultChanges, deleteChanges} {
+		plan.Changes = append(plan.Changes, changes...)
+	}
+	return plan, nil
+}
+
+// ApplyLayoutPlan executes all changes of a plan in order. It stops at the first change that fails.
+func (c *Client) ApplyLayoutPlan(plan *LayoutPlan) error {
+	for _, change := range plan.Changes {
+		err := c.applyLayoutChange(change)
+		if err != nil {
+			return tools.WithMessage(err, "Failed to "+change.Action+" "+change.Kind+" "+change.Name+": "+err.Error())
+		}
+	}
+	return nil
+}
+
+// applyLayoutChange executes a single change of a plan.
+func (c *Client) applyLayoutChange(change LayoutChange) error {
+	switch change.Kind + "/" + change.Action {
+	case "target-group/create", "target-group/update":
+		return c.SetTargetGroupToNodes(change.target, change.nodes)
+	case "target-group/delete":
+		return c.DeleteTargetGroupFromNodes(change.target)
+	case "tenant/create":
+		return c.CreateTenant(change.tenant, TenantOptions{})
+	case "tenant/update":
+		return c.UpdateTenantDefaultDeployTarget(change.defaultTarget, change.tenant)
+	case "tenant/delete":
+		var deleteTargetOps []<-chan error
+		for _, targetName := range change.targets {
+			deleteTargetOps = append(deleteTargetOps, c.DeleteTenantTarget(targetName, change.tenant))
+		}
+		//Ensure all operations are done
+		for _, op := range deleteTargetOps {
+			if res := <-op; res != nil {
+				return res
+			}
+		}
+		return c.DeleteTenant(change.tenant)
+	case "tenant-target/create":
+		return <-c.CreateTenantTarget(change.tenant, change.target, change.opts)
+	case "tenant-target/update":
+		return c.UpdateTenantTarget(change.tenant, change.target, change.opts)
+	case "tenant-target/delete":
+		if res := <-c.DeleteTenantTarget(change.target, change.tenant); res != nil {
+			return res
+		}
+		return c.DeleteTargetFromTenant(change.target, change.tenant)
+	}
+	return errors.New("unknown change")
+}
+
+// diffTenantTargetLimits compares the quota of a tenant-target with the desired limits and returns the differences.
+func (c *Client) diffTenantTargetLimits(tenantName string, targetName string, opts TenantTargetOptions) ([]string, error) {
+	quota, err := c.GetTenantTargetQuota(tenantName, targetName)
+	if err != nil {
+		return nil, err
+	}
+
+	var details []string
+	limits := []struct {
+		name     string
+		resource string
+		desired  string
+	}{
+		{"memory", "limits.memory", opts.Memory},
+		{"cpu", "limits.cpu", opts.CPU},
+		{"storage", "limits.ephemeral-storage", opts.Storage},
+		{"pods", "pods", opts.Pods},
+	}
+	for _, limit := range limits {
+		desired, err := resource.ParseQuantity(limit.desired)
+		if err != nil {
+			return nil, tools.NewError(tools.ERROR_KIND_USAGE, "Invalid layout: tenant-target "+tenantName+"-"+targetName+" has an invalid limit "+limit.desired)
+		}
+		current, ok := quota.Spec.Hard[v1.ResourceName(limit.resource)]
+		if !ok {
+			details = append(details, limit.name+": none -> "+desired.String())
+		} else if current.Cmp(desired) != 0 {
+			details = append(details, limit.name+": "+current.String()+" -> "+desired.String())
+		}
+	}
+	return details, nil
+}
+
+// listNodeNames returns the names of all nodes of the cluster.
+func (c *Client) listNodeNames() (map[string]bool, error) {
+	nodeList, err := c.clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	for _, node := range nodeList.Items {
+		names[node.Name] = true
+	}
+	return names, nil
+}
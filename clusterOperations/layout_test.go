@@ -0,0 +1,203 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clusterOperations
+
+import (
+	"kufast/tools"
+	"strings"
+	"testing"
+)
+
+const testLayout = `
+targetGroups:
+  - name: edge
+    nodes: [w2, w1]
+tenants:
+  - name: tenant1
+    defaultTarget: w3
+    targets:
+      - name: edge
+        cpu: "1"
+        pods: "2"
+      - name: w3
+`
+
+func TestParseLayout(t *testing.T) {
+	layout, err := ParseLayout([]byte(testLayout))
+	if err != nil {
+		t.Fatalf("ParseLayout: %v", err)
+	}
+	if len(layout.TargetGroups) != 1 || len(layout.Tenants) != 1 || len(layout.Tenants[0].Targets) != 2 {
+		t.Errorf("unexpected layout: %+v", layout)
+	}
+	opts := layout.Tenants[0].Targets[0].options()
+	if opts != (TenantTargetOptions{Memory: "1Gi", CPU: "1", Storage: "10Gi", MinStorage: "1Gi", Pods: "2"}) {
+		t.Errorf("unexpected tenant-target options: %+v", opts)
+	}
+
+	invalid := map[string]string{
+		"unknown field":      "tenants:\n  - name: tenant1\n    quota: 1\n",
+		"duplicate tenant":   "tenants:\n  - name: tenant1\n  - name: tenant1\n",
+		"invalid name":       "tenants:\n  - name: tenant_1\n",
+		"invalid limit":      "tenants:\n  - name: tenant1\n    targets:\n      - name: w1\n        cpu: lots\n",
+		"unknown default":    "tenants:\n  - name: tenant1\n    defaultTarget: w2\n    targets:\n      - name: w1\n",
+		"group without node": "targetGroups:\n  - name: edge\n",
+	}
+	for name, data := range invalid {
+		if _, err := ParseLayout([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestPlanAndApplyLayout(t *testing.T) {
+	client, _ := newTestClient(t, "",
+		newTestNode("w1", nil), newTestNode("w2", nil), newTestNode("w3", nil))
+	if err := client.CreateTenant("tenant2", TenantOptions{}); err != nil {
+		t.Fatalf("CreateTenant: %v", err)
+	}
+	if err := client.AddTargetToTenant("w3", "tenant2"); err != nil {
+		t.Fatalf("AddTargetToTenant: %v", err)
+	}
+	awaitResult(t, client.CreateTenantTarget("tenant2", "w3", defaultTenantTargetOptions))
+
+	layout, err := ParseLayout([]byte(testLayout))
+	if err != nil {
+		t.Fatalf("ParseLayout: %v", err)
+	}
+
+	plan, err := client.PlanLayout(layout, false)
+	if err != nil {
+		t.Fatalf("PlanLayout: %v", err)
+	}
+	expected := `+ target-group edge
+    nodes: w1,w2
++ tenant tenant1
++ tenant-target tenant1-edge
+    memory: 1Gi
+    cpu: 1
+    storage: 10Gi
+    min storage: 1Gi
+    pods: 2
++ tenant-target tenant1-w3
+    memory: 1Gi
+    cpu: 500m
+    storage: 10Gi
+    min storage: 1Gi
+    pods: 1
+~ tenant tenant1
+    default target:  -> w3`
+	if plan.String() != expected {
+		t.Errorf("unexpected plan\n--- expected\n%s\n--- actual\n%s", expected, plan.String())
+	}
+
+	if err := client.ApplyLayoutPlan(plan); err != nil {
+		t.Fatalf("ApplyLayoutPlan: %v", err)
+	}
+	plan, err = client.PlanLayout(layout, false)
+	if err != nil {
+		t.Fatalf("PlanLayout: %v", err)
+	}
+	if !plan.IsEmpty() {
+		t.Errorf("expected the cluster to match the layout, got plan\n%s", plan)
+	}
+
+	//Changed limits and pruning
+	layout.Tenants[0].Targets = layout.Tenants[0].Targets[1:]
+	layout.Tenants[0].Targets[0].Memory = "2Gi"
+	layout.TargetGroups = nil
+	plan, err = client.PlanLayout(layout, true)
+	if err != nil {
+		t.Fatalf("PlanLayout: %v", err)
+	}
+	expected = `~ tenant-target tenant1-w3
+    memory: 1Gi -> 2Gi
+- tenant-target tenant1-edge
+- tenant tenant2
+    tenant-targets: w3
+- target-group edge`
+	if plan.String() != expected {
+		t.Errorf("unexpected plan\n--- expected\n%s\n--- actual\n%s", expected, plan.String())
+	}
+
+	if err := client.ApplyLayoutPlan(plan); err != nil {
+		t.Fatalf("ApplyLayoutPlan: %v", err)
+	}
+	plan, err = client.PlanLayout(layout, true)
+	if err != nil {
+		t.Fatalf("PlanLayout: %v", err)
+	}
+	if !plan.IsEmpty() {
+		t.Errorf("expected the cluster to match the layout, got plan\n%s", plan)
+	}
+}
+
+func TestPlanLayoutUnknownTarget(t *testing.T) {
+	client, _ := newTestClient(t, "", newTestNode("w1", nil))
+
+	layout, err := ParseLayout([]byte(testLayout))
+	if err != nil {
+		t.Fatalf("ParseLayout: %v", err)
+	}
+	if _, err := client.PlanLayout(layout, false); err == nil || !strings.Contains(err.Error(), "w2") {
+		t.Errorf("expected an error for the unknown node w2, got %v", err)
+	}
+}
+
+func TestPlanLayoutDrift(t *testing.T) {
+	client, _ := newTestClient(t, "", newTestNode("w3", nil), newTestNode("w4", nil))
+	if err := client.CreateTenant("tenant2", TenantOptions{}); err != nil {
+		t.Fatalf("CreateTenant: %v", err)
+	}
+	if err := client.AddTargetToTenant("w3", "tenant2"); err != nil {
+		t.Fatalf("AddTargetToTenant: %v", err)
+	}
+	awaitResult(t, client.CreateTenantTarget("tenant2", "w3", defaultTenantTargetOptions))
+
+	//Layouts passed without ParseLayout are not validated yet
+	layout := &Layout{Tenants: []LayoutTenant{{Name: "tenant2", DefaultTarget: "w3", Targets: []LayoutTenantTarget{{Name: "w3", CPU: "lots"}}}}}
+	if _, err := client.PlanLayout(layout, false); tools.GetErrorKind(err) != tools.ERROR_KIND_USAGE {
+		t.Errorf("expected a usage error for an invalid limit, got %v", err)
+	}
+
+	//The tenant has access to w4, but its namespace is missing
+	if err := client.AddTargetToTenant("w4", "tenant2"); err != nil {
+		t.Fatalf("AddTargetToTenant: %v", err)
+	}
+	layout.Tenants[0].Targets = []LayoutTenantTarget{{Name: "w3", CPU: "500m"}, {Name: "w4"}}
+	plan, err := client.PlanLayout(layout, true)
+	if err != nil {
+		t.Fatalf("PlanLayout: %v", err)
+	}
+	if !strings.HasPrefix(plan.String(), "+ tenant-target tenant2-w4\n") {
+		t.Errorf("expected the tenant-target to be created, got plan\n%s", plan)
+	}
+	if err := client.ApplyLayoutPlan(plan); err != nil {
+		t.Fatalf("ApplyLayoutPlan: %v", err)
+	}
+	if _, err := client.GetTenantTarget("tenant2", "w4"); err != nil {
+		t.Errorf("expected the namespace to be created: %v", err)
+	}
+}
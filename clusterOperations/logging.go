@@ -0,0 +1,325 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clusterOperations
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"io"
+	"k8s.io/client-go/rest"
+	"kufast/tools"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogOptions selects how the API calls of a client are logged.
+type LogOptions struct {
+	// Verbosity 0 logs nothing, 1 logs a line per API call and 2 adds the URL and the response of failed calls.
+	Verbosity int
+	// Out receives the log lines. Defaults to os.Stderr.
+	Out io.Writer
+	// Trace receives every request and response in full, if set.
+	Trace io.Writer
+}
+
+// logMu serializes the log output of all clients, as the clients of several clusters share the same trace file.
+var logMu sync.Mutex
+
+// LogOptionsFromCmd reads the logging options from the verbose and trace-file flags of the command. Log lines are
+// written to the error output of the command.
+func LogOptionsFromCmd(cmd *cobra.Command) (LogOptions, error) {
+	trace, err := tools.GetTraceFile(cmd)
+	if err != nil {
+		return LogOptions{}, err
+	}
+	return LogOptions{Verbosity: tools.GetVerbosity(cmd), Out: cmd.ErrOrStderr(), Trace: trace}, nil
+}
+
+// SetLogging changes how the API calls of the client are logged. Calls answered by a client side dry run never
+// reach the cluster and are not logged.
+func (c *Client) SetLogging(opts LogOptions) error {
+	if opts.Verbosity <= 0 && opts.Trace == nil {
+		return nil
+	}
+	if c.config == nil {
+		return errors.New("This operation requires a client created from a rest config.")
+	}
+
+	config := rest.CopyConfig(c.config)
+	WrapLogging(config, opts)
+	return c.setConfig(config)
+}
+
+// WrapLogging adds the logging of API calls to the transport of a rest config, so every client created from the
+// config logs its calls.
+func WrapLogging(config *rest.Config, opts LogOptions) {
+	if opts.Verbosity <= 0 && opts.Trace == nil {
+		return
+	}
+	if opts.Out == nil {
+		opts.Out = os.Stderr
+	}
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &loggingTransport{next: rt, opts: opts}
+	})
+}
+
+// loggingTransport logs every request sent to the cluster together with the status of its response.
+type loggingTransport struct {
+	next http.RoundTripper
+	opts LogOptions
+}
+
+// RoundTrip executes a single request to the cluster.
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	latency := time.Since(start).Round(time.Millisecond)
+
+	//Streams like watches, logs followed or exec sessions are only logged, once they have been opened
+	stream := isStream(req, resp)
+	var respBody []byte
+	if err == nil && !stream && (t.opts.Trace != nil || (t.opts.Verbosity >= 2 && resp.StatusCode >= http.StatusBadRequest)) {
+		respBody, err = io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	}
+
+	logMu.Lock()
+	defer logMu.Unlock()
+	if t.opts.Verbosity >= 1 {
+		t.log(req, reqBody, resp, respBody, err, latency)
+	}
+	if t.opts.Trace != nil {
+		t.trace(req, reqBody, resp, respBody, stream, err, latency)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// log writes a line describing an API call, e.g. "get pods/nginx namespace=tenant1-w1 status=200 latency=12ms".
+func (t *loggingTransport) log(req *http.Request, reqBody []byte, resp *http.Response, respBody []byte, err error, latency time.Duration) {
+	obj := newResourcePath(req.URL.Path, reqBody)
+	line := apiVerb(req, obj) + " " + obj.resource
+	if obj.name != "" {
+		line += "/" + obj.name
+	}
+	if obj.subresource != "" {
+		line += "/" + obj.subresource
+	}
+	if obj.namespace != "" {
+		line += " namespace=" + obj.namespace
+	}
+	if err != nil {
+		line += " error=" + fmt.Sprintf("%q", err.Error())
+	} else {
+		line += fmt.Sprintf(" status=%d", resp.StatusCode)
+	}
+	line += " latency=" + latency.String()
+	if t.opts.Verbosity >= 2 {
+		line += " url=" + req.URL.String()
+	}
+	fmt.Fprintln(t.opts.Out, line)
+
+	if t.opts.Verbosity >= 2 && err == nil && resp.StatusCode >= http.StatusBadRequest && len(respBody) > 0 {
+		fmt.Fprintln(t.opts.Out, "  "+strings.TrimSpace(string(respBody)))
+	}
+}
+
+// trace writes the full request and response of an API call. Credentials in the headers, the data of secrets and
+// the tokens of token requests are redacted.
+func (t *loggingTransport) trace(req *http.Request, reqBody []byte, resp *http.Response, respBody []byte, stream bool, err error, latency time.Duration) {
+	obj := newResourcePath(req.URL.Path, reqBody)
+
+	var b strings.Builder
+	b.WriteString("=== " + time.Now().UTC().Format(time.RFC3339Nano) + " latency=" + latency.String() + "\n")
+	b.WriteString(req.Method + " " + req.URL.String() + "\n")
+	writeHeaders(&b, req.Header)
+	b.WriteString("\n")
+	if len(reqBody) > 0 {
+		b.Write(redactBody(obj, reqBody))
+		b.WriteString("\n\n")
+	}
+
+	switch {
+	case err != nil:
+		b.WriteString("Error: " + err.Error() + "\n")
+	default:
+		b.WriteString(resp.Proto + " " + resp.Status + "\n")
+		writeHeaders(&b, resp.Header)
+		b.WriteString("\n")
+		if stream {
+			b.WriteString("(streamed response not recorded)\n")
+		} else if len(respBody) > 0 {
+			b.Write(redactBody(obj, respBody))
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("\n")
+	_, _ = io.WriteString(t.opts.Trace, b.String())
+}
+
+// writeHeaders writes the headers of a request or response sorted by their name.
+func writeHeaders(b *strings.Builder, header http.Header) {
+	var names []string
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range header[name] {
+			if name == "Authorization" {
+				value = strings.SplitN(value, " ", 2)[0] + " <redacted>"
+			}
+			b.WriteString(name + ": " + value + "\n")
+		}
+	}
+}
+
+// redactBody replaces the data of secrets and the tokens of token requests in the body of a request or response.
+// Bodies of secrets and token requests, which cannot be parsed, are not recorded at all.
+func redactBody(obj resourcePath, body []byte) []byte {
+	sensitive := obj.resource == "secrets" || obj.subresource == "token"
+
+	var object map[string]interface{}
+	if err := json.Unmarshal(body, &object); err != nil {
+		if sensitive {
+			return []byte("(body not recorded)")
+		}
+		return body
+	}
+
+	//Patches of secrets and token requests do not contain the kind of the object
+	if !redactObject(object, obj.resource == "secrets", obj.subresource == "token") {
+		return body
+	}
+	var redacted bytes.Buffer
+	encoder := json.NewEncoder(&redacted)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(object); err != nil {
+		return []byte("(body not recorded)")
+	}
+	return bytes.TrimSpace(redacted.Bytes())
+}
+
+// redactObject replaces the data of secrets and the tokens of token requests in a decoded object and the items of
+// lists. It returns true, if anything has been redacted.
+func redactObject(object map[string]interface{}, isSecret bool, isToken bool) bool {
+	kind, _ := object["kind"].(string)
+	isSecret = isSecret || kind == "Secret"
+	isToken = isToken || kind == "TokenRequest"
+
+	redacted := false
+	if isSecret {
+		for _, field := range []string{"data", "stringData"} {
+			if values, ok := object[field].(map[string]interface{}); ok {
+				for key := range values {
+					values[key] = "<redacted>"
+					redacted = true
+				}
+			}
+		}
+		//Secrets created with kubectl apply contain their data in an annotation as well
+		if metadata, ok := object["metadata"].(map[string]interface{}); ok {
+			if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+				if _, ok := annotations["kubectl.kubernetes.io/last-applied-configuration"]; ok {
+					annotations["kubectl.kubernetes.io/last-applied-configuration"] = "<redacted>"
+					redacted = true
+				}
+			}
+		}
+	}
+	if isToken {
+		if status, ok := object["status"].(map[string]interface{}); ok {
+			if token, _ := status["token"].(string); token != "" {
+				status["token"] = "<redacted>"
+				redacted = true
+			}
+		}
+	}
+
+	//The items of a list do not contain their kind
+	if items, ok := object["items"].([]interface{}); ok {
+		for _, item := range items {
+			if itemObject, ok := item.(map[string]interface{}); ok && redactObject(itemObject, isSecret || kind == "SecretList", isToken) {
+				redacted = true
+			}
+		}
+	}
+	return redacted
+}
+
+// isStream returns true, if the response of a request is streamed, so its body cannot be read before it is returned.
+func isStream(req *http.Request, resp *http.Response) bool {
+	query := req.URL.Query()
+	return query.Get("watch") == "true" || query.Get("follow") == "true" || req.Header.Get("Upgrade") != "" ||
+		(resp != nil && resp.StatusCode == http.StatusSwitchingProtocols)
+}
+
+// apiVerb returns the Kubernetes verb of a request, e.g. list for a GET request without the name of an object.
+func apiVerb(req *http.Request, obj resourcePath) string {
+	switch req.Method {
+	case http.MethodGet:
+		if req.URL.Query().Get("watch") == "true" {
+			return "watch"
+		}
+		if obj.name == "" {
+			return "list"
+		}
+		return "get"
+	case http.MethodPost:
+		return "create"
+	case http.MethodPut:
+		return "update"
+	case http.MethodPatch:
+		return "patch"
+	case http.MethodDelete:
+		if obj.name == "" {
+			return "deletecollection"
+		}
+		return "delete"
+	}
+	return strings.ToLower(req.Method)
+}
@@ -0,0 +1,189 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clusterOperations
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"kufast/objectFactory"
+	"kufast/tools"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newLoggingTestClient creates a Client for a fake API server, which returns every object sent to it and answers
+// all other requests with 404. Token requests are answered with the token "issued-token".
+func newLoggingTestClient(t *testing.T, opts LogOptions) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/token") {
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"kind":"TokenRequest","apiVersion":"authentication.k8s.io/v1","status":{"token":"issued-token"}}`))
+			return
+		}
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write(body)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","message":"pods \"nginx\" not found","reason":"NotFound","code":404}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(&rest.Config{Host: server.URL, BearerToken: "secret-token"}, "")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if err := client.SetLogging(opts); err != nil {
+		t.Fatalf("SetLogging: %v", err)
+	}
+	return client
+}
+
+func TestLoggingVerbose(t *testing.T) {
+	out := &bytes.Buffer{}
+	client := newLoggingTestClient(t, LogOptions{Verbosity: 1, Out: out})
+
+	_, err := client.Clientset().CoreV1().Secrets("tenant1-w1").Create(context.TODO(), objectFactory.NewSecret("tenant1-w1", "password", "1234"), metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	_, err = client.Clientset().CoreV1().Pods("tenant1-w1").Get(context.TODO(), "nginx", metav1.GetOptions{})
+	if err == nil {
+		t.Fatalf("expected the pod not to be found")
+	}
+	_, _ = client.Clientset().CoreV1().Pods("tenant1-w1").List(context.TODO(), metav1.ListOptions{})
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	expected := []string{
+		"create secrets/password namespace=tenant1-w1 status=201 latency=",
+		"get pods/nginx namespace=tenant1-w1 status=404 latency=",
+		"list pods namespace=tenant1-w1 status=404 latency=",
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %d log lines, got %q", len(expected), out.String())
+	}
+	for i, line := range lines {
+		if !strings.HasPrefix(line, expected[i]) {
+			t.Errorf("expected log line %q, got %q", expected[i], line)
+		}
+	}
+}
+
+func TestLoggingVerbosityTwo(t *testing.T) {
+	out := &bytes.Buffer{}
+	client := newLoggingTestClient(t, LogOptions{Verbosity: 2, Out: out})
+
+	_, _ = client.Clientset().CoreV1().Pods("tenant1-w1").Get(context.TODO(), "nginx", metav1.GetOptions{})
+	if !strings.Contains(out.String(), " url=http://") {
+		t.Errorf("expected the URL to be logged, got %q", out.String())
+	}
+	if !strings.Contains(out.String(), `"reason":"NotFound"`) {
+		t.Errorf("expected the response of the failed call to be logged, got %q", out.String())
+	}
+}
+
+func TestLoggingTrace(t *testing.T) {
+	out := &bytes.Buffer{}
+	trace := &bytes.Buffer{}
+	client := newLoggingTestClient(t, LogOptions{Out: out, Trace: trace})
+
+	_, err := client.Clientset().CoreV1().Secrets("tenant1-w1").Create(context.TODO(), objectFactory.NewSecret("tenant1-w1", "password", "1234"), metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if out.Len() != 0 {
+		t.Errorf("expected no log lines without verbosity, got %q", out.String())
+	}
+	for _, expected := range []string{"POST http://", "/api/v1/namespaces/tenant1-w1/secrets", "Authorization: Bearer <redacted>", `"name":"password"`, "HTTP/1.1 201 Created"} {
+		if !strings.Contains(trace.String(), expected) {
+			t.Errorf("expected the trace to contain %q, got %q", expected, trace.String())
+		}
+	}
+	if strings.Contains(trace.String(), "secret-token") {
+		t.Errorf("expected the bearer token to be redacted")
+	}
+}
+
+func TestLoggingTraceRedactsCredentials(t *testing.T) {
+	trace := &bytes.Buffer{}
+	client := newLoggingTestClient(t, LogOptions{Out: &bytes.Buffer{}, Trace: trace})
+
+	secret := objectFactory.NewSecret("tenant1-w1", "password", "string-secret")
+	secret.Data = map[string][]byte{"key": []byte("data-secret")}
+	_, err := client.Clientset().CoreV1().Secrets("tenant1-w1").Create(context.TODO(), secret, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	_, err = client.Clientset().CoreV1().ServiceAccounts("default").CreateToken(context.TODO(), "tenant1-user", &authenticationv1.TokenRequest{}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("CreateToken: %v", err)
+	}
+
+	for _, secret := range []string{"string-secret", base64.StdEncoding.EncodeToString([]byte("data-secret")), "issued-token"} {
+		if strings.Contains(trace.String(), secret) {
+			t.Errorf("expected %q to be redacted, got %q", secret, trace.String())
+		}
+	}
+	for _, expected := range []string{`"name":"password"`, `"secret":"<redacted>"`, `"token":"<redacted>"`} {
+		if !strings.Contains(trace.String(), expected) {
+			t.Errorf("expected the trace to contain %q, got %q", expected, trace.String())
+		}
+	}
+
+	//The items of a list of secrets do not contain their kind
+	list := []byte(`{"kind":"SecretList","items":[{"metadata":{"name":"password"},"data":{"key":"ZGF0YS1zZWNyZXQ="}}]}`)
+	if redacted := string(redactBody(newResourcePath("/api/v1/namespaces/tenant1-w1/secrets", nil), list)); strings.Contains(redacted, "ZGF0YS1zZWNyZXQ=") {
+		t.Errorf("expected the data of the listed secrets to be redacted, got %s", redacted)
+	}
+}
+
+func TestLoggingClientDryRun(t *testing.T) {
+	out := &bytes.Buffer{}
+	client := newLoggingTestClient(t, LogOptions{Verbosity: 1, Out: out})
+	if err := client.SetDryRun(DryRunOptions{Mode: tools.DRY_RUN_CLIENT}); err != nil {
+		t.Fatalf("SetDryRun: %v", err)
+	}
+
+	_, err := client.Clientset().CoreV1().Secrets("tenant1-w1").Create(context.TODO(), objectFactory.NewSecret("tenant1-w1", "password", "1234"), metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected no API call for a client side dry run, got %q", out.String())
+	}
+}
@@ -0,0 +1,66 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clusterOperations
+
+import (
+	"errors"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// GetTenantKubeconfigForClusters creates the credentials of a tenant for several clusters. The kubeconfig contains
+// one context per cluster, which is named after the cluster of the client. The context of the first cluster is
+// the current context.
+func GetTenantKubeconfigForClusters(clients []*Client, tenantName string, opts CredentialOptions) (*api.Config, error) {
+	if len(clients) == 1 {
+		return clients[0].GetTenantKubeconfig(tenantName, opts)
+	}
+
+	merged := api.NewConfig()
+	merged.Kind = "Config"
+	merged.APIVersion = "v1"
+	for _, client := range clients {
+		if client.Cluster() == "" {
+			return nil, errors.New("Credentials for several clusters require a name for each cluster.")
+		}
+
+		config, err := client.GetTenantKubeconfig(tenantName, opts)
+		if err != nil {
+			return nil, errors.New("Cluster " + client.Cluster() + ": " + err.Error())
+		}
+		tenantContext := config.Contexts[config.CurrentContext]
+
+		merged.Clusters[client.Cluster()] = config.Clusters[tenantContext.Cluster]
+		merged.AuthInfos[tenantName+"-user@"+client.Cluster()] = config.AuthInfos[tenantContext.AuthInfo]
+		merged.Contexts[client.Cluster()] = &api.Context{
+			Cluster:   client.Cluster(),
+			Namespace: tenantContext.Namespace,
+			AuthInfo:  tenantName + "-user@" + client.Cluster(),
+		}
+		if merged.CurrentContext == "" {
+			merged.CurrentContext = client.Cluster()
+		}
+	}
+	return merged, nil
+}
@@ -0,0 +1,74 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clusterOperations
+
+import (
+	"testing"
+)
+
+func TestGetTenantKubeconfigForClusters(t *testing.T) {
+	var clients []*Client
+	for _, cluster := range []string{"prod", "staging"} {
+		client, _ := newTestClient(t, "", newTestNode("w1", nil))
+		client.SetCluster(cluster)
+		client.config.Host = "https://" + cluster + ".test:6443"
+		if err := client.CreateTenant("tenant1", TenantOptions{}); err != nil {
+			t.Fatalf("CreateTenant: %v", err)
+		}
+		awaitResult(t, client.CreateTenantTarget("tenant1", "w1", TenantTargetOptions{}))
+		clients = append(clients, client)
+	}
+
+	config, err := GetTenantKubeconfigForClusters(clients, "tenant1", CredentialOptions{})
+	if err != nil {
+		t.Fatalf("GetTenantKubeconfigForClusters: %v", err)
+	}
+	if config.CurrentContext != "prod" {
+		t.Errorf("expected the first cluster to be the current context, got %s", config.CurrentContext)
+	}
+	for _, cluster := range []string{"prod", "staging"} {
+		context := config.Contexts[cluster]
+		if context == nil {
+			t.Fatalf("missing context for cluster %s", cluster)
+		}
+		if context.Namespace != "tenant1-w1" {
+			t.Errorf("expected namespace tenant1-w1 in cluster %s, got %s", cluster, context.Namespace)
+		}
+		if server := config.Clusters[context.Cluster].Server; server != "https://"+cluster+".test:6443" {
+			t.Errorf("expected server of cluster %s, got %s", cluster, server)
+		}
+		if config.AuthInfos[context.AuthInfo] == nil || config.AuthInfos[context.AuthInfo].Token == "" {
+			t.Errorf("missing token for cluster %s", cluster)
+		}
+	}
+}
+
+func TestGetTenantKubeconfigForClustersRequiresNames(t *testing.T) {
+	first, _ := newTestClient(t, "")
+	second, _ := newTestClient(t, "")
+	if _, err := GetTenantKubeconfigForClusters([]*Client{first, second}, "tenant1", CredentialOptions{}); err == nil {
+		t.Error("expected an error for clusters without names")
+	}
+}
@@ -27,70 +27,74 @@ package clusterOperations
 import (
 	"context"
 	"errors"
-	"github.com/spf13/cobra"
+	"io"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
 	"kufast/objectFactory"
 	"kufast/tools"
-	"time"
+	"strings"
 )
 
+// CreatePodOptions contains all parameters required to create a new pod.
+type CreatePodOptions struct {
+	ScopeOptions
+	Name         string
+	Image        string
+	Memory       string
+	CPU          string
+	Storage      string
+	KeepAlive    bool
+	Secrets      []string
+	DeploySecret string
+	Ports        []int32
+	Command      []string
+}
+
 // CreatePod creates a new pod as an async function. The input channel is closed, as soon as the operation
-// completes. All parameters are drawn from the environment on the command line.
-func CreatePod(cmd *cobra.Command, args []string) <-chan string {
-	res := make(chan string)
+// completes.
+func (c *Client) CreatePod(opts CreatePodOptions) <-chan error {
+	res := make(chan error)
 
 	go func() {
 		defer close(res)
-		clientset, _, err := tools.GetUserClient(cmd)
+
+		namespaceName, err := c.GetTenantTargetName(opts.ScopeOptions)
 		if err != nil {
-			res <- err.Error()
+			res <- err
 			return
 		}
 
-		ram, _ := cmd.Flags().GetString("memory")
-		cpu, _ := cmd.Flags().GetString("cpu")
-		storage, _ := cmd.Flags().GetString("storage")
-		keepAlive, _ := cmd.Flags().GetBool("keep-alive")
-		target, _ := cmd.Flags().GetString("target")
-		secrets, _ := cmd.Flags().GetStringArray("secrets")
-		deploySecret, _ := cmd.Flags().GetString("deploy-secret")
-		ports, _ := cmd.Flags().GetInt32Slice("port")
-		podCmd, _ := cmd.Flags().GetStringArray("cmd")
-
-		namespaceName, err := GetTenantTargetNameFromCmd(cmd)
+		if opts.Target == "" || c.IsValidTarget(opts.Target, opts.Tenant, false) {
 
-		if target == "" || IsValidTarget(cmd, target, false) {
+			podObject := objectFactory.NewPod(opts.Name, opts.Image, namespaceName, opts.Secrets, opts.DeploySecret,
+				opts.CPU, opts.Memory, opts.Storage, opts.KeepAlive, opts.Ports, opts.Command)
 
-			podObject := objectFactory.NewPod(args[0], args[1], namespaceName, secrets, deploySecret, cpu, ram, storage, keepAlive, ports, podCmd)
+			//Pods select the nodes of their target themselves, so they are admitted without the PodNodeSelector plugin
+			if tenantName, err := c.GetTenantName(opts.Tenant); err == nil {
+				target, err := c.GetTargetFromTargetName(strings.TrimPrefix(namespaceName, tenantName+"-"), tenantName, false)
+				if err == nil {
+					podObject.Spec.NodeSelector = objectFactory.NewNodeSelector(target)
+				}
+			}
 
-			_, err := clientset.CoreV1().Pods(namespaceName).Create(context.TODO(), podObject, metav1.CreateOptions{})
+			_, err := c.clientset.CoreV1().Pods(namespaceName).Create(context.TODO(), podObject, metav1.CreateOptions{})
 			if err != nil {
-				res <- err.Error()
+				res <- err
 				return
 			}
 
-			timeout := 30
-			for true {
-				timeout--
-
-				if timeout == 0 {
-					res <- "Operation timeout. Maybe your pod doesn't start correctly? Please look after it with 'kufast get pod'"
-				}
-
-				time.Sleep(time.Millisecond * 1000)
-				pod, err := clientset.CoreV1().Pods(namespaceName).Get(context.TODO(), args[0], metav1.GetOptions{})
-				if err != nil {
-					res <- err.Error()
-					return
-				}
-				if pod.Status.Phase == "Running" {
-					res <- ""
-					break
-				}
+			err = c.waitForPodStarted(namespaceName, opts.Name)
+			if err != nil {
+				res <- err
+				return
 			}
+			res <- nil
 		} else {
-			res <- errors.New("Invalid target for tenant").Error()
+			res <- tools.NewError(tools.ERROR_KIND_INVALID_TARGET, "Invalid target for tenant: "+opts.Target)
 			return
 		}
 	}()
@@ -98,66 +102,54 @@ func CreatePod(cmd *cobra.Command, args []string) <-chan string {
 }
 
 // DeletePod deletes an existent pod as an async function. The input channel is closed, as soon as the operation
-// completes. All parameters are drawn from the environment on the command line.
-func DeletePod(cmd *cobra.Command, pod string) <-chan string {
-	res := make(chan string)
+// completes.
+func (c *Client) DeletePod(pod string, scope ScopeOptions) <-chan error {
+	res := make(chan error)
 
 	go func() {
 		defer close(res)
-		clientset, _, err := tools.GetUserClient(cmd)
-		if err != nil {
-			res <- err.Error()
-			return
-		}
 
-		namespaceName, err := GetTenantTargetNameFromCmd(cmd)
+		namespaceName, err := c.GetTenantTargetName(scope)
 		if err != nil {
-			res <- err.Error()
+			res <- err
 			return
 		}
 
-		err = clientset.CoreV1().Pods(namespaceName).Delete(context.TODO(), pod, metav1.DeleteOptions{})
+		err = c.clientset.CoreV1().Pods(namespaceName).Delete(context.TODO(), pod, metav1.DeleteOptions{})
 		if err != nil {
-			res <- err.Error()
+			res <- err
 			return
 		}
 
 		//Check for the pod been deleted from the system
-		timeout := 80
-		for true {
-			timeout--
-
-			if timeout == 0 {
-				res <- "Operation timeout. Your pod still exists. Please look after it with 'kufast get pod'"
-			}
-			time.Sleep(time.Millisecond * 250)
-			_, err := clientset.CoreV1().Pods(namespaceName).Get(context.TODO(), pod, metav1.GetOptions{})
-			if err != nil {
-				res <- ""
-				break
-			}
+		err = c.waitForDeletion("pod", namespaceName, pod, &v1.Pod{},
+			func(options metav1.ListOptions) (runtime.Object, error) {
+				return c.clientset.CoreV1().Pods(namespaceName).List(context.TODO(), options)
+			},
+			func(options metav1.ListOptions) (watch.Interface, error) {
+				return c.clientset.CoreV1().Pods(namespaceName).Watch(context.TODO(), options)
+			})
+		if err != nil {
+			res <- err
+			return
 		}
+		res <- nil
 
 	}()
 
 	return res
 }
 
-// GetPod returns a pod from a string. All parameters are drawn from the environment on the command line.
-func GetPod(podName string, cmd *cobra.Command) (*v1.Pod, error) {
+// GetPod returns a pod from a string.
+func (c *Client) GetPod(podName string, scope ScopeOptions) (*v1.Pod, error) {
 	//Initial config block
-	namespaceName, err := GetTenantTargetNameFromCmd(cmd)
-	if err != nil {
-		return nil, err
-	}
-
-	clientset, _, err := tools.GetUserClient(cmd)
+	namespaceName, err := c.GetTenantTargetName(scope)
 	if err != nil {
 		return nil, err
 	}
 
 	//execute request
-	pod, err := clientset.CoreV1().Pods(namespaceName).Get(context.TODO(), podName, metav1.GetOptions{})
+	pod, err := c.clientset.CoreV1().Pods(namespaceName).Get(context.TODO(), podName, metav1.GetOptions{})
 	if err != nil {
 		return nil, err
 	}
@@ -166,51 +158,105 @@ func GetPod(podName string, cmd *cobra.Command) (*v1.Pod, error) {
 }
 
 // GetPodEvents returns all pod events from the pod provided as a string.
-// All parameters are drawn from the environment on the command line.
-func GetPodEvents(podName string, cmd *cobra.Command) ([]v1.Event, error) {
+func (c *Client) GetPodEvents(podName string, scope ScopeOptions) ([]v1.Event, error) {
 	//Initial config block
-	namespaceName, err := GetTenantTargetNameFromCmd(cmd)
+	namespaceName, err := c.GetTenantTargetName(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := c.clientset.CoreV1().Events(namespaceName).List(context.TODO(),
+		metav1.ListOptions{FieldSelector: "involvedObject.name=" + podName, TypeMeta: metav1.TypeMeta{Kind: "Pod"}})
 	if err != nil {
 		return nil, err
 	}
 
-	clientset, _, err := tools.GetUserClient(cmd)
+	return events.Items, nil
+}
+
+// GetPodLogs returns a stream of the logs of a pod. The last tailLines lines are included and the stream
+// follows new log lines if follow is true. The caller has to close the stream.
+func (c *Client) GetPodLogs(podName string, scope ScopeOptions, tailLines int64, follow bool) (io.ReadCloser, error) {
+	namespaceName, err := c.GetTenantTargetName(scope)
 	if err != nil {
 		return nil, err
 	}
 
-	events, _ := clientset.CoreV1().Events(namespaceName).List(context.TODO(),
-		metav1.ListOptions{FieldSelector: "involvedObject.name=" + podName, TypeMeta: metav1.TypeMeta{Kind: "Pod"}})
+	options := v1.PodLogOptions{
+		Follow:    follow,
+		TailLines: &tailLines,
+	}
 
-	return events.Items, nil
+	return c.clientset.CoreV1().Pods(namespaceName).GetLogs(podName, &options).Stream(context.TODO())
 }
 
-// ListTenantPods lists all pods in all tenant-targets of a tenant.
-func ListTenantPods(cmd *cobra.Command) ([]v1.Pod, error) {
+// ExecInPod starts an interactive shell session in a pod, which executes the given command. The session is attached
+// to the given streams until the command completes.
+func (c *Client) ExecInPod(podName string, command string, scope ScopeOptions, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	if c.config == nil {
+		return errors.New("This operation requires a client created from a rest config.")
+	}
 
-	clientset, _, err := tools.GetUserClient(cmd)
+	namespaceName, err := c.GetTenantTargetName(scope)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	targets, err := ListTargetsFromCmd(cmd, false)
+	req := c.clientset.CoreV1().RESTClient().Post().Resource("pods").Name(podName).
+		Namespace(namespaceName).SubResource("exec")
+	option := &v1.PodExecOptions{
+		Command: []string{"sh", "-c", command},
+		Stdin:   true,
+		Stdout:  true,
+		Stderr:  true,
+		TTY:     true,
+	}
+	req.VersionedParams(
+		option,
+		scheme.ParameterCodec,
+	)
+
+	exec, err := remotecommand.NewSPDYExecutor(c.config, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+	return exec.Stream(remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+}
+
+// ListTenantPods lists all pods in all tenant-targets of a tenant.
+func (c *Client) ListTenantPods(tenantName string) ([]v1.Pod, error) {
+
+	tenantName, err := c.GetTenantName(tenantName)
 	if err != nil {
 		return nil, err
 	}
 
-	tenantName, err := GetTenantNameFromCmd(cmd)
+	targets, err := c.ListTargets(tenantName, false)
 	if err != nil {
 		return nil, err
 	}
-	var results []v1.Pod
-	for _, target := range targets {
-		list, err := clientset.CoreV1().Pods(tenantName+"-"+target.Name).List(context.TODO(), metav1.ListOptions{})
+
+	lists := make([][]v1.Pod, len(targets))
+	err = c.fanOut(len(targets), func(i int) error {
+		list, err := c.clientset.CoreV1().Pods(tenantName+"-"+targets[i].Name).List(context.TODO(), metav1.ListOptions{})
 		if err != nil {
-			return nil, err
+			return err
 		}
-		results = append(results, list.Items...)
+		lists[i] = list.Items
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	var results []v1.Pod
+	for _, list := range lists {
+		results = append(results, list...)
+	}
 	return results, nil
 
 }
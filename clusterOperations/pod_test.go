@@ -0,0 +1,85 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clusterOperations
+
+import (
+	"kufast/tools"
+	"testing"
+)
+
+func TestCreatePod(t *testing.T) {
+	client, _ := newTestClient(t, "tenant1-w1", newTestNode("w1", nil), newTestTenant("tenant1", "w1", "w1"))
+
+	opts := CreatePodOptions{
+		Name:         "nginx",
+		Image:        "nginx:1.25",
+		Memory:       "256Mi",
+		CPU:          "200m",
+		Storage:      "1Gi",
+		KeepAlive:    true,
+		Secrets:      []string{"password"},
+		DeploySecret: "registry",
+		Ports:        []int32{80},
+		Command:      []string{"nginx", "-g", "daemon off;"},
+	}
+	awaitResult(t, client.CreatePod(opts))
+
+	pod, err := client.GetPod("nginx", ScopeOptions{Tenant: "tenant1", Target: "w1"})
+	if err != nil {
+		t.Fatalf("GetPod: %v", err)
+	}
+	assertGolden(t, "pod", pod)
+
+	pods, err := client.ListTenantPods("")
+	if err != nil {
+		t.Fatalf("ListTenantPods: %v", err)
+	}
+	if len(pods) != 1 || pods[0].Name != "nginx" {
+		t.Errorf("unexpected pods: %v", pods)
+	}
+}
+
+func TestCreatePodInvalidTarget(t *testing.T) {
+	client, _ := newTestClient(t, "tenant1-w1",
+		newTestNode("w1", nil),
+		newTestNode("w2", map[string]string{tools.KUFAST_NODE_GROUP_LABEL + "edge": "true"}),
+		newTestTenant("tenant1", "w1", "w1"))
+
+	err := <-client.CreatePod(CreatePodOptions{ScopeOptions: ScopeOptions{Target: "w2"}, Name: "nginx", Image: "nginx"})
+	if tools.GetErrorKind(err) != tools.ERROR_KIND_INVALID_TARGET {
+		t.Errorf("expected an invalid target error for a target the tenant has no access to, got %v", err)
+	}
+}
+
+func TestDeletePod(t *testing.T) {
+	client, _ := newTestClient(t, "tenant1-w1", newTestNode("w1", nil), newTestTenant("tenant1", "w1", "w1"))
+	awaitResult(t, client.CreatePod(CreatePodOptions{Name: "nginx", Image: "nginx"}))
+
+	awaitResult(t, client.DeletePod("nginx", ScopeOptions{}))
+
+	if _, err := client.GetPod("nginx", ScopeOptions{}); err == nil {
+		t.Errorf("expected the pod to be deleted")
+	}
+}
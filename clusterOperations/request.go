@@ -0,0 +1,204 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clusterOperations
+
+import (
+	"context"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"kufast/objectFactory"
+	"kufast/tools"
+	"strings"
+)
+
+// CreateTenantTargetRequest files a request for a tenant-target with the given limits. The request is stored in the
+// request namespace of the tenant, until an admin approves or denies it. If tenantName is empty, the tenant of the
+// client is used.
+func (c *Client) CreateTenantTargetRequest(tenantName string, targetName string, opts TenantTargetOptions) (*v1.ConfigMap, error) {
+	tenantName, err := c.GetTenantName(tenantName)
+	if err != nil {
+		return nil, err
+	}
+	if !tools.IsAlphaNumeric(targetName) {
+		return nil, tools.CreateAlphaNumericError(targetName)
+	}
+	if err := validateRequestedLimits(opts); err != nil {
+		return nil, err
+	}
+
+	//The id starts with the tenant, so the request can be found in its namespace
+	request := objectFactory.NewTenantTargetRequest(tenantName+"-"+rand.String(5), tenantName, targetName, opts.Memory,
+		opts.CPU, opts.Storage, opts.MinStorage, opts.Pods)
+	request, err = c.clientset.CoreV1().ConfigMaps(request.Namespace).Create(context.TODO(), request, metav1.CreateOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, tools.WithMessage(err, "The tenant "+tenantName+" cannot file requests. Tenants created by older "+
+			"versions of kufast need to be updated by an admin with 'kufast repair tenant "+tenantName+"' first.")
+	}
+	return request, err
+}
+
+// ListTenantTargetRequests lists the requests for tenant-targets of a tenant. If tenantName is empty, the requests of
+// all tenants are listed for admins and the requests of the tenant of the client for tenants. If state is not empty,
+// only requests in this state are listed.
+func (c *Client) ListTenantTargetRequests(tenantName string, state string) ([]v1.ConfigMap, error) {
+	selector := tools.KUFAST_REQUEST_LABEL + "=true"
+	if state != "" {
+		selector += "," + tools.KUFAST_REQUEST_STATE_LABEL + "=" + state
+	}
+
+	namespace := metav1.NamespaceAll
+	if tenantName != "" {
+		namespace = objectFactory.RequestNamespace(tenantName)
+	}
+	requests, err := c.clientset.CoreV1().ConfigMaps(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector})
+	if apierrors.IsForbidden(err) && tenantName == "" {
+		//Tenants are only allowed to list their own requests
+		tenantName, err = c.GetTenantName("")
+		if err != nil {
+			return nil, err
+		}
+		return c.ListTenantTargetRequests(tenantName, state)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	//Config maps outside of the request namespaces are no requests
+	var results []v1.ConfigMap
+	for _, request := range requests.Items {
+		if request.Namespace == objectFactory.RequestNamespace(tools.GetTenantFromNamespace(request.Data["id"])) {
+			results = append(results, request)
+		}
+	}
+	return results, nil
+}
+
+// GetTenantTargetRequest gets a request for a tenant-target by its id.
+func (c *Client) GetTenantTargetRequest(requestID string) (*v1.ConfigMap, error) {
+	namespace := objectFactory.RequestNamespace(tools.GetTenantFromNamespace(requestID))
+	request, err := c.clientset.CoreV1().ConfigMaps(namespace).Get(context.TODO(), "kufast-request-"+requestID, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if request.Labels[tools.KUFAST_REQUEST_LABEL] != "true" || request.Data["id"] != requestID {
+		return nil, tools.NewError(tools.ERROR_KIND_NOT_FOUND, "Not a kufast request: "+requestID)
+	}
+	return request, nil
+}
+
+// ApproveTenantTargetRequest creates the requested tenant-target with the requested limits and marks the request as
+// approved. The tenant must exist and must have filed the request itself in its request namespace.
+func (c *Client) ApproveTenantTargetRequest(requestID string) error {
+	request, err := c.getPendingRequest(requestID)
+	if err != nil {
+		return err
+	}
+
+	//The request namespace, not the content of the request, tells which tenant filed it
+	tenantName := strings.TrimPrefix(request.Namespace, objectFactory.RequestNamespace(""))
+	if request.Data["tenant"] != tenantName {
+		return tools.NewError(tools.ERROR_KIND_FORBIDDEN, "The request "+requestID+" has been filed by the tenant "+
+			tenantName+" for the tenant "+request.Data["tenant"]+". Tenants can only request tenant-targets for themselves.")
+	}
+	_, err = c.GetTenant(tenantName)
+	if apierrors.IsNotFound(err) {
+		return tools.WithMessage(err, "The tenant "+tenantName+" of the request "+requestID+" does not exist.")
+	} else if err != nil {
+		return err
+	}
+
+	//Tenants can write the config map of a request directly, so the limits are checked again
+	opts := TenantTargetOptions{
+		Memory:     request.Data["memory"],
+		CPU:        request.Data["cpu"],
+		Storage:    request.Data["storage"],
+		MinStorage: request.Data["minStorage"],
+		Pods:       request.Data["pods"],
+	}
+	if err := validateRequestedLimits(opts); err != nil {
+		return tools.WithMessage(err, "The request "+requestID+" cannot be approved: "+err.Error())
+	}
+	if res := <-c.CreateTenantTarget(tenantName, request.Data["target"], opts); res != nil {
+		return res
+	}
+
+	return c.setRequestState(request, objectFactory.RequestStateApproved, "")
+}
+
+// DenyTenantTargetRequest marks a request as denied. The reason is shown to the tenant with 'kufast list requests'.
+func (c *Client) DenyTenantTargetRequest(requestID string, reason string) error {
+	request, err := c.getPendingRequest(requestID)
+	if err != nil {
+		return err
+	}
+	return c.setRequestState(request, objectFactory.RequestStateDenied, reason)
+}
+
+// validateRequestedLimits checks, that all limits of a request are set and valid quantities. A missing or invalid
+// limit would be left out of the quota of the tenant-target, so the tenant-target would not be limited at all.
+func validateRequestedLimits(opts TenantTargetOptions) error {
+	limits := []struct {
+		name  string
+		value string
+	}{
+		{"memory", opts.Memory},
+		{"cpu", opts.CPU},
+		{"storage", opts.Storage},
+		{"storage-min", opts.MinStorage},
+		{"pods", opts.Pods},
+	}
+	for _, limit := range limits {
+		qty, err := resource.ParseQuantity(limit.value)
+		if err != nil || qty.Sign() < 0 {
+			return tools.NewError(tools.ERROR_KIND_USAGE, "Invalid "+limit.name+" limit \""+limit.value+"\". Please request a non-negative quantity, e.g. 1Gi or 500m.")
+		}
+	}
+	return nil
+}
+
+// getPendingRequest gets a request, which has neither been approved nor denied yet.
+func (c *Client) getPendingRequest(requestID string) (*v1.ConfigMap, error) {
+	request, err := c.GetTenantTargetRequest(requestID)
+	if err != nil {
+		return nil, err
+	}
+	if state := request.Labels[tools.KUFAST_REQUEST_STATE_LABEL]; state != objectFactory.RequestStatePending {
+		return nil, tools.NewError(tools.ERROR_KIND_CONFLICT, "The request "+requestID+" has already been "+state+".")
+	}
+	return request, nil
+}
+
+// setRequestState stores the new state of a request along with the reason for it.
+func (c *Client) setRequestState(request *v1.ConfigMap, state string, reason string) error {
+	request.Labels[tools.KUFAST_REQUEST_STATE_LABEL] = state
+	if reason != "" {
+		request.Data["reason"] = reason
+	}
+	_, err := c.clientset.CoreV1().ConfigMaps(request.Namespace).Update(context.TODO(), request, metav1.UpdateOptions{})
+	return err
+}
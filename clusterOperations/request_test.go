@@ -0,0 +1,200 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clusterOperations
+
+import (
+	"context"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+	"kufast/objectFactory"
+	"kufast/tools"
+	"testing"
+)
+
+func TestApproveTenantTargetRequest(t *testing.T) {
+	client, clientset := newTestClient(t, "", newTestNode("w1", nil))
+	if err := client.CreateTenant("tenant1", TenantOptions{}); err != nil {
+		t.Fatalf("CreateTenant: %v", err)
+	}
+
+	request, err := client.CreateTenantTargetRequest("tenant1", "w1", TenantTargetOptions{Memory: "2Gi", CPU: "1", Storage: "10Gi", MinStorage: "1Gi", Pods: "2"})
+	if err != nil {
+		t.Fatalf("CreateTenantTargetRequest: %v", err)
+	}
+	requestID := request.Data["id"]
+	if request.Namespace != "kufast-requests-tenant1" {
+		t.Errorf("expected the request to be stored in kufast-requests-tenant1, got %s", request.Namespace)
+	}
+
+	pending, err := client.ListTenantTargetRequests("", objectFactory.RequestStatePending)
+	if err != nil {
+		t.Fatalf("ListTenantTargetRequests: %v", err)
+	}
+	if len(pending) != 1 || pending[0].Data["id"] != requestID {
+		t.Fatalf("expected request %s to be pending, got %v", requestID, pending)
+	}
+
+	if err := client.ApproveTenantTargetRequest(requestID); err != nil {
+		t.Fatalf("ApproveTenantTargetRequest: %v", err)
+	}
+
+	quota, err := clientset.CoreV1().ResourceQuotas("tenant1-w1").Get(context.TODO(), "tenant1-w1-limits", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("tenant-target not created: %v", err)
+	}
+	if memory := quota.Spec.Hard["limits.memory"]; memory.String() != "2Gi" {
+		t.Errorf("expected the requested memory limit 2Gi, got %s", memory.String())
+	}
+
+	approved, _ := client.GetTenantTargetRequest(requestID)
+	if state := approved.Labels["kufast/request-state"]; state != objectFactory.RequestStateApproved {
+		t.Errorf("expected request to be approved, got %s", state)
+	}
+	if err := client.ApproveTenantTargetRequest(requestID); tools.GetErrorKind(err) != tools.ERROR_KIND_CONFLICT {
+		t.Errorf("expected approving a request twice to fail with a conflict, got %v", err)
+	}
+}
+
+func TestApproveTenantTargetRequestOfOtherTenant(t *testing.T) {
+	client, clientset := newTestClient(t, "", newTestNode("w1", nil))
+	for _, tenantName := range []string{"tenant1", "tenant2"} {
+		if err := client.CreateTenant(tenantName, TenantOptions{}); err != nil {
+			t.Fatalf("CreateTenant: %v", err)
+		}
+	}
+
+	//tenant2 files a request claiming to be tenant1
+	request := objectFactory.NewTenantTargetRequest("tenant1-abcde", "tenant1", "w1", "1Gi", "1", "10Gi", "1Gi", "1")
+	request.Namespace = "kufast-requests-tenant2"
+	request.Name = "kufast-request-tenant2-abcde"
+	request.Data["id"] = "tenant2-abcde"
+	if _, err := clientset.CoreV1().ConfigMaps(request.Namespace).Create(context.TODO(), request, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create request: %v", err)
+	}
+
+	if err := client.ApproveTenantTargetRequest("tenant2-abcde"); tools.GetErrorKind(err) != tools.ERROR_KIND_FORBIDDEN {
+		t.Errorf("expected a forbidden error for a request filed for another tenant, got %v", err)
+	}
+	if _, err := clientset.CoreV1().Namespaces().Get(context.TODO(), "tenant1-w1", metav1.GetOptions{}); err == nil {
+		t.Error("expected no tenant-target for a request filed for another tenant")
+	}
+}
+
+func TestTenantTargetRequestInvalidLimits(t *testing.T) {
+	client, clientset := newTestClient(t, "", newTestNode("w1", nil))
+	if err := client.CreateTenant("tenant1", TenantOptions{}); err != nil {
+		t.Fatalf("CreateTenant: %v", err)
+	}
+
+	for _, opts := range []TenantTargetOptions{
+		{Memory: "1Gi", CPU: "", Storage: "10Gi", MinStorage: "1Gi", Pods: "1"},
+		{Memory: "lots", CPU: "1", Storage: "10Gi", MinStorage: "1Gi", Pods: "1"},
+		{Memory: "1Gi", CPU: "1", Storage: "10Gi", MinStorage: "1Gi", Pods: "-1"},
+	} {
+		if _, err := client.CreateTenantTargetRequest("tenant1", "w1", opts); tools.GetErrorKind(err) != tools.ERROR_KIND_USAGE {
+			t.Errorf("expected a usage error for the limits %+v, got %v", opts, err)
+		}
+	}
+
+	//The tenant writes the config map of the request directly
+	request := objectFactory.NewTenantTargetRequest("tenant1-abcde", "tenant1", "w1", "1Gi", "lots", "10Gi", "1Gi", "1")
+	if _, err := clientset.CoreV1().ConfigMaps(request.Namespace).Create(context.TODO(), request, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create request: %v", err)
+	}
+	if err := client.ApproveTenantTargetRequest("tenant1-abcde"); tools.GetErrorKind(err) != tools.ERROR_KIND_USAGE {
+		t.Errorf("expected a usage error for a request with an invalid cpu limit, got %v", err)
+	}
+	if _, err := clientset.CoreV1().Namespaces().Get(context.TODO(), "tenant1-w1", metav1.GetOptions{}); err == nil {
+		t.Error("expected no tenant-target for a request with an invalid cpu limit")
+	}
+}
+
+func TestApproveTenantTargetRequestOfUnknownTenant(t *testing.T) {
+	client, clientset := newTestClient(t, "", newTestNode("w1", nil))
+	clientset.PrependReactor("create", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		_, err := clientset.Tracker().Get(v1.SchemeGroupVersion.WithResource("namespaces"), "", action.GetNamespace())
+		return err != nil, nil, err
+	})
+
+	if _, err := client.CreateTenantTargetRequest("tenant1", "w1", defaultTenantTargetOptions); tools.GetErrorKind(err) != tools.ERROR_KIND_NOT_FOUND {
+		t.Errorf("expected a not found error for a tenant without request namespace, got %v", err)
+	}
+
+	//The tenant has been deleted after filing the request
+	if err := client.CreateTenant("tenant1", TenantOptions{}); err != nil {
+		t.Fatalf("CreateTenant: %v", err)
+	}
+	request, err := client.CreateTenantTargetRequest("tenant1", "w1", defaultTenantTargetOptions)
+	if err != nil {
+		t.Fatalf("CreateTenantTargetRequest: %v", err)
+	}
+	if err := clientset.CoreV1().ServiceAccounts("default").Delete(context.TODO(), "tenant1-user", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("delete tenant user: %v", err)
+	}
+
+	if err := client.ApproveTenantTargetRequest(request.Data["id"]); tools.GetErrorKind(err) != tools.ERROR_KIND_NOT_FOUND {
+		t.Errorf("expected a not found error for an unknown tenant, got %v", err)
+	}
+	if _, err := client.GetTenant("tenant1"); err == nil {
+		t.Error("expected the unknown tenant not to be created")
+	}
+}
+
+func TestDenyTenantTargetRequest(t *testing.T) {
+	client, clientset := newTestClient(t, "", newTestNode("w1", nil))
+	if err := client.CreateTenant("tenant1", TenantOptions{}); err != nil {
+		t.Fatalf("CreateTenant: %v", err)
+	}
+
+	request, err := client.CreateTenantTargetRequest("tenant1", "w1", defaultTenantTargetOptions)
+	if err != nil {
+		t.Fatalf("CreateTenantTargetRequest: %v", err)
+	}
+	requestID := request.Data["id"]
+
+	if err := client.DenyTenantTargetRequest(requestID, "no capacity left"); err != nil {
+		t.Fatalf("DenyTenantTargetRequest: %v", err)
+	}
+
+	//The tenant reads the reason from its own requests
+	denied, err := client.ListTenantTargetRequests("tenant1", "")
+	if err != nil {
+		t.Fatalf("ListTenantTargetRequests: %v", err)
+	}
+	if len(denied) != 1 || denied[0].Labels["kufast/request-state"] != objectFactory.RequestStateDenied {
+		t.Fatalf("expected request %s to be denied, got %v", requestID, denied)
+	}
+	if denied[0].Data["reason"] != "no capacity left" {
+		t.Errorf("expected the reason to be stored, got %q", denied[0].Data["reason"])
+	}
+	if _, err := clientset.CoreV1().Namespaces().Get(context.TODO(), "tenant1-w1", metav1.GetOptions{}); err == nil {
+		t.Error("expected no tenant-target for a denied request")
+	}
+	if pending, _ := client.ListTenantTargetRequests("", objectFactory.RequestStatePending); len(pending) != 0 {
+		t.Errorf("expected no pending requests, got %d", len(pending))
+	}
+}
@@ -26,41 +26,24 @@ package clusterOperations
 
 import (
 	"context"
-	"github.com/spf13/cobra"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"kufast/objectFactory"
-	"kufast/tools"
-	"os"
-	"time"
 )
 
-// CreateDeploymentSecret creates a new deploy-secret. All parameters are drawn from the cobra command.
-func CreateDeploymentSecret(secretName string, cmd *cobra.Command) error {
+// CreateDeploymentSecret creates a new deploy-secret from the content of a .dockerconfigjson file.
+func (c *Client) CreateDeploymentSecret(secretName string, dockerConfig []byte, scope ScopeOptions) error {
 
-	clientset, _, err := tools.GetUserClient(cmd)
+	namespaceName, err := c.GetTenantTargetName(scope)
 	if err != nil {
 		return err
 	}
 
-	fileName, err := cmd.Flags().GetString("input")
-	if err != nil {
-		return err
-	}
-
-	namespaceName, err := GetTenantTargetNameFromCmd(cmd)
-	if err != nil {
-		return err
-	}
+	deploymentSecretObject := objectFactory.NewDeploymentSecret(namespaceName, secretName, dockerConfig)
 
-	creds, err := os.ReadFile(fileName)
-	if err != nil {
-		return err
-	}
-
-	deploymentSecretObject := objectFactory.NewDeploymentSecret(namespaceName, secretName, creds)
-
-	_, err = clientset.CoreV1().Secrets(namespaceName).Create(context.TODO(), deploymentSecretObject, metav1.CreateOptions{})
+	_, err = c.clientset.CoreV1().Secrets(namespaceName).Create(context.TODO(), deploymentSecretObject, metav1.CreateOptions{})
 	if err != nil {
 		return err
 	}
@@ -68,16 +51,10 @@ func CreateDeploymentSecret(secretName string, cmd *cobra.Command) error {
 	return nil
 }
 
-// CreateSecret creates a new secret. All parameters are drawn from the cobra command.
-func CreateSecret(secretName string, secretData string, cmd *cobra.Command) error {
-	//Default config block
-	clientset, _, err := tools.GetUserClient(cmd)
-	if err != nil {
-		return err
-	}
-
+// CreateSecret creates a new secret.
+func (c *Client) CreateSecret(secretName string, secretData string, scope ScopeOptions) error {
 	//Get the namespace
-	namespaceName, err := GetTenantTargetNameFromCmd(cmd)
+	namespaceName, err := c.GetTenantTargetName(scope)
 	if err != nil {
 		return err
 	}
@@ -86,95 +63,94 @@ func CreateSecret(secretName string, secretData string, cmd *cobra.Command) erro
 	secretObject := objectFactory.NewSecret(namespaceName, secretName, secretData)
 
 	//Push secret
-	_, err = clientset.CoreV1().Secrets(namespaceName).Create(context.TODO(), secretObject, metav1.CreateOptions{})
+	_, err = c.clientset.CoreV1().Secrets(namespaceName).Create(context.TODO(), secretObject, metav1.CreateOptions{})
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-// GetSecret gets an existing secret. All parameters are drawn from the cobra command.
-func GetSecret(secretName string, cmd *cobra.Command) (*v1.Secret, error) {
+// GetSecret gets an existing secret.
+func (c *Client) GetSecret(secretName string, scope ScopeOptions) (*v1.Secret, error) {
 	//Initial config block
-	namespaceName, err := GetTenantTargetNameFromCmd(cmd)
-	if err != nil {
-		return nil, err
-	}
-
-	clientset, _, err := tools.GetUserClient(cmd)
+	namespaceName, err := c.GetTenantTargetName(scope)
 	if err != nil {
 		return nil, err
 	}
 
 	//execute request
-	secret, err := clientset.CoreV1().Secrets(namespaceName).Get(context.TODO(), secretName, metav1.GetOptions{})
+	secret, err := c.clientset.CoreV1().Secrets(namespaceName).Get(context.TODO(), secretName, metav1.GetOptions{})
 	if err != nil {
-		tools.HandleError(err, cmd)
+		return nil, err
 	}
 
 	return secret, nil
 }
 
-// ListSecrets lists all secrets of a tenant. All parameters are drawn from the cobra command.
-func ListSecrets(cmd *cobra.Command) ([]v1.Secret, error) {
-	clientset, _, err := tools.GetUserClient(cmd)
+// ListSecrets lists all secrets of a tenant.
+func (c *Client) ListSecrets(tenantName string) ([]v1.Secret, error) {
+	tenantName, err := c.GetTenantName(tenantName)
 	if err != nil {
 		return nil, err
 	}
 
-	tenantName, err := GetTenantNameFromCmd(cmd)
+	targets, err := c.ListTargets(tenantName, false)
 	if err != nil {
 		return nil, err
 	}
 
-	targets, err := ListTargetsFromCmd(cmd, false)
+	lists := make([][]v1.Secret, len(targets))
+	err = c.fanOut(len(targets), func(i int) error {
+		list, err := c.clientset.CoreV1().Secrets(tenantName+"-"+targets[i].Name).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		lists[i] = list.Items
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	var results []v1.Secret
-	for _, target := range targets {
-		list, err := clientset.CoreV1().Secrets(tenantName+"-"+target.Name).List(context.TODO(), metav1.ListOptions{})
-		if err != nil {
-			return nil, err
-		}
-		results = append(results, list.Items...)
+	for _, list := range lists {
+		results = append(results, list...)
 	}
-
 	return results, nil
 
 }
 
-// DeleteSecret deletes a secret of a tenant. All parameters are drawn from the cobra command.
-func DeleteSecret(secretName string, cmd *cobra.Command) <-chan string {
-	r := make(chan string)
+// DeleteSecret deletes a secret of a tenant.
+func (c *Client) DeleteSecret(secretName string, scope ScopeOptions) <-chan error {
+	r := make(chan error)
 
 	go func() {
 		defer close(r)
 
-		clientset, _, err := tools.GetUserClient(cmd)
+		namespaceName, err := c.GetTenantTargetName(scope)
 		if err != nil {
-			r <- err.Error()
+			r <- err
 			return
 		}
 
-		namespaceName, err := GetTenantTargetNameFromCmd(cmd)
-
-		err = clientset.CoreV1().Secrets(namespaceName).Delete(context.TODO(), secretName, metav1.DeleteOptions{})
+		err = c.clientset.CoreV1().Secrets(namespaceName).Delete(context.TODO(), secretName, metav1.DeleteOptions{})
 		if err != nil {
-			r <- err.Error()
+			r <- err
 			return
 		}
 
-		for true {
-			_, err := clientset.CoreV1().Secrets(namespaceName).Get(context.TODO(), secretName, metav1.GetOptions{})
-			if err != nil {
-				r <- ""
-				break
-			}
-			time.Sleep(time.Millisecond * 250)
+		err = c.waitForDeletion("secret", namespaceName, secretName, &v1.Secret{},
+			func(options metav1.ListOptions) (runtime.Object, error) {
+				return c.clientset.CoreV1().Secrets(namespaceName).List(context.TODO(), options)
+			},
+			func(options metav1.ListOptions) (watch.Interface, error) {
+				return c.clientset.CoreV1().Secrets(namespaceName).Watch(context.TODO(), options)
+			})
+		if err != nil {
+			r <- err
+			return
 		}
-		r <- ""
+		r <- nil
 
 	}()
 	return r
@@ -0,0 +1,76 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clusterOperations
+
+import (
+	"kufast/tools"
+	"testing"
+)
+
+func TestCreateSecret(t *testing.T) {
+	client, _ := newTestClient(t, "tenant1-w1", newTestTenant("tenant1", "w1", "w1"))
+
+	if err := client.CreateSecret("password", "s3cr3t", ScopeOptions{}); err != nil {
+		t.Fatalf("CreateSecret: %v", err)
+	}
+	secret, err := client.GetSecret("password", ScopeOptions{Tenant: "tenant1"})
+	if err != nil {
+		t.Fatalf("GetSecret: %v", err)
+	}
+	assertGolden(t, "secret", secret)
+
+	if err := client.CreateDeploymentSecret("registry", []byte(`{"auths":{}}`), ScopeOptions{Target: "w1"}); err != nil {
+		t.Fatalf("CreateDeploymentSecret: %v", err)
+	}
+	secret, err = client.GetSecret("registry", ScopeOptions{})
+	if err != nil {
+		t.Fatalf("GetSecret: %v", err)
+	}
+	assertGolden(t, "deploy-secret", secret)
+
+	secrets, err := client.ListSecrets("tenant1")
+	if err != nil {
+		t.Fatalf("ListSecrets: %v", err)
+	}
+	if len(secrets) != 2 {
+		t.Errorf("expected 2 secrets, got %d", len(secrets))
+	}
+}
+
+func TestDeleteSecret(t *testing.T) {
+	client, _ := newTestClient(t, "tenant1-w1", newTestTenant("tenant1", "w1", "w1"))
+	if err := client.CreateSecret("password", "s3cr3t", ScopeOptions{}); err != nil {
+		t.Fatalf("CreateSecret: %v", err)
+	}
+
+	awaitResult(t, client.DeleteSecret("password", ScopeOptions{}))
+
+	if _, err := client.GetSecret("password", ScopeOptions{}); err == nil {
+		t.Errorf("expected the secret to be deleted")
+	}
+	if err := <-client.DeleteSecret("password", ScopeOptions{}); tools.GetErrorKind(err) != tools.ERROR_KIND_NOT_FOUND {
+		t.Errorf("expected a not found error when deleting a missing secret, got %v", err)
+	}
+}
@@ -27,36 +27,20 @@ package clusterOperations
 import (
 	"context"
 	"errors"
-	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/strings/slices"
 	"kufast/tools"
+	"sort"
 	"strings"
 )
 
 // IsValidTarget returns true, if the target is valid for this tenant. If all is true, the function returns if this
-// is a valid target within the cluster.
-func IsValidTarget(cmd *cobra.Command, target string, all bool) bool {
+// is a valid target within the cluster. If tenantName is empty, the tenant of the client is used.
+func (c *Client) IsValidTarget(target string, tenantName string, all bool) bool {
 	if strings.Contains(target, "_") {
 		return false
 	}
-	targets, err := ListTargetsFromCmd(cmd, all)
-	if err != nil {
-		return false
-	}
-	for _, t := range targets {
-		if t.Name == target {
-			return true
-		}
-	}
-	return false
-}
-
-// IsValidTenantTarget returns true, if the target is valid for this tenant. If all is true, the function returns if this
-// is a valid target within the cluster. reads the tenant name from a string.
-func IsValidTenantTarget(cmd *cobra.Command, target string, tenantName string, all bool) bool {
-
-	targets, err := ListTargetsFromString(cmd, tenantName, all)
+	targets, err := c.ListTargets(tenantName, all)
 	if err != nil {
 		return false
 	}
@@ -69,8 +53,8 @@ func IsValidTenantTarget(cmd *cobra.Command, target string, tenantName string, a
 }
 
 // GetTargetFromTargetName returns the target to a specific tragetName.
-func GetTargetFromTargetName(cmd *cobra.Command, targetName string, tenantName string, all bool) (tools.Target, error) {
-	targets, err := ListTargetsFromString(cmd, tenantName, all)
+func (c *Client) GetTargetFromTargetName(targetName string, tenantName string, all bool) (tools.Target, error) {
+	targets, err := c.ListTargets(tenantName, all)
 	if err != nil {
 		return tools.Target{}, err
 	}
@@ -79,23 +63,19 @@ func GetTargetFromTargetName(cmd *cobra.Command, targetName string, tenantName s
 			return t, nil
 		}
 	}
-	return tools.Target{}, errors.New("the target does not exist or the tenant has no access to the target")
+	return tools.Target{}, tools.NewError(tools.ERROR_KIND_INVALID_TARGET, "the target does not exist or the tenant has no access to the target")
 }
 
-// ListTargetsFromString returns a list of targets for a tenant. If all is true, it returns a list of all targets of the
-// cluster.
-func ListTargetsFromString(cmd *cobra.Command, tenantName string, all bool) ([]tools.Target, error) {
+// ListTargets returns a list of targets for a tenant. If all is true, it returns a list of all targets of the
+// cluster. If tenantName is empty, the tenant of the client is used.
+func (c *Client) ListTargets(tenantName string, all bool) ([]tools.Target, error) {
 
-	clientset, _, err := tools.GetUserClient(cmd)
-	if err != nil {
-		return nil, err
-	}
 	var results []tools.Target
 
 	//Do we want the target of the user or all?
 	if all {
 		//This information is only available by parsing the nodes
-		nodes, err := clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+		nodes, err := c.clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
 		if err != nil {
 			return nil, err
 		}
@@ -124,7 +104,7 @@ func ListTargetsFromString(cmd *cobra.Command, tenantName string, all bool) ([]t
 
 	} else {
 
-		user, err := clientset.CoreV1().ServiceAccounts("default").Get(context.TODO(), tenantName+"-user", metav1.GetOptions{})
+		user, err := c.GetTenant(tenantName)
 		if err != nil {
 			return nil, err
 		}
@@ -147,65 +127,63 @@ func ListTargetsFromString(cmd *cobra.Command, tenantName string, all bool) ([]t
 
 }
 
-// ListTargetsFromCmd returns a list of targets for a tenant. If all is true, it returns a list of all targets of the
-// cluster.
-func ListTargetsFromCmd(cmd *cobra.Command, all bool) ([]tools.Target, error) {
-
-	//Get the information from the tenant
-	namespaceName, _ := tools.GetNamespaceFromUserConfig(cmd)
-	tenant, _ := cmd.Flags().GetString("tenant")
-	if tenant == "" {
-		tenant = tools.GetTenantFromNamespace(namespaceName)
-	}
-
-	return ListTargetsFromString(cmd, tenant, all)
-
-}
-
 // SetTargetGroupToNodes Adds all nodes from the array to a target-group. Overwrites previous config.
-func SetTargetGroupToNodes(targetName string, targetNodes []string, cmd *cobra.Command) error {
-	clientset, _, err := tools.GetUserClient(cmd)
+func (c *Client) SetTargetGroupToNodes(targetName string, targetNodes []string) error {
+	nodeList, err := c.clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
 	if err != nil {
 		return errors.New(err.Error())
 	}
 
-	nodeList, err := clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return errors.New(err.Error())
-	}
-
-	if !IsValidTarget(cmd, targetName, true) {
-		for _, node := range nodeList.Items {
-			if slices.Contains(targetNodes, node.Name) {
-				node.ObjectMeta.Labels["kufast.group/"+targetName] = "true"
-			} else {
-				node.ObjectMeta.Labels["kufast.group/"+targetName] = "false"
-			}
-			_, err = clientset.CoreV1().Nodes().Update(context.TODO(), &node, metav1.UpdateOptions{})
-			if err != nil {
-				return errors.New(err.Error())
-			}
+	for _, node := range nodeList.Items {
+		if node.ObjectMeta.Labels == nil {
+			node.ObjectMeta.Labels = map[string]string{}
+		}
+		if slices.Contains(targetNodes, node.Name) {
+			node.ObjectMeta.Labels[tools.KUFAST_NODE_GROUP_LABEL+targetName] = "true"
+		} else {
+			node.ObjectMeta.Labels[tools.KUFAST_NODE_GROUP_LABEL+targetName] = "false"
+		}
+		_, err = c.clientset.CoreV1().Nodes().Update(context.TODO(), &node, metav1.UpdateOptions{})
+		if err != nil {
+			return errors.New(err.Error())
 		}
 	}
 
 	return nil
 }
 
-// DeleteTargetGroupFromNodes removes a target-group from all nodes.
-func DeleteTargetGroupFromNodes(targetName string, cmd *cobra.Command) error {
-	clientset, _, err := tools.GetUserClient(cmd)
+// ListTargetGroups returns all target-groups of the cluster along with the sorted names of their nodes.
+func (c *Client) ListTargetGroups() (map[string][]string, error) {
+	nodeList, err := c.clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
 	if err != nil {
-		return errors.New(err.Error())
+		return nil, err
+	}
+
+	groups := map[string][]string{}
+	for _, node := range nodeList.Items {
+		for key, elem := range node.ObjectMeta.Labels {
+			groupName := strings.TrimPrefix(key, tools.KUFAST_NODE_GROUP_LABEL)
+			if strings.HasPrefix(key, tools.KUFAST_NODE_GROUP_LABEL) && groupName != "" && elem == "true" {
+				groups[groupName] = append(groups[groupName], node.Name)
+			}
+		}
+	}
+	for _, nodes := range groups {
+		sort.Strings(nodes)
 	}
+	return groups, nil
+}
 
-	nodeList, err := clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+// DeleteTargetGroupFromNodes removes a target-group from all nodes.
+func (c *Client) DeleteTargetGroupFromNodes(targetName string) error {
+	nodeList, err := c.clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
 	if err != nil {
 		return errors.New(err.Error())
 	}
-	if IsValidTarget(cmd, targetName, true) {
+	if c.IsValidTarget(targetName, "", true) {
 		for _, node := range nodeList.Items {
-			delete(node.ObjectMeta.Labels, "kufast.group/"+targetName)
-			_, err = clientset.CoreV1().Nodes().Update(context.TODO(), &node, metav1.UpdateOptions{})
+			delete(node.ObjectMeta.Labels, tools.KUFAST_NODE_GROUP_LABEL+targetName)
+			_, err = c.clientset.CoreV1().Nodes().Update(context.TODO(), &node, metav1.UpdateOptions{})
 			if err != nil {
 				return errors.New(err.Error())
 			}
@@ -0,0 +1,138 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clusterOperations
+
+import (
+	"context"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kufast/tools"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestListTargets(t *testing.T) {
+	client, _ := newTestClient(t, "",
+		newTestNode("w1", map[string]string{tools.KUFAST_NODE_GROUP_LABEL + "edge": "true"}),
+		newTestNode("w2", map[string]string{tools.KUFAST_NODE_GROUP_LABEL + "edge": "true", tools.KUFAST_NODE_GROUP_LABEL + "old": "false"}),
+		newTestTenant("tenant1", "w1", "w1"))
+
+	targets, err := client.ListTargets("", true)
+	if err != nil {
+		t.Fatalf("ListTargets: %v", err)
+	}
+	expected := []tools.Target{{Name: "w1", AccessType: "node"}, {Name: "w2", AccessType: "node"}, {Name: "edge", AccessType: "group"}}
+	if !reflect.DeepEqual(targets, expected) {
+		t.Errorf("expected %v, got %v", expected, targets)
+	}
+
+	targets, err = client.ListTargets("tenant1", false)
+	if err != nil {
+		t.Fatalf("ListTargets: %v", err)
+	}
+	if !reflect.DeepEqual(targets, []tools.Target{{Name: "w1", AccessType: "node"}}) {
+		t.Errorf("unexpected tenant targets: %v", targets)
+	}
+
+	if client.IsValidTarget("w2", "tenant1", false) {
+		t.Errorf("w2 must not be a valid target for tenant1")
+	}
+	if !client.IsValidTarget("w2", "tenant1", true) {
+		t.Errorf("w2 must be a valid target within the cluster")
+	}
+}
+
+func TestSetAndDeleteTargetGroup(t *testing.T) {
+	client, clientset := newTestClient(t, "", newTestNode("w1", nil), newTestNode("w2", nil), newTestNode("w3", nil))
+
+	if err := client.SetTargetGroupToNodes("edge", []string{"w1", "w3"}); err != nil {
+		t.Fatalf("SetTargetGroupToNodes: %v", err)
+	}
+
+	nodes, _ := clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	var members []string
+	for _, node := range nodes.Items {
+		value, ok := node.Labels[tools.KUFAST_NODE_GROUP_LABEL+"edge"]
+		if !ok {
+			t.Errorf("node %s has no label for the target-group", node.Name)
+		}
+		if value == "true" {
+			members = append(members, node.Name)
+		}
+	}
+	sort.Strings(members)
+	if !reflect.DeepEqual(members, []string{"w1", "w3"}) {
+		t.Errorf("unexpected target-group members: %v", members)
+	}
+
+	//Existing target-groups are overwritten
+	if err := client.SetTargetGroupToNodes("edge", []string{"w2"}); err != nil {
+		t.Fatalf("SetTargetGroupToNodes: %v", err)
+	}
+	groups, err := client.ListTargetGroups()
+	if err != nil {
+		t.Fatalf("ListTargetGroups: %v", err)
+	}
+	if !reflect.DeepEqual(groups, map[string][]string{"edge": {"w2"}}) {
+		t.Errorf("unexpected target-groups: %v", groups)
+	}
+
+	if err := client.DeleteTargetGroupFromNodes("edge"); err != nil {
+		t.Fatalf("DeleteTargetGroupFromNodes: %v", err)
+	}
+	nodes, _ = clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	for _, node := range nodes.Items {
+		if _, ok := node.Labels[tools.KUFAST_NODE_GROUP_LABEL+"edge"]; ok {
+			t.Errorf("node %s still has the target-group label", node.Name)
+		}
+	}
+}
+
+func TestGetTenantTargetName(t *testing.T) {
+	client, _ := newTestClient(t, "tenant1-w1", newTestTenant("tenant2", "w2", "w2"))
+
+	tests := []struct {
+		scope    ScopeOptions
+		expected string
+	}{
+		{ScopeOptions{}, "tenant1-w1"},
+		{ScopeOptions{Target: "w3"}, "tenant1-w3"},
+		{ScopeOptions{Tenant: "tenant2"}, "tenant2-w2"},
+		{ScopeOptions{Tenant: "tenant3", Target: "w4"}, "tenant3-w4"},
+	}
+	for _, test := range tests {
+		name, err := client.GetTenantTargetName(test.scope)
+		if err != nil {
+			t.Errorf("GetTenantTargetName(%v): %v", test.scope, err)
+		} else if name != test.expected {
+			t.Errorf("GetTenantTargetName(%v): expected %s, got %s", test.scope, test.expected, name)
+		}
+	}
+
+	client, _ = newTestClient(t, "")
+	if _, err := client.GetTenantTargetName(ScopeOptions{}); err == nil {
+		t.Errorf("expected an error without tenant-target and kubeconfig namespace")
+	}
+}
@@ -27,128 +27,121 @@ package clusterOperations
 import (
 	"context"
 	"errors"
-	"github.com/spf13/cobra"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
 	"kufast/objectFactory"
 	"kufast/tools"
-	"time"
 )
 
-// CreateTenant creates a new tenant. All parameters are drawn from the environment on the command line.
-func CreateTenant(tenantName string, cmd *cobra.Command) error {
+// TenantOptions holds the parameters for creating a tenant.
+type TenantOptions struct {
+	// Upsert updates the objects of an existing tenant to the requested spec instead of failing.
+	Upsert bool
+}
+
+// CreateTenant creates a new tenant.
+func (c *Client) CreateTenant(tenantName string, opts TenantOptions) error {
 
-	//Configblock
-	clientset, _, err := tools.GetUserClient(cmd)
+	_, err := c.upsertServiceAccount(objectFactory.NewTenantUser(tenantName, "default"), opts.Upsert)
 	if err != nil {
 		return err
 	}
 
-	_, err = clientset.CoreV1().ServiceAccounts("default").Create(context.TODO(), objectFactory.NewTenantUser(tenantName, "default"), metav1.CreateOptions{})
+	_, err = c.upsertRole(objectFactory.NewTenantDefaultRole(tenantName), opts.Upsert)
 	if err != nil {
 		return err
 	}
 
-	_, err = clientset.RbacV1().Roles("default").Create(context.TODO(), objectFactory.NewTenantDefaultRole(tenantName), metav1.CreateOptions{})
+	_, err = c.upsertRoleBinding(objectFactory.NewTenantDefaultRoleBinding(tenantName), opts.Upsert)
 	if err != nil {
 		return err
 	}
 
-	_, err = clientset.RbacV1().RoleBindings("default").Create(context.TODO(), objectFactory.NewTenantDefaultRoleBinding(tenantName), metav1.CreateOptions{})
+	//Requests of the tenant for tenant-targets are stored in a namespace of their own
+	_, err = c.upsertNamespace(objectFactory.NewRequestNamespace(tenantName), opts.Upsert)
+	if err != nil {
+		return err
+	}
+	err = c.waitForNamespaceActive(objectFactory.RequestNamespace(tenantName))
 	if err != nil {
 		return err
 	}
 
-	timeout := 600
-	for true {
-		timeout--
+	_, err = c.upsertRole(objectFactory.NewTenantRequestRole(tenantName), opts.Upsert)
+	if err != nil {
+		return err
+	}
 
-		if timeout == 0 {
-			return errors.New(`Operation Timeout. Your tenant has been initialized but it is not ready yet. 
-Please ensure it is fully initialized and get its credentials from 'kufast get tenant-creds'`)
-		}
-		tenant, err := clientset.CoreV1().ServiceAccounts("default").Get(context.TODO(), tenantName+"-user", metav1.GetOptions{})
-		time.Sleep(time.Millisecond * 1000)
-		if err == nil && tenant.Secrets != nil && len(tenant.Secrets) > 0 {
-			break
-		} else if err != nil {
-			return err
-		}
+	_, err = c.upsertRoleBinding(objectFactory.NewTenantRequestRoleBinding(tenantName), opts.Upsert)
+	if err != nil {
+		return err
 	}
+
 	return nil
 }
 
-// DeleteTenant Deletes a tenant. All parameters are drawn from the environment on the command line.
-func DeleteTenant(tenantName string, cmd *cobra.Command) error {
-	//Configblock
-	clientset, _, err := tools.GetUserClient(cmd)
+// DeleteTenant Deletes a tenant.
+func (c *Client) DeleteTenant(tenantName string) error {
+	err := c.clientset.CoreV1().ServiceAccounts("default").Delete(context.TODO(), tenantName+"-user", metav1.DeleteOptions{})
 	if err != nil {
 		return err
 	}
 
-	err = clientset.CoreV1().ServiceAccounts("default").Delete(context.TODO(), tenantName+"-user", metav1.DeleteOptions{})
+	err = c.clientset.RbacV1().Roles("default").Delete(context.TODO(), tenantName+"-defaultrole", metav1.DeleteOptions{})
 	if err != nil {
 		return err
 	}
 
-	err = clientset.RbacV1().Roles("default").Delete(context.TODO(), tenantName+"-defaultrole", metav1.DeleteOptions{})
+	err = c.clientset.RbacV1().RoleBindings("default").Delete(context.TODO(), tenantName+"-defaultrolebinding", metav1.DeleteOptions{})
 	if err != nil {
 		return err
 	}
 
-	err = clientset.RbacV1().RoleBindings("default").Delete(context.TODO(), tenantName+"-defaultrolebinding", metav1.DeleteOptions{})
-	if err != nil {
+	//Tenants created by older versions of kufast have no request namespace
+	err = c.clientset.CoreV1().Namespaces().Delete(context.TODO(), objectFactory.RequestNamespace(tenantName), metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
 		return err
 	}
 
 	return nil
 }
 
-// GetTenantNameFromCmd gets the name of a tenant from cmd. All parameters are drawn from the environment on the command line.
-func GetTenantNameFromCmd(cmd *cobra.Command) (string, error) {
-	tenant, _ := cmd.Flags().GetString("tenant")
-	if tenant == "" {
-		namespaceName, err := tools.GetNamespaceFromUserConfig(cmd)
-		if err != nil {
-			return "", err
-		}
-		return tools.GetTenantFromNamespace(namespaceName), nil
-	}
-	return tenant, nil
-}
-
-// GetTenantFromCmd gets a tenant object. All parameters are drawn from the environment on the command line.
-func GetTenantFromCmd(cmd *cobra.Command) (*v1.ServiceAccount, error) {
+// ListTenantObjects lists the user of a tenant and the objects of all its tenant-targets, which are deleted together
+// with the tenant. The objects are returned as <kind>/<name>, namespaced objects are followed by their namespace.
+func (c *Client) ListTenantObjects(tenantName string) ([]string, error) {
+	objects := []string{"serviceaccount/" + tenantName + "-user"}
 
-	tenantName, err := GetTenantNameFromCmd(cmd)
+	targets, err := c.ListTargets(tenantName, false)
 	if err != nil {
 		return nil, err
 	}
-
-	//Configblock
-	clientset, _, err := tools.GetUserClient(cmd)
+	targetObjects := make([][]string, len(targets))
+	err = c.fanOut(len(targets), func(i int) error {
+		list, err := c.ListTenantTargetObjects(tenantName, targets[i].Name)
+		targetObjects[i] = list
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	user, err := clientset.CoreV1().ServiceAccounts("default").Get(context.TODO(), tenantName+"-user", metav1.GetOptions{})
-	if err != nil {
-		return nil, err
+	for _, list := range targetObjects {
+		objects = append(objects, list...)
 	}
 
-	return user, nil
+	return objects, nil
 }
 
-// GetTenantFromString gets a tenant object from its name. All parameters are drawn from the environment on the command line.
-func GetTenantFromString(cmd *cobra.Command, tenantName string) (*v1.ServiceAccount, error) {
+// GetTenant gets a tenant object from its name. If tenantName is empty, the tenant of the client is used.
+func (c *Client) GetTenant(tenantName string) (*v1.ServiceAccount, error) {
 
-	//Configblock
-	clientset, _, err := tools.GetUserClient(cmd)
+	tenantName, err := c.GetTenantName(tenantName)
 	if err != nil {
 		return nil, err
 	}
 
-	user, err := clientset.CoreV1().ServiceAccounts("default").Get(context.TODO(), tenantName+"-user", metav1.GetOptions{})
+	user, err := c.clientset.CoreV1().ServiceAccounts("default").Get(context.TODO(), tenantName+"-user", metav1.GetOptions{})
 	if err != nil {
 		return nil, err
 	}
@@ -156,21 +149,31 @@ func GetTenantFromString(cmd *cobra.Command, tenantName string) (*v1.ServiceAcco
 	return user, nil
 }
 
-// UpdateTenantDefaultDeployTarget sets the kufast/default label of a tenant to a new value.
-func UpdateTenantDefaultDeployTarget(newDefaultTarget string, cmd *cobra.Command) error {
-	//Configblock
-	clientset, _, err := tools.GetUserClient(cmd)
+// ListTenants lists all tenants of the cluster.
+func (c *Client) ListTenants() ([]v1.ServiceAccount, error) {
+	users, err := c.clientset.CoreV1().ServiceAccounts("default").List(context.TODO(), metav1.ListOptions{})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	tenant, err := GetTenantFromCmd(cmd)
+	var results []v1.ServiceAccount
+	for _, user := range users.Items {
+		if user.ObjectMeta.Labels[tools.KUFAST_TENANT_LABEL] != "" {
+			results = append(results, user)
+		}
+	}
+	return results, nil
+}
+
+// UpdateTenantDefaultDeployTarget sets the kufast/default label of a tenant to a new value.
+func (c *Client) UpdateTenantDefaultDeployTarget(newDefaultTarget string, tenantName string) error {
+	tenant, err := c.GetTenant(tenantName)
 	if err != nil {
 		return err
 	}
 
 	tenant.ObjectMeta.Labels[tools.KUFAST_TENANT_DEFAULT_LABEL] = newDefaultTarget
-	_, err = clientset.CoreV1().ServiceAccounts("default").Update(context.TODO(), tenant, metav1.UpdateOptions{})
+	_, err = c.clientset.CoreV1().ServiceAccounts("default").Update(context.TODO(), tenant, metav1.UpdateOptions{})
 	if err != nil {
 		return err
 	}
@@ -180,81 +183,69 @@ func UpdateTenantDefaultDeployTarget(newDefaultTarget string, cmd *cobra.Command
 }
 
 // DeleteTargetFromTenant deletes a target from a tenant.
-func DeleteTargetFromTenant(targetName string, tenantName string, cmd *cobra.Command) error {
-	if IsValidTenantTarget(cmd, targetName, tenantName, false) {
-		clientset, _, err := tools.GetUserClient(cmd)
-		if err != nil {
-			return errors.New(err.Error())
-		}
-
-		target, err := GetTargetFromTargetName(cmd, targetName, tenantName, false)
-		if err != nil {
-			return errors.New(err.Error())
-		}
-
-		tenant, err := GetTenantFromString(cmd, tenantName)
-		if err != nil {
-			return errors.New(err.Error())
-		}
-
-		if target.AccessType == "node" {
-			delete(tenant.ObjectMeta.Labels, tools.KUFAST_TENANT_NODEACCESS_LABEL+targetName)
-		} else {
-			delete(tenant.ObjectMeta.Labels, tools.KUFAST_TENANT_GROUPACCESS_LABEL+targetName)
-		}
-		_, err = clientset.CoreV1().ServiceAccounts("default").Update(context.TODO(), tenant, metav1.UpdateOptions{})
+func (c *Client) DeleteTargetFromTenant(targetName string, tenantName string) error {
+	if c.IsValidTarget(targetName, tenantName, false) {
+		target, err := c.GetTargetFromTargetName(targetName, tenantName, false)
 		if err != nil {
 			return errors.New(err.Error())
 		}
 
-	} else {
-		return errors.New("Not a valid target for this tenant: " + targetName)
+		//Tenant-targets of a tenant are created and deleted concurrently, so conflicting updates are retried
+		return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			tenant, err := c.GetTenant(tenantName)
+			if err != nil {
+				return err
+			}
+
+			if target.AccessType == "node" {
+				delete(tenant.ObjectMeta.Labels, tools.KUFAST_TENANT_NODEACCESS_LABEL+targetName)
+			} else {
+				delete(tenant.ObjectMeta.Labels, tools.KUFAST_TENANT_GROUPACCESS_LABEL+targetName)
+			}
+			_, err = c.clientset.CoreV1().ServiceAccounts("default").Update(context.TODO(), tenant, metav1.UpdateOptions{})
+			return err
+		})
 	}
 
-	return nil
+	return tools.NewError(tools.ERROR_KIND_INVALID_TARGET, "Not a valid target for this tenant: "+targetName)
 }
 
 // AddTargetToTenant adds a new target to a tenant.
-func AddTargetToTenant(cmd *cobra.Command, targetName string, tenantName string) error {
-	if IsValidTenantTarget(cmd, targetName, tenantName, true) {
-		clientset, _, err := tools.GetUserClient(cmd)
-		if err != nil {
-			return errors.New(err.Error())
-		}
-
-		target, err := GetTargetFromTargetName(cmd, targetName, tenantName, true)
+func (c *Client) AddTargetToTenant(targetName string, tenantName string) error {
+	if c.IsValidTarget(targetName, tenantName, true) {
+		target, err := c.GetTargetFromTargetName(targetName, tenantName, true)
 		if err != nil {
 			return err
 		}
-		tenant, err := GetTenantFromString(cmd, tenantName)
-		if err != nil {
-			return err
-		}
-		if target.AccessType == "node" {
-			tenant.ObjectMeta.Labels[tools.KUFAST_TENANT_NODEACCESS_LABEL+targetName] = "true"
-		} else {
-			tenant.ObjectMeta.Labels[tools.KUFAST_TENANT_GROUPACCESS_LABEL+targetName] = "true"
-		}
 
-		// Populate default label if possible
-		if tenant.ObjectMeta.Labels[tools.KUFAST_TENANT_DEFAULT_LABEL] == "" {
-			tenant.ObjectMeta.Labels[tools.KUFAST_TENANT_DEFAULT_LABEL] = targetName
-		}
-		_, err = clientset.CoreV1().ServiceAccounts("default").Update(context.TODO(), tenant, metav1.UpdateOptions{})
-		if err != nil {
+		//Tenant-targets of a tenant are created and deleted concurrently, so conflicting updates are retried
+		return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			tenant, err := c.GetTenant(tenantName)
+			if err != nil {
+				return err
+			}
+			if target.AccessType == "node" {
+				tenant.ObjectMeta.Labels[tools.KUFAST_TENANT_NODEACCESS_LABEL+targetName] = "true"
+			} else {
+				tenant.ObjectMeta.Labels[tools.KUFAST_TENANT_GROUPACCESS_LABEL+targetName] = "true"
+			}
+
+			// Populate default label if possible
+			if tenant.ObjectMeta.Labels[tools.KUFAST_TENANT_DEFAULT_LABEL] == "" {
+				tenant.ObjectMeta.Labels[tools.KUFAST_TENANT_DEFAULT_LABEL] = targetName
+			}
+			_, err = c.clientset.CoreV1().ServiceAccounts("default").Update(context.TODO(), tenant, metav1.UpdateOptions{})
 			return err
-		}
-
-		return nil
+		})
 	}
 
-	return errors.New("Invalid target!")
+	return tools.NewError(tools.ERROR_KIND_INVALID_TARGET, "Invalid target: "+targetName)
 }
 
-// GetTenantDefaultTargetNameFromCmd returns the default target name of a tenant from cmd parameters.
-func GetTenantDefaultTargetNameFromCmd(cmd *cobra.Command) (string, error) {
+// GetTenantDefaultTargetName returns the default target name of a tenant.
+func (c *Client) GetTenantDefaultTargetName(tenantName string) (string, error) {
 
-	user, err := GetTenantFromCmd(cmd)
+	user, err := c.GetTenant(tenantName)
 	if err != nil {
 		return "", err
 	}
@@ -26,116 +26,338 @@ package clusterOperations
 
 import (
 	"context"
-	"github.com/spf13/cobra"
+	"encoding/json"
+	"errors"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
 	"kufast/objectFactory"
 	"kufast/tools"
-	"time"
 )
 
-// CreateTenantTarget creates a new tenant-target
-func CreateTenantTarget(tenantName string, targetName string, cmd *cobra.Command) <-chan string {
-	res := make(chan string)
+// TenantTargetOptions contains the resource limits of a tenant-target. Empty values are not applied.
+type TenantTargetOptions struct {
+	Memory     string
+	CPU        string
+	Storage    string
+	MinStorage string
+	Pods       string
+	// Upsert updates the objects of an existing tenant-target to the requested spec instead of failing.
+	Upsert bool
+}
 
-	go func() {
-		defer close(res)
+// creationStep is a single step of the creation of a tenant-target.
+type creationStep struct {
+	name string
+	run  func() error
+}
 
-		clientset, _, err := tools.GetUserClient(cmd)
-		if err != nil {
-			res <- err.Error()
-			return
-		}
+// CreateTenantTarget creates a new tenant-target and grants the tenant access to its target. The creation is all or
+// nothing: If a step fails, all objects created so far are removed again and the error names the failed step.
+// With opts.Upsert, existing objects are updated to the requested spec; they are kept, if a later step fails.
+func (c *Client) CreateTenantTarget(tenantName string, targetName string, opts TenantTargetOptions) <-chan error {
+	res := make(chan error)
+	newNamespaceName := tenantName + "-" + targetName
 
-		newNamespaceName := tenantName + "-" + targetName
+	//The access label is granted before returning, so the first target of a tenant deterministically becomes its
+	//default target, even if several tenant-targets are created concurrently.
+	target, previousDefault, accessGranted, err := c.grantTargetAccess(tenantName, targetName)
 
-		ram, _ := cmd.Flags().GetString("memory")
-		cpu, _ := cmd.Flags().GetString("cpu")
-		storage, _ := cmd.Flags().GetString("storage")
-		minStorage, _ := cmd.Flags().GetString("storage-min")
-		pods, _ := cmd.Flags().GetString("pods")
+	go func() {
+		defer close(res)
 
-		target, err := GetTargetFromTargetName(cmd, targetName, tenantName, true)
 		if err != nil {
-			res <- err.Error()
+			res <- tools.WithMessage(err, "Failed to create tenant-target "+newNamespaceName+" at step 'tenant access label': "+err.Error())
 			return
 		}
 
-		_, err = clientset.CoreV1().Namespaces().Create(context.TODO(), objectFactory.NewNamespace(tenantName, target, cmd), metav1.CreateOptions{})
-		if err != nil {
-			res <- err.Error()
-			return
+		namespaceCreated := false
+		steps := []creationStep{
+			{"namespace", func() error {
+				namespace := objectFactory.NewNamespace(tenantName, target)
+				err := recordLimits(namespace, opts)
+				if err != nil {
+					return err
+				}
+				created, err := c.upsertNamespace(namespace, opts.Upsert)
+				namespaceCreated = created
+				return err
+			}},
+			{"namespace activation", func() error {
+				return c.waitForNamespaceActive(newNamespaceName)
+			}},
+			{"resource quota", func() error {
+				_, err := c.upsertResourceQuota(objectFactory.NewResourceQuota(newNamespaceName, opts.Memory, opts.CPU, opts.Storage, opts.Pods), opts.Upsert)
+				return err
+			}},
+			{"role", func() error {
+				_, err := c.upsertRole(objectFactory.NewRole(newNamespaceName), opts.Upsert)
+				return err
+			}},
+			{"limit range", func() error {
+				_, err := c.upsertLimitRange(objectFactory.NewLimitRange(newNamespaceName, opts.MinStorage, opts.Storage), opts.Upsert)
+				return err
+			}},
+			{"network policy", func() error {
+				_, err := c.upsertNetworkPolicy(objectFactory.NewNetworkPolicy(newNamespaceName, tenantName), opts.Upsert)
+				return err
+			}},
+			{"role binding", func() error {
+				_, err := c.upsertRoleBinding(objectFactory.NewTenantRolebinding(newNamespaceName, tenantName), opts.Upsert)
+				return err
+			}},
 		}
 
-		for true {
-			newNamespace, err := clientset.CoreV1().Namespaces().Get(context.TODO(), tenantName+"-"+targetName, metav1.GetOptions{})
-			if err != nil {
-				res <- err.Error()
-				return
+		for _, step := range steps {
+			stepErr := step.run()
+			if stepErr == nil {
+				continue
 			}
-			if newNamespace.Status.Phase == "Active" {
-				break
-			}
-			time.Sleep(time.Millisecond * 250)
-		}
 
-		_, err = clientset.CoreV1().ResourceQuotas(newNamespaceName).Create(context.TODO(), objectFactory.NewResourceQuota(newNamespaceName, ram, cpu, storage, pods), metav1.CreateOptions{})
-		if err != nil {
-			res <- err.Error()
+			msg := "Failed to create tenant-target " + newNamespaceName + " at step '" + step.name + "': " + stepErr.Error()
+			err = c.rollbackTenantTarget(tenantName, target, namespaceCreated, accessGranted, previousDefault)
+			if err != nil {
+				msg += "\nThe rollback failed as well: " + err.Error() + "\nPlease clean up with 'kufast delete tenant-target " +
+					targetName + " --tenant " + tenantName + "'"
+			} else {
+				msg += "\nAll changes have been rolled back."
+			}
+			res <- tools.WithMessage(stepErr, msg)
 			return
 		}
 
-		_, err = clientset.RbacV1().Roles(newNamespaceName).Create(context.TODO(), objectFactory.NewRole(newNamespaceName), metav1.CreateOptions{})
-		if err != nil {
-			res <- err.Error()
-			return
+		res <- nil
+	}()
+	return res
+
+}
+
+// grantTargetAccess grants a tenant access to a target, if it has no access yet. It returns the target, the
+// previous default target of the tenant and whether the access has been granted by this call.
+func (c *Client) grantTargetAccess(tenantName string, targetName string) (tools.Target, string, bool, error) {
+	target, err := c.GetTargetFromTargetName(targetName, tenantName, true)
+	if err != nil {
+		return target, "", false, err
+	}
+	tenant, err := c.GetTenant(tenantName)
+	if err != nil {
+		return target, "", false, err
+	}
+	previousDefault := tenant.ObjectMeta.Labels[tools.KUFAST_TENANT_DEFAULT_LABEL]
+
+	if c.IsValidTarget(targetName, tenantName, false) {
+		return target, previousDefault, false, nil
+	}
+	err = c.AddTargetToTenant(targetName, tenantName)
+	return target, previousDefault, err == nil, err
+}
+
+// rollbackTenantTarget removes a partially created tenant-target. Only the namespace and access label created by
+// kufast are removed; deleting the namespace removes all objects within it.
+func (c *Client) rollbackTenantTarget(tenantName string, target tools.Target, namespaceCreated bool, accessGranted bool, previousDefault string) error {
+	if namespaceCreated {
+		err := c.clientset.CoreV1().Namespaces().Delete(context.TODO(), tenantName+"-"+target.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
 		}
+	}
 
-		_, err = clientset.CoreV1().LimitRanges(newNamespaceName).Create(context.TODO(), objectFactory.NewLimitRange(newNamespaceName, minStorage, storage), metav1.CreateOptions{})
+	if !accessGranted {
+		return nil
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		tenant, err := c.GetTenant(tenantName)
 		if err != nil {
-			res <- err.Error()
-			return
+			return err
 		}
+		if target.AccessType == "node" {
+			delete(tenant.ObjectMeta.Labels, tools.KUFAST_TENANT_NODEACCESS_LABEL+target.Name)
+		} else {
+			delete(tenant.ObjectMeta.Labels, tools.KUFAST_TENANT_GROUPACCESS_LABEL+target.Name)
+		}
+		if tenant.ObjectMeta.Labels[tools.KUFAST_TENANT_DEFAULT_LABEL] == target.Name {
+			tenant.ObjectMeta.Labels[tools.KUFAST_TENANT_DEFAULT_LABEL] = previousDefault
+		}
+		_, err = c.clientset.CoreV1().ServiceAccounts("default").Update(context.TODO(), tenant, metav1.UpdateOptions{})
+		return err
+	})
+}
 
-		_, err = clientset.NetworkingV1().NetworkPolicies(newNamespaceName).Create(context.TODO(), objectFactory.NewNetworkPolicy(newNamespaceName, tenantName), metav1.CreateOptions{})
-		if err != nil {
-			res <- err.Error()
-			return
+// recordLimits stores the limits of a tenant-target in an annotation of its namespace. Tenants cannot change their
+// namespace, so the recorded limits tell, which quota the tenant-target should have.
+func recordLimits(namespace *v1.Namespace, opts TenantTargetOptions) error {
+	limits, err := json.Marshal(objectFactory.TenantTargetLimits{Memory: opts.Memory, CPU: opts.CPU, Storage: opts.Storage,
+		MinStorage: opts.MinStorage, Pods: opts.Pods})
+	if err != nil {
+		return err
+	}
+	if namespace.Annotations == nil {
+		namespace.Annotations = map[string]string{}
+	}
+	namespace.Annotations[tools.KUFAST_NAMESPACE_LIMITS_ANNOTATION] = string(limits)
+	return nil
+}
+
+// recordedLimits returns the limits stored in the namespace of a tenant-target. It returns false, if the tenant-target
+// has been created by an older version of kufast, which did not record its limits.
+func recordedLimits(namespace *v1.Namespace) (TenantTargetOptions, bool) {
+	var limits objectFactory.TenantTargetLimits
+	annotation, ok := namespace.Annotations[tools.KUFAST_NAMESPACE_LIMITS_ANNOTATION]
+	if !ok || json.Unmarshal([]byte(annotation), &limits) != nil {
+		return TenantTargetOptions{}, false
+	}
+	return TenantTargetOptions{Memory: limits.Memory, CPU: limits.CPU, Storage: limits.Storage,
+		MinStorage: limits.MinStorage, Pods: limits.Pods}, true
+}
+
+// UpdateTenantTarget updates the limits of a tenant-target and updates its role scheme and network policy to the
+// latest version of kufast.
+func (c *Client) UpdateTenantTarget(tenantName string, targetName string, opts TenantTargetOptions) error {
+	tenantName, err := c.GetTenantName(tenantName)
+	if err != nil {
+		return err
+	}
+
+	tenantTargetName := tenantName + "-" + targetName
+
+	//Get Current Namespace
+	namespace, err := c.GetTenantTarget(tenantName, targetName)
+	if err != nil {
+		return err
+	}
+
+	//Get quotas for namespace
+	quota, err := c.GetTenantTargetQuota(tenantName, targetName)
+	if err != nil {
+		return err
+	}
+
+	//Get Networkpolicy for namespace
+	nps, err := c.clientset.NetworkingV1().NetworkPolicies(tenantTargetName).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	target, err := c.GetTargetFromTargetName(targetName, tenantName, false)
+	if err != nil {
+		return err
+	}
+
+	if namespace.ObjectMeta.Annotations == nil {
+		//No annotations have been provided, need to create them
+		namespace.ObjectMeta.Annotations = map[string]string{}
+	}
+	namespace.ObjectMeta.Annotations[tools.KUFAST_NAMESPACE_NODE_SELECTOR_ANNOTATION] = objectFactory.NewNamespace(tenantName, target).
+		ObjectMeta.Annotations[tools.KUFAST_NAMESPACE_NODE_SELECTOR_ANNOTATION]
+
+	if opts.Memory != "" {
+		qty, err := resource.ParseQuantity(opts.Memory)
+		if err == nil {
+			quota.Spec.Hard["limits.memory"] = qty
+			quota.Spec.Hard["requests.memory"] = qty
+		}
+	}
+	if opts.CPU != "" {
+		qty, err := resource.ParseQuantity(opts.CPU)
+		if err == nil {
+			quota.Spec.Hard["limits.cpu"] = qty
+			quota.Spec.Hard["requests.cpu"] = qty
+		}
+	}
+	if opts.Storage != "" {
+		qty, err := resource.ParseQuantity(opts.Storage)
+		if err == nil {
+			quota.Spec.Hard["limits.ephemeral-storage"] = qty
+			quota.Spec.Hard["requests.ephemeral-storage"] = qty
+			quota.Spec.Hard["requests.storage"] = qty
 		}
+	}
 
-		_, err = clientset.RbacV1().RoleBindings(newNamespaceName).Create(context.TODO(), objectFactory.NewTenantRolebinding(newNamespaceName, tenantName), metav1.CreateOptions{})
-		if err != nil {
-			res <- err.Error()
-			return
+	//Limits, which are not updated, keep their recorded values. Tenant-targets of older versions of kufast start
+	//recording with the limits of their quota.
+	limits, ok := recordedLimits(namespace)
+	if !ok {
+		hard := func(name v1.ResourceName) string {
+			if qty, ok := quota.Spec.Hard[name]; ok {
+				return qty.String()
+			}
+			return ""
+		}
+		limits = TenantTargetOptions{Memory: hard("limits.memory"), CPU: hard("limits.cpu"), Storage: hard("requests.storage"), Pods: hard("pods")}
+	}
+	for _, limit := range []struct {
+		recorded *string
+		updated  string
+	}{
+		{&limits.Memory, opts.Memory},
+		{&limits.CPU, opts.CPU},
+		{&limits.Storage, opts.Storage},
+		{&limits.MinStorage, opts.MinStorage},
+		{&limits.Pods, opts.Pods},
+	} {
+		if limit.updated != "" {
+			*limit.recorded = limit.updated
+		}
+	}
+	err = recordLimits(namespace, limits)
+	if err != nil {
+		return err
+	}
+	if opts.Pods != "" {
+		qty, err := resource.ParseQuantity(opts.Pods)
+		if err == nil {
+			quota.Spec.Hard["pods"] = qty
 		}
+	}
 
-		res <- ""
-	}()
-	return res
+	networkPolicy := objectFactory.NewNetworkPolicy(tenantTargetName, tenantName)
+	if len(nps.Items) == 0 {
+		//No policy available, create it
+		_, err = c.clientset.NetworkingV1().NetworkPolicies(tenantTargetName).Create(context.TODO(), networkPolicy, metav1.CreateOptions{})
+	} else if len(nps.Items) == 1 {
+		//Update Policy
+		networkPolicy.ObjectMeta.ResourceVersion = nps.Items[0].ResourceVersion
+		_, err = c.clientset.NetworkingV1().NetworkPolicies(tenantTargetName).Update(context.TODO(), networkPolicy, metav1.UpdateOptions{})
+	} else {
+		err = errors.New("More than one network policy detected in " + tenantTargetName + "! Please resolve this manually.")
+	}
+	if err != nil {
+		return err
+	}
 
+	//Apply changes
+	_, err = c.clientset.CoreV1().ResourceQuotas(tenantTargetName).Update(context.TODO(), quota, metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+	_, err = c.clientset.RbacV1().Roles(tenantTargetName).Update(context.TODO(), objectFactory.NewRole(tenantTargetName), metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+	_, err = c.clientset.CoreV1().Namespaces().Update(context.TODO(), namespace, metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+
+	return nil
 }
 
 // DeleteTenantTarget deletes a tenant-target
-func DeleteTenantTarget(targetName string, tenantName string, cmd *cobra.Command) <-chan string {
-	res := make(chan string)
+func (c *Client) DeleteTenantTarget(targetName string, tenantName string) <-chan error {
+	res := make(chan error)
 
 	go func() {
 		defer close(res)
 
-		//Configblock
-		clientset, _, err := tools.GetUserClient(cmd)
+		err := c.clientset.CoreV1().Namespaces().Delete(context.TODO(), tenantName+"-"+targetName, metav1.DeleteOptions{})
 		if err != nil {
-			res <- err.Error()
+			res <- err
 			return
 		}
 
-		err = clientset.CoreV1().Namespaces().Delete(context.TODO(), tenantName+"-"+targetName, metav1.DeleteOptions{})
-		if err != nil {
-			res <- err.Error()
-			return
-		}
-
-		res <- ""
+		res <- nil
 
 	}()
 	return res
@@ -143,71 +365,140 @@ func DeleteTenantTarget(targetName string, tenantName string, cmd *cobra.Command
 }
 
 // GetTenantTarget gets a tenant-target
-func GetTenantTarget(tenantName string, targetName string, cmd *cobra.Command) (*v1.Namespace, error) {
+func (c *Client) GetTenantTarget(tenantName string, targetName string) (*v1.Namespace, error) {
 
-	//Configblock
-	clientset, _, err := tools.GetUserClient(cmd)
+	tenantTarget, err := c.clientset.CoreV1().Namespaces().Get(context.TODO(), tenantName+"-"+targetName, metav1.GetOptions{})
 	if err != nil {
 		return nil, err
 	}
+	return tenantTarget, nil
 
-	tenantTarget, err := clientset.CoreV1().Namespaces().Get(context.TODO(), tenantName+"-"+targetName, metav1.GetOptions{})
+}
+
+// GetTenantTargetQuota gets the resource quota of a tenant-target
+func (c *Client) GetTenantTargetQuota(tenantName string, targetName string) (*v1.ResourceQuota, error) {
+	tenantTargetName := tenantName + "-" + targetName
+
+	quota, err := c.clientset.CoreV1().ResourceQuotas(tenantTargetName).Get(context.TODO(), tenantTargetName+"-limits", metav1.GetOptions{})
 	if err != nil {
 		return nil, err
 	}
-	return tenantTarget, nil
+	return quota, nil
+}
+
+// ListTenantTargetPods lists all pods of a tenant-target
+func (c *Client) ListTenantTargetPods(tenantName string, targetName string) ([]v1.Pod, error) {
+	pods, err := c.clientset.CoreV1().Pods(tenantName+"-"+targetName).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return pods.Items, nil
+}
+
+// ListTenantTargetObjects lists the namespace, pods and secrets of a tenant-target, which are deleted together with
+// it. The objects are returned as <kind>/<name>, namespaced objects are followed by their namespace.
+func (c *Client) ListTenantTargetObjects(tenantName string, targetName string) ([]string, error) {
+	namespaceName := tenantName + "-" + targetName
+	objects := []string{"namespace/" + namespaceName}
+
+	pods, err := c.clientset.CoreV1().Pods(namespaceName).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range pods.Items {
+		objects = append(objects, "pod/"+pod.Name+" ("+namespaceName+")")
+	}
+
+	secrets, err := c.clientset.CoreV1().Secrets(namespaceName).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, secret := range secrets.Items {
+		objects = append(objects, "secret/"+secret.Name+" ("+namespaceName+")")
+	}
 
+	return objects, nil
 }
 
-// ListTenantTarget lists a new tenant-target
-func ListTenantTargets(tenantName string, cmd *cobra.Command) ([]*v1.Namespace, error) {
+// ListTenantTargets lists all tenant-targets of a tenant
+func (c *Client) ListTenantTargets(tenantName string) ([]*v1.Namespace, error) {
 
-	tenantTargets, err := ListTargetsFromString(cmd, tenantName, false)
+	tenantName, err := c.GetTenantName(tenantName)
 	if err != nil {
 		return nil, err
 	}
 
-	var tenantTargetObjects []*v1.Namespace
+	tenantTargets, err := c.ListTargets(tenantName, false)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, target := range tenantTargets {
-		tenantTarget, err := GetTenantTarget(tenantName, target.Name, cmd)
-		if err != nil {
-			return nil, err
-		}
-		tenantTargetObjects = append(tenantTargetObjects, tenantTarget)
+	tenantTargetObjects := make([]*v1.Namespace, len(tenantTargets))
+	err = c.fanOut(len(tenantTargets), func(i int) error {
+		tenantTarget, err := c.GetTenantTarget(tenantName, tenantTargets[i].Name)
+		tenantTargetObjects[i] = tenantTarget
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return tenantTargetObjects, nil
 
 }
 
-// GetTenantTargetNameFromCmd gets the tenant targets name from the command.
-func GetTenantTargetNameFromCmd(cmd *cobra.Command) (string, error) {
-
-	namespaceName, err := tools.GetNamespaceFromUserConfig(cmd)
+// listExistingTenantTargets lists the tenant-targets of a tenant like ListTenantTargets, but skips the targets whose
+// namespace does not exist, e.g. because it has been deleted by hand.
+func (c *Client) listExistingTenantTargets(tenantName string) ([]*v1.Namespace, error) {
+	targets, err := c.ListTargets(tenantName, false)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	tenantName, err := cmd.Flags().GetString("tenant")
+
+	namespaces := make([]*v1.Namespace, len(targets))
+	err = c.fanOut(len(targets), func(i int) error {
+		namespace, err := c.GetTenantTarget(tenantName, targets[i].Name)
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		namespaces[i] = namespace
+		return err
+	})
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	targetName, err := cmd.Flags().GetString("target")
-	if err != nil {
-		return "", err
+
+	var existing []*v1.Namespace
+	for _, namespace := range namespaces {
+		if namespace != nil {
+			existing = append(existing, namespace)
+		}
 	}
+	return existing, nil
+}
 
-	if tenantName != "" && targetName != "" {
-		namespaceName = tenantName + "-" + targetName
-	} else if targetName != "" {
-		tenantName = tools.GetTenantFromNamespace(namespaceName)
-		namespaceName = tenantName + "-" + targetName
-	} else if tenantName != "" {
-		defaultTargetName, err := GetTenantDefaultTargetNameFromCmd(cmd)
+// GetTenantTargetName returns the name of the tenant-target selected by scope. Missing values are filled with the
+// tenant and target of the client or the default target of the tenant.
+func (c *Client) GetTenantTargetName(scope ScopeOptions) (string, error) {
+
+	if scope.Tenant != "" && scope.Target != "" {
+		return scope.Tenant + "-" + scope.Target, nil
+	} else if scope.Target != "" {
+		tenantName, err := c.GetTenantName("")
+		if err != nil {
+			return "", err
+		}
+		return tenantName + "-" + scope.Target, nil
+	} else if scope.Tenant != "" {
+		defaultTargetName, err := c.GetTenantDefaultTargetName(scope.Tenant)
 		if err != nil {
 			return "", err
 		}
-		namespaceName = tenantName + "-" + defaultTargetName
+		return scope.Tenant + "-" + defaultTargetName, nil
+	}
+
+	if c.namespace == "" {
+		return "", errors.New("No tenant-target specified and no tenant-target found in your .kubeconfig file.")
 	}
-	return namespaceName, nil
+	return c.namespace, nil
 }
@@ -0,0 +1,222 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clusterOperations
+
+import (
+	"context"
+	"errors"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+	"kufast/tools"
+	"strings"
+	"testing"
+)
+
+func TestCreateTenantTarget(t *testing.T) {
+	client, clientset := newTestClient(t, "",
+		newTestNode("w1", nil),
+		newTestNode("w2", map[string]string{tools.KUFAST_NODE_GROUP_LABEL + "edge": "true"}),
+		newTestTenant("tenant1", ""))
+
+	opts := TenantTargetOptions{Memory: "1Gi", CPU: "500m", Storage: "10Gi", MinStorage: "1Gi", Pods: "2"}
+	awaitResult(t, client.CreateTenantTarget("tenant1", "w1", opts))
+	awaitResult(t, client.CreateTenantTarget("tenant1", "edge", opts))
+
+	namespace, err := client.GetTenantTarget("tenant1", "w1")
+	if err != nil {
+		t.Fatalf("GetTenantTarget: %v", err)
+	}
+	assertGolden(t, "tenanttarget-namespace-node", namespace)
+
+	namespace, err = client.GetTenantTarget("tenant1", "edge")
+	if err != nil {
+		t.Fatalf("GetTenantTarget: %v", err)
+	}
+	assertGolden(t, "tenanttarget-namespace-group", namespace)
+
+	quota, err := client.GetTenantTargetQuota("tenant1", "w1")
+	if err != nil {
+		t.Fatalf("GetTenantTargetQuota: %v", err)
+	}
+	assertGolden(t, "tenanttarget-resourcequota", quota)
+
+	role, err := clientset.RbacV1().Roles("tenant1-w1").Get(context.TODO(), "tenant1-w1-role", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get role: %v", err)
+	}
+	assertGolden(t, "tenanttarget-role", role)
+
+	limitRange, err := clientset.CoreV1().LimitRanges("tenant1-w1").Get(context.TODO(), "tenant1-w1-limitrange", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get limit range: %v", err)
+	}
+	assertGolden(t, "tenanttarget-limitrange", limitRange)
+
+	networkPolicy, err := clientset.NetworkingV1().NetworkPolicies("tenant1-w1").Get(context.TODO(), "tenant1-w1-networkpolicy", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get network policy: %v", err)
+	}
+	assertGolden(t, "tenanttarget-networkpolicy", networkPolicy)
+
+	binding, err := clientset.RbacV1().RoleBindings("tenant1-w1").Get(context.TODO(), "tenant1-w1-tenant1-binding", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get role binding: %v", err)
+	}
+	assertGolden(t, "tenanttarget-rolebinding", binding)
+}
+
+func TestCreateTenantTargetInvalidTarget(t *testing.T) {
+	client, _ := newTestClient(t, "", newTestNode("w1", nil), newTestTenant("tenant1", ""))
+
+	if err := <-client.CreateTenantTarget("tenant1", "w9", TenantTargetOptions{}); tools.GetErrorKind(err) != tools.ERROR_KIND_INVALID_TARGET {
+		t.Errorf("expected an invalid target error for an unknown target, got %v", err)
+	}
+}
+
+func TestCreateTenantTargetGrantsAccess(t *testing.T) {
+	client, _ := newTestClient(t, "", newTestNode("w1", nil), newTestTenant("tenant1", ""))
+	awaitResult(t, client.CreateTenantTarget("tenant1", "w1", TenantTargetOptions{}))
+
+	tenant, err := client.GetTenant("tenant1")
+	if err != nil {
+		t.Fatalf("GetTenant: %v", err)
+	}
+	if tenant.Labels[tools.KUFAST_TENANT_NODEACCESS_LABEL+"w1"] != "true" || tenant.Labels[tools.KUFAST_TENANT_DEFAULT_LABEL] != "w1" {
+		t.Errorf("expected access to w1 as default target, got %v", tenant.Labels)
+	}
+}
+
+func TestCreateTenantTargetRollback(t *testing.T) {
+	client, clientset := newTestClient(t, "", newTestNode("w1", nil), newTestTenant("tenant1", ""))
+	clientset.PrependReactor("create", "networkpolicies", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("admission denied")
+	})
+
+	res := <-client.CreateTenantTarget("tenant1", "w1", TenantTargetOptions{})
+	expected := "Failed to create tenant-target tenant1-w1 at step 'network policy': admission denied\nAll changes have been rolled back."
+	if res == nil || res.Error() != expected {
+		t.Errorf("expected %q, got %v", expected, res)
+	}
+
+	if _, err := client.GetTenantTarget("tenant1", "w1"); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the namespace to be deleted, got %v", err)
+	}
+	tenant, err := client.GetTenant("tenant1")
+	if err != nil {
+		t.Fatalf("GetTenant: %v", err)
+	}
+	if _, ok := tenant.Labels[tools.KUFAST_TENANT_NODEACCESS_LABEL+"w1"]; ok || tenant.Labels[tools.KUFAST_TENANT_DEFAULT_LABEL] != "" {
+		t.Errorf("expected the access label and default target to be rolled back, got %v", tenant.Labels)
+	}
+}
+
+func TestCreateTenantTargetRollbackKeepsExistingNamespace(t *testing.T) {
+	existing := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant1-w1"}}
+	client, _ := newTestClient(t, "", newTestNode("w1", nil), newTestTenant("tenant1", "w1", "w1"), existing)
+
+	res := <-client.CreateTenantTarget("tenant1", "w1", TenantTargetOptions{})
+	if res == nil || !strings.HasPrefix(res.Error(), "Failed to create tenant-target tenant1-w1 at step 'namespace'") {
+		t.Errorf("expected the namespace step to fail, got %v", res)
+	}
+	if tools.GetErrorKind(res) != tools.ERROR_KIND_CONFLICT {
+		t.Errorf("expected the existing namespace to cause a conflict, got kind %v", tools.GetErrorKind(res))
+	}
+	if _, err := client.GetTenantTarget("tenant1", "w1"); err != nil {
+		t.Errorf("expected the existing namespace to be kept, got %v", err)
+	}
+	if !client.IsValidTarget("w1", "tenant1", false) {
+		t.Errorf("expected the existing access of the tenant to be kept")
+	}
+}
+
+func TestCreateTenantTargetUpsert(t *testing.T) {
+	client, clientset := newTestClient(t, "", newTestNode("w1", nil), newTestTenant("tenant1", ""))
+	awaitResult(t, client.CreateTenantTarget("tenant1", "w1", TenantTargetOptions{Memory: "1Gi", CPU: "500m", Storage: "10Gi", Pods: "1"}))
+	_ = clientset.NetworkingV1().NetworkPolicies("tenant1-w1").Delete(context.TODO(), "tenant1-w1-networkpolicy", metav1.DeleteOptions{})
+
+	res := <-client.CreateTenantTarget("tenant1", "w1", TenantTargetOptions{CPU: "2"})
+	if res == nil || !strings.HasPrefix(res.Error(), "Failed to create tenant-target tenant1-w1 at step 'namespace'") {
+		t.Errorf("expected the namespace step to fail without upsert, got %v", res)
+	}
+
+	awaitResult(t, client.CreateTenantTarget("tenant1", "w1", TenantTargetOptions{Memory: "1Gi", CPU: "2", Storage: "10Gi", Pods: "1", Upsert: true}))
+	quota, err := client.GetTenantTargetQuota("tenant1", "w1")
+	if err != nil {
+		t.Fatalf("GetTenantTargetQuota: %v", err)
+	}
+	if qty := quota.Spec.Hard["limits.cpu"]; qty.Cmp(resource.MustParse("2")) != 0 {
+		t.Errorf("expected the cpu limit to be updated to 2, got %s", qty.String())
+	}
+	if _, err := clientset.NetworkingV1().NetworkPolicies("tenant1-w1").Get(context.TODO(), "tenant1-w1-networkpolicy", metav1.GetOptions{}); err != nil {
+		t.Errorf("expected the missing network policy to be recreated, got %v", err)
+	}
+}
+
+func TestUpdateTenantTarget(t *testing.T) {
+	client, _ := newTestClient(t, "", newTestNode("w1", nil), newTestTenant("tenant1", "w1", "w1"))
+	awaitResult(t, client.CreateTenantTarget("tenant1", "w1", TenantTargetOptions{Memory: "1Gi", CPU: "500m", Storage: "10Gi", Pods: "1"}))
+
+	err := client.UpdateTenantTarget("tenant1", "w1", TenantTargetOptions{CPU: "2", Pods: "5"})
+	if err != nil {
+		t.Fatalf("UpdateTenantTarget: %v", err)
+	}
+
+	quota, err := client.GetTenantTargetQuota("tenant1", "w1")
+	if err != nil {
+		t.Fatalf("GetTenantTargetQuota: %v", err)
+	}
+	expected := map[v1.ResourceName]string{"limits.cpu": "2", "requests.cpu": "2", "pods": "5", "limits.memory": "1Gi"}
+	for name, value := range expected {
+		qty := quota.Spec.Hard[name]
+		if qty.Cmp(resource.MustParse(value)) != 0 {
+			t.Errorf("expected %s to be %s, got %s", name, value, qty.String())
+		}
+	}
+}
+
+func TestDeleteTenantTarget(t *testing.T) {
+	client, _ := newTestClient(t, "", newTestNode("w1", nil), newTestTenant("tenant1", "w1", "w1"))
+	awaitResult(t, client.CreateTenantTarget("tenant1", "w1", TenantTargetOptions{}))
+
+	awaitResult(t, client.DeleteTenantTarget("w1", "tenant1"))
+	if err := client.DeleteTargetFromTenant("w1", "tenant1"); err != nil {
+		t.Fatalf("DeleteTargetFromTenant: %v", err)
+	}
+
+	if _, err := client.GetTenantTarget("tenant1", "w1"); err == nil {
+		t.Errorf("expected the tenant-target namespace to be deleted")
+	}
+	namespaces, err := client.ListTenantTargets("tenant1")
+	if err != nil {
+		t.Fatalf("ListTenantTargets: %v", err)
+	}
+	if len(namespaces) != 0 {
+		t.Errorf("expected no tenant-targets, got %d", len(namespaces))
+	}
+}
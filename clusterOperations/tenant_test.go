@@ -0,0 +1,177 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clusterOperations
+
+import (
+	"context"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kufast/tools"
+	"strings"
+	"testing"
+)
+
+func TestCreateTenant(t *testing.T) {
+	client, clientset := newTestClient(t, "")
+
+	err := client.CreateTenant("tenant1", TenantOptions{})
+	if err != nil {
+		t.Fatalf("CreateTenant: %v", err)
+	}
+
+	user, err := clientset.CoreV1().ServiceAccounts("default").Get(context.TODO(), "tenant1-user", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get tenant user: %v", err)
+	}
+	assertGolden(t, "tenant-serviceaccount", user)
+
+	role, err := clientset.RbacV1().Roles("default").Get(context.TODO(), "tenant1-defaultrole", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get tenant role: %v", err)
+	}
+	assertGolden(t, "tenant-role", role)
+
+	binding, err := clientset.RbacV1().RoleBindings("default").Get(context.TODO(), "tenant1-defaultrolebinding", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get tenant role binding: %v", err)
+	}
+	assertGolden(t, "tenant-rolebinding", binding)
+}
+
+func TestCreateTenantUpsert(t *testing.T) {
+	client, clientset := newTestClient(t, "", newTestNode("w1", nil))
+	if err := client.CreateTenant("tenant1", TenantOptions{}); err != nil {
+		t.Fatalf("CreateTenant: %v", err)
+	}
+	if err := client.AddTargetToTenant("w1", "tenant1"); err != nil {
+		t.Fatalf("AddTargetToTenant: %v", err)
+	}
+	role, _ := clientset.RbacV1().Roles("default").Get(context.TODO(), "tenant1-defaultrole", metav1.GetOptions{})
+	role.Rules = nil
+	_, _ = clientset.RbacV1().Roles("default").Update(context.TODO(), role, metav1.UpdateOptions{})
+
+	if err := client.CreateTenant("tenant1", TenantOptions{}); !apierrors.IsAlreadyExists(err) {
+		t.Errorf("expected an AlreadyExists error without upsert, got %v", err)
+	}
+	if err := client.CreateTenant("tenant1", TenantOptions{Upsert: true}); err != nil {
+		t.Fatalf("CreateTenant with upsert: %v", err)
+	}
+
+	if !client.IsValidTarget("w1", "tenant1", false) {
+		t.Errorf("expected the targets of the tenant to be kept")
+	}
+	role, _ = clientset.RbacV1().Roles("default").Get(context.TODO(), "tenant1-defaultrole", metav1.GetOptions{})
+	role.ResourceVersion = ""
+	assertGolden(t, "tenant-role", role)
+}
+
+func TestDeleteTenant(t *testing.T) {
+	client, clientset := newTestClient(t, "")
+	if err := client.CreateTenant("tenant1", TenantOptions{}); err != nil {
+		t.Fatalf("CreateTenant: %v", err)
+	}
+
+	if err := client.DeleteTenant("tenant1"); err != nil {
+		t.Fatalf("DeleteTenant: %v", err)
+	}
+
+	tenants, err := client.ListTenants()
+	if err != nil {
+		t.Fatalf("ListTenants: %v", err)
+	}
+	if len(tenants) != 0 {
+		t.Errorf("expected no tenants, got %d", len(tenants))
+	}
+	roles, _ := clientset.RbacV1().Roles("default").List(context.TODO(), metav1.ListOptions{})
+	if len(roles.Items) != 0 {
+		t.Errorf("expected tenant role to be deleted, got %d roles", len(roles.Items))
+	}
+}
+
+func TestListTenantObjects(t *testing.T) {
+	client, clientset := newTestClient(t, "", newTestNode("w1", nil))
+	if err := client.CreateTenant("tenant1", TenantOptions{}); err != nil {
+		t.Fatalf("CreateTenant: %v", err)
+	}
+	awaitResult(t, client.CreateTenantTarget("tenant1", "w1", TenantTargetOptions{}))
+	_, _ = clientset.CoreV1().Pods("tenant1-w1").Create(context.TODO(), &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx"}}, metav1.CreateOptions{})
+	_, _ = clientset.CoreV1().Secrets("tenant1-w1").Create(context.TODO(), &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "credentials"}}, metav1.CreateOptions{})
+
+	objects, err := client.ListTenantObjects("tenant1")
+	if err != nil {
+		t.Fatalf("ListTenantObjects: %v", err)
+	}
+	expected := []string{"serviceaccount/tenant1-user", "namespace/tenant1-w1", "pod/nginx (tenant1-w1)", "secret/credentials (tenant1-w1)"}
+	if strings.Join(objects, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected %v, got %v", expected, objects)
+	}
+
+	if _, err := client.ListTenantObjects("tenant2"); err == nil {
+		t.Errorf("expected an error for an unknown tenant")
+	}
+}
+
+func TestAddAndDeleteTargetFromTenant(t *testing.T) {
+	client, _ := newTestClient(t, "",
+		newTestNode("w1", nil),
+		newTestNode("w2", map[string]string{tools.KUFAST_NODE_GROUP_LABEL + "edge": "true"}),
+		newTestTenant("tenant1", ""))
+
+	if err := client.AddTargetToTenant("w1", "tenant1"); err != nil {
+		t.Fatalf("AddTargetToTenant w1: %v", err)
+	}
+	if err := client.AddTargetToTenant("edge", "tenant1"); err != nil {
+		t.Fatalf("AddTargetToTenant edge: %v", err)
+	}
+	if err := client.AddTargetToTenant("w3", "tenant1"); err == nil {
+		t.Errorf("expected an error for an unknown target")
+	}
+
+	tenant, err := client.GetTenant("tenant1")
+	if err != nil {
+		t.Fatalf("GetTenant: %v", err)
+	}
+	if tenant.Labels[tools.KUFAST_TENANT_NODEACCESS_LABEL+"w1"] != "true" {
+		t.Errorf("node access label missing: %v", tenant.Labels)
+	}
+	if tenant.Labels[tools.KUFAST_TENANT_GROUPACCESS_LABEL+"edge"] != "true" {
+		t.Errorf("group access label missing: %v", tenant.Labels)
+	}
+	if tenant.Labels[tools.KUFAST_TENANT_DEFAULT_LABEL] != "w1" {
+		t.Errorf("expected default target w1, got %q", tenant.Labels[tools.KUFAST_TENANT_DEFAULT_LABEL])
+	}
+
+	if err := client.DeleteTargetFromTenant("w1", "tenant1"); err != nil {
+		t.Fatalf("DeleteTargetFromTenant: %v", err)
+	}
+	targets, err := client.ListTargets("tenant1", false)
+	if err != nil {
+		t.Fatalf("ListTargets: %v", err)
+	}
+	if len(targets) != 1 || targets[0] != (tools.Target{Name: "edge", AccessType: "group"}) {
+		t.Errorf("unexpected targets after deletion: %v", targets)
+	}
+}
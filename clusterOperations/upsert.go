@@ -0,0 +1,196 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clusterOperations
+
+import (
+	"context"
+	v1 "k8s.io/api/core/v1"
+	n1 "k8s.io/api/networking/v1"
+	v12 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"reflect"
+)
+
+// upsert creates an object. If the object already exists and upsert is true, update is called to bring the existing
+// object to the requested spec. It returns true, if the object has been created.
+func upsert(upsert bool, create func() error, update func() error) (bool, error) {
+	err := create()
+	if apierrors.IsAlreadyExists(err) && upsert {
+		return false, retry.RetryOnConflict(retry.DefaultRetry, update)
+	}
+	return err == nil, err
+}
+
+// mergeMaps returns the existing map with all entries of the requested map added.
+func mergeMaps(existing map[string]string, requested map[string]string) map[string]string {
+	if existing == nil {
+		existing = map[string]string{}
+	}
+	for key, value := range requested {
+		existing[key] = value
+	}
+	return existing
+}
+
+// upsertServiceAccount creates a service account. Existing service accounts only get the requested labels added, as
+// their labels hold the targets of a tenant.
+func (c *Client) upsertServiceAccount(sa *v1.ServiceAccount, isUpsert bool) (bool, error) {
+	client := c.clientset.CoreV1().ServiceAccounts(sa.Namespace)
+	return upsert(isUpsert, func() error {
+		_, err := client.Create(context.TODO(), sa, metav1.CreateOptions{})
+		return err
+	}, func() error {
+		existing, err := client.Get(context.TODO(), sa.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if existing.Labels == nil {
+			existing.Labels = map[string]string{}
+		}
+		for key, value := range sa.Labels {
+			if _, ok := existing.Labels[key]; !ok {
+				existing.Labels[key] = value
+			}
+		}
+		_, err = client.Update(context.TODO(), existing, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// upsertNamespace creates a namespace or adds the requested labels and annotations to an existing one.
+func (c *Client) upsertNamespace(namespace *v1.Namespace, isUpsert bool) (bool, error) {
+	client := c.clientset.CoreV1().Namespaces()
+	return upsert(isUpsert, func() error {
+		_, err := client.Create(context.TODO(), namespace, metav1.CreateOptions{})
+		return err
+	}, func() error {
+		existing, err := client.Get(context.TODO(), namespace.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		existing.Labels = mergeMaps(existing.Labels, namespace.Labels)
+		existing.Annotations = mergeMaps(existing.Annotations, namespace.Annotations)
+		_, err = client.Update(context.TODO(), existing, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// upsertRole creates a role or replaces the rules of an existing one.
+func (c *Client) upsertRole(role *v12.Role, isUpsert bool) (bool, error) {
+	client := c.clientset.RbacV1().Roles(role.Namespace)
+	return upsert(isUpsert, func() error {
+		_, err := client.Create(context.TODO(), role, metav1.CreateOptions{})
+		return err
+	}, func() error {
+		existing, err := client.Get(context.TODO(), role.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		existing.Labels = mergeMaps(existing.Labels, role.Labels)
+		existing.Rules = role.Rules
+		_, err = client.Update(context.TODO(), existing, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// upsertRoleBinding creates a role binding or replaces the subjects of an existing one. As the role of a binding
+// cannot be changed, bindings to another role are recreated.
+func (c *Client) upsertRoleBinding(binding *v12.RoleBinding, isUpsert bool) (bool, error) {
+	client := c.clientset.RbacV1().RoleBindings(binding.Namespace)
+	return upsert(isUpsert, func() error {
+		_, err := client.Create(context.TODO(), binding, metav1.CreateOptions{})
+		return err
+	}, func() error {
+		existing, err := client.Get(context.TODO(), binding.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(existing.RoleRef, binding.RoleRef) {
+			err = client.Delete(context.TODO(), binding.Name, metav1.DeleteOptions{})
+			if err != nil {
+				return err
+			}
+			_, err = client.Create(context.TODO(), binding, metav1.CreateOptions{})
+			return err
+		}
+		existing.Labels = mergeMaps(existing.Labels, binding.Labels)
+		existing.Subjects = binding.Subjects
+		_, err = client.Update(context.TODO(), existing, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// upsertResourceQuota creates a resource quota or replaces the limits of an existing one.
+func (c *Client) upsertResourceQuota(quota *v1.ResourceQuota, isUpsert bool) (bool, error) {
+	client := c.clientset.CoreV1().ResourceQuotas(quota.Namespace)
+	return upsert(isUpsert, func() error {
+		_, err := client.Create(context.TODO(), quota, metav1.CreateOptions{})
+		return err
+	}, func() error {
+		existing, err := client.Get(context.TODO(), quota.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		existing.Spec = quota.Spec
+		_, err = client.Update(context.TODO(), existing, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// upsertLimitRange creates a limit range or replaces the limits of an existing one.
+func (c *Client) upsertLimitRange(limitRange *v1.LimitRange, isUpsert bool) (bool, error) {
+	client := c.clientset.CoreV1().LimitRanges(limitRange.Namespace)
+	return upsert(isUpsert, func() error {
+		_, err := client.Create(context.TODO(), limitRange, metav1.CreateOptions{})
+		return err
+	}, func() error {
+		existing, err := client.Get(context.TODO(), limitRange.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		existing.Spec = limitRange.Spec
+		_, err = client.Update(context.TODO(), existing, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// upsertNetworkPolicy creates a network policy or replaces the rules of an existing one.
+func (c *Client) upsertNetworkPolicy(networkPolicy *n1.NetworkPolicy, isUpsert bool) (bool, error) {
+	client := c.clientset.NetworkingV1().NetworkPolicies(networkPolicy.Namespace)
+	return upsert(isUpsert, func() error {
+		_, err := client.Create(context.TODO(), networkPolicy, metav1.CreateOptions{})
+		return err
+	}, func() error {
+		existing, err := client.Get(context.TODO(), networkPolicy.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		existing.Spec = networkPolicy.Spec
+		_, err = client.Update(context.TODO(), existing, metav1.UpdateOptions{})
+		return err
+	})
+}
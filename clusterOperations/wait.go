@@ -0,0 +1,256 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clusterOperations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+	"k8s.io/utils/strings/slices"
+	"kufast/tools"
+	"sort"
+	"time"
+)
+
+// WaitOptions configures how operations wait for the cluster to reach the requested state.
+type WaitOptions struct {
+	// Wait is false, if operations return as soon as the cluster accepted a request.
+	Wait bool
+	// Timeout limits how long an operation waits for the cluster.
+	Timeout time.Duration
+}
+
+// DefaultWaitOptions are the wait options of a new Client.
+var DefaultWaitOptions = WaitOptions{Wait: true, Timeout: 2 * time.Minute}
+
+// podFailureReasons are the reasons of waiting containers, which will not resolve by waiting longer. A failed image
+// pull (ErrImagePull) or a crashed container is retried by the kubelet, so the pod only fails once the kubelet backs
+// off after the failure repeated.
+var podFailureReasons = []string{"InvalidImageName", "ErrImageNeverPull", "CreateContainerConfigError", "ImagePullBackOff", "CrashLoopBackOff"}
+
+// SetWaitOptions changes how the operations of the client wait for the cluster.
+func (c *Client) SetWaitOptions(opts WaitOptions) {
+	c.wait = opts
+}
+
+// conditionFunc is called for every state of a watched object. obj is nil, once the object does not exist.
+type conditionFunc func(obj runtime.Object) (bool, error)
+
+// listFunc and watchFunc list and watch the objects of a single resource.
+type listFunc func(options metav1.ListOptions) (runtime.Object, error)
+type watchFunc func(options metav1.ListOptions) (watch.Interface, error)
+
+// waitForObject watches the object with the given name until condition returns true or an error. If the timeout of
+// the client expires first, wait.ErrWaitTimeout is returned.
+func (c *Client) waitForObject(namespace string, name string, objType runtime.Object, list listFunc, watchObjects watchFunc, condition conditionFunc) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.wait.Timeout)
+	defer cancel()
+
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", name).String()
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = fieldSelector
+			return list(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = fieldSelector
+			return watchObjects(options)
+		},
+	}
+
+	key := name
+	if namespace != "" {
+		key = namespace + "/" + name
+	}
+
+	//Objects, which do not exist when the watch starts, cause no events
+	precondition := func(store cache.Store) (bool, error) {
+		_, exists, err := store.GetByKey(key)
+		if err != nil || exists {
+			return false, err
+		}
+		return condition(nil)
+	}
+
+	_, err := watchtools.UntilWithSync(ctx, lw, objType, precondition, func(event watch.Event) (bool, error) {
+		object, err := meta.Accessor(event.Object)
+		if err != nil || object.GetName() != name {
+			return false, nil
+		}
+		if event.Type == watch.Deleted {
+			return condition(nil)
+		}
+		return condition(event.Object)
+	})
+	return err
+}
+
+// waitForNamespaceActive waits until a namespace is active. Objects cannot be created in a namespace before.
+func (c *Client) waitForNamespaceActive(namespaceName string) error {
+	if c.IsDryRun() {
+		return nil
+	}
+
+	err := c.waitForObject("", namespaceName, &v1.Namespace{},
+		func(options metav1.ListOptions) (runtime.Object, error) {
+			return c.clientset.CoreV1().Namespaces().List(context.TODO(), options)
+		},
+		func(options metav1.ListOptions) (watch.Interface, error) {
+			return c.clientset.CoreV1().Namespaces().Watch(context.TODO(), options)
+		},
+		func(obj runtime.Object) (bool, error) {
+			if obj == nil {
+				return false, errors.New("The namespace " + namespaceName + " has been deleted.")
+			}
+			return obj.(*v1.Namespace).Status.Phase == v1.NamespaceActive, nil
+		})
+	if err == wait.ErrWaitTimeout {
+		return tools.NewError(tools.ERROR_KIND_TIMEOUT, "Operation timeout after "+c.wait.Timeout.String()+". The namespace "+namespaceName+" is not active yet.")
+	}
+	return err
+}
+
+// waitForPodStarted waits until a pod is running or has completed. If the pod fails or does not start in time, the
+// error contains the reason the pod is stuck.
+func (c *Client) waitForPodStarted(namespaceName string, podName string) error {
+	if !c.wait.Wait {
+		return nil
+	}
+
+	err := c.waitForObject(namespaceName, podName, &v1.Pod{},
+		func(options metav1.ListOptions) (runtime.Object, error) {
+			return c.clientset.CoreV1().Pods(namespaceName).List(context.TODO(), options)
+		},
+		func(options metav1.ListOptions) (watch.Interface, error) {
+			return c.clientset.CoreV1().Pods(namespaceName).Watch(context.TODO(), options)
+		},
+		func(obj runtime.Object) (bool, error) {
+			if obj == nil {
+				return false, errors.New("The pod " + podName + " has been deleted.")
+			}
+			pod := obj.(*v1.Pod)
+			//A crash looping pod is still in the phase running
+			for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+				if waiting := status.State.Waiting; waiting != nil && slices.Contains(podFailureReasons, waiting.Reason) {
+					return false, errors.New("The pod " + podName + " cannot start: container " + status.Name + ": " + formatReason(waiting.Reason, waiting.Message))
+				}
+			}
+			switch pod.Status.Phase {
+			case v1.PodRunning, v1.PodSucceeded:
+				return true, nil
+			case v1.PodFailed:
+				return false, errors.New("The pod " + podName + " failed: " + c.getPodStuckReason(pod))
+			}
+			return false, nil
+		})
+	if err == wait.ErrWaitTimeout {
+		reason := "Maybe your pod doesn't start correctly? Please look after it with 'kufast get pod'"
+		pod, getErr := c.clientset.CoreV1().Pods(namespaceName).Get(context.TODO(), podName, metav1.GetOptions{})
+		if getErr == nil {
+			if stuckReason := c.getPodStuckReason(pod); stuckReason != "" {
+				reason = "The pod is stuck: " + stuckReason
+			}
+		}
+		return tools.NewError(tools.ERROR_KIND_TIMEOUT, "Operation timeout after "+c.wait.Timeout.String()+". "+reason)
+	}
+	return err
+}
+
+// waitForDeletion waits until an object does not exist anymore.
+func (c *Client) waitForDeletion(kind string, namespaceName string, name string, objType runtime.Object, list listFunc, watchObjects watchFunc) error {
+	if !c.wait.Wait {
+		return nil
+	}
+
+	err := c.waitForObject(namespaceName, name, objType, list, watchObjects, func(obj runtime.Object) (bool, error) {
+		return obj == nil, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return tools.NewError(tools.ERROR_KIND_TIMEOUT, "Operation timeout after "+c.wait.Timeout.String()+". The "+kind+" "+name+" still exists. Please look after it with 'kufast get "+kind+"'")
+	}
+	return err
+}
+
+// getPodStuckReason returns the reason why a pod does not run, e.g. a failed image pull, an unschedulable pod or an
+// exceeded quota. It is empty, if no reason is known.
+func (c *Client) getPodStuckReason(pod *v1.Pod) string {
+	for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+		if status.State.Waiting != nil && status.State.Waiting.Reason != "" && status.State.Waiting.Reason != "ContainerCreating" && status.State.Waiting.Reason != "PodInitializing" {
+			return formatReason(status.State.Waiting.Reason, status.State.Waiting.Message)
+		}
+		if status.State.Terminated != nil && status.State.Terminated.ExitCode != 0 {
+			return formatReason(status.State.Terminated.Reason, fmt.Sprintf("container %s exited with code %d", status.Name, status.State.Terminated.ExitCode))
+		}
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodScheduled && condition.Status == v1.ConditionFalse {
+			return formatReason(condition.Reason, condition.Message)
+		}
+	}
+	if pod.Status.Reason != "" || pod.Status.Message != "" {
+		return formatReason(pod.Status.Reason, pod.Status.Message)
+	}
+
+	//Fall back to the latest warning, e.g. about an exceeded quota
+	events, err := c.clientset.CoreV1().Events(pod.Namespace).List(context.TODO(), metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("involvedObject.name", pod.Name).String(),
+	})
+	if err != nil {
+		return ""
+	}
+	var warnings []v1.Event
+	for _, event := range events.Items {
+		if event.Type == v1.EventTypeWarning && event.InvolvedObject.Name == pod.Name {
+			warnings = append(warnings, event)
+		}
+	}
+	if len(warnings) == 0 {
+		return ""
+	}
+	sort.Slice(warnings, func(i, j int) bool {
+		return warnings[i].LastTimestamp.Before(&warnings[j].LastTimestamp)
+	})
+	return formatReason(warnings[len(warnings)-1].Reason, warnings[len(warnings)-1].Message)
+}
+
+// formatReason combines the reason and the message of a Kubernetes status.
+func formatReason(reason string, message string) string {
+	if message == "" {
+		return reason
+	}
+	if reason == "" {
+		return message
+	}
+	return reason + ": " + message
+}
@@ -0,0 +1,117 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clusterOperations
+
+import (
+	"context"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+	"kufast/tools"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newStuckPodClient creates a test client, whose pods never start but get the given status instead.
+func newStuckPodClient(t *testing.T, status v1.PodStatus) *Client {
+	t.Helper()
+
+	client, clientset := newTestClient(t, "tenant1-w1", newTestNode("w1", nil), newTestTenant("tenant1", "w1", "w1"))
+	clientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		pod := action.(k8stesting.CreateAction).GetObject().(*v1.Pod)
+		pod.Status = status
+		return true, pod, clientset.Tracker().Add(pod)
+	})
+	client.SetWaitOptions(WaitOptions{Wait: true, Timeout: 200 * time.Millisecond})
+	return client
+}
+
+func TestCreatePodStuckReason(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   v1.PodStatus
+		expected string
+	}{
+		{"image pull", v1.PodStatus{Phase: v1.PodPending, ContainerStatuses: []v1.ContainerStatus{{
+			Name: "nginx", State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ErrImagePull", Message: "manifest unknown"}},
+		}}}, "Operation timeout after 200ms. The pod is stuck: ErrImagePull: manifest unknown"},
+		{"image pull back-off", v1.PodStatus{Phase: v1.PodPending, ContainerStatuses: []v1.ContainerStatus{{
+			Name: "nginx", State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "Back-off pulling image \"nginx:1.2.3.4\""}},
+		}}}, "The pod nginx cannot start: container nginx: ImagePullBackOff: Back-off pulling image \"nginx:1.2.3.4\""},
+		{"crash loop", v1.PodStatus{Phase: v1.PodRunning, ContainerStatuses: []v1.ContainerStatus{{
+			Name: "nginx", State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff", Message: "back-off 20s restarting failed container"}},
+		}}}, "The pod nginx cannot start: container nginx: CrashLoopBackOff: back-off 20s restarting failed container"},
+		{"init container crash loop", v1.PodStatus{Phase: v1.PodPending, InitContainerStatuses: []v1.ContainerStatus{{
+			Name: "init", State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff", Message: "back-off 10s restarting failed container"}},
+		}}}, "The pod nginx cannot start: container init: CrashLoopBackOff: back-off 10s restarting failed container"},
+		{"unschedulable", v1.PodStatus{Phase: v1.PodPending, Conditions: []v1.PodCondition{{
+			Type: v1.PodScheduled, Status: v1.ConditionFalse, Reason: "Unschedulable", Message: "0/1 nodes are available",
+		}}}, "Operation timeout after 200ms. The pod is stuck: Unschedulable: 0/1 nodes are available"},
+		{"invalid image", v1.PodStatus{Phase: v1.PodPending, ContainerStatuses: []v1.ContainerStatus{{
+			Name: "nginx", State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "InvalidImageName", Message: "couldn't parse image reference"}},
+		}}}, "The pod nginx cannot start: container nginx: InvalidImageName: couldn't parse image reference"},
+		{"failed", v1.PodStatus{Phase: v1.PodFailed, Reason: "Evicted", Message: "The node was low on resource"},
+			"The pod nginx failed: Evicted: The node was low on resource"},
+		{"no reason", v1.PodStatus{Phase: v1.PodPending}, "Operation timeout after 200ms. Maybe your pod doesn't start correctly?"},
+	}
+	for _, test := range tests {
+		client := newStuckPodClient(t, test.status)
+		res := <-client.CreatePod(CreatePodOptions{Name: "nginx", Image: "nginx"})
+		if res == nil || !strings.HasPrefix(res.Error(), test.expected) {
+			t.Errorf("%s: expected %q, got %v", test.name, test.expected, res)
+		}
+		if strings.HasPrefix(test.expected, "Operation timeout") && tools.GetErrorKind(res) != tools.ERROR_KIND_TIMEOUT {
+			t.Errorf("%s: expected a timeout error, got kind %v", test.name, tools.GetErrorKind(res))
+		}
+	}
+}
+
+func TestCreatePodStuckReasonFromEvents(t *testing.T) {
+	client := newStuckPodClient(t, v1.PodStatus{Phase: v1.PodPending})
+	_, err := client.clientset.CoreV1().Events("tenant1-w1").Create(context.TODO(), &v1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "nginx.1", Namespace: "tenant1-w1"},
+		InvolvedObject: v1.ObjectReference{Kind: "Pod", Name: "nginx", Namespace: "tenant1-w1"},
+		Type:           v1.EventTypeWarning,
+		Reason:         "FailedCreate",
+		Message:        "exceeded quota: tenant1-w1-limits",
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("create event: %v", err)
+	}
+
+	res := <-client.CreatePod(CreatePodOptions{Name: "nginx", Image: "nginx"})
+	if res == nil || !strings.HasSuffix(res.Error(), "FailedCreate: exceeded quota: tenant1-w1-limits") {
+		t.Errorf("expected the quota warning as reason, got %v", res)
+	}
+}
+
+func TestCreatePodNoWait(t *testing.T) {
+	client := newStuckPodClient(t, v1.PodStatus{Phase: v1.PodPending})
+	client.SetWaitOptions(WaitOptions{Wait: false, Timeout: time.Hour})
+
+	awaitResult(t, client.CreatePod(CreatePodOptions{Name: "nginx", Image: "nginx"}))
+}
@@ -0,0 +1,153 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+	"kufast/clusterOperations"
+	"kufast/tools"
+	"os"
+)
+
+// applyCmd represents the apply command
+var applyCmd = &cobra.Command{
+	Use:   "apply -f <layout>",
+	Short: "Converge the cluster to a layout file of target-groups, tenants and tenant-targets.",
+	Long: `Converge the cluster to a layout file of target-groups, tenants and tenant-targets. kufast compares the
+layout with the cluster and prints all changes, before they are applied. Objects missing in the layout are only
+deleted, if --prune is set. This operation can only be executed by a cluster admin.
+
+Example layout:
+
+targetGroups:
+  - name: edge
+    nodes: [w1, w2]
+tenants:
+  - name: tenant1
+    defaultTarget: edge
+    targets:
+      - name: edge
+        cpu: 500m
+        memory: 1Gi
+        storage: 10Gi
+        minStorage: 1Gi
+        pods: "2"
+      - name: w3`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		if len(args) != 0 {
+			tools.HandleError(tools.CreateWrongNumberArgumentsError(), cmd)
+		}
+
+		fileName, _ := cmd.Flags().GetString("filename")
+		prune, _ := cmd.Flags().GetBool("prune")
+
+		data, err := os.ReadFile(fileName)
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
+		layout, err := clusterOperations.ParseLayout(data)
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
+
+		s := tools.CreateStandardSpinner(tools.MESSAGE_GET_OBJECTS)
+
+		client, err := clusterOperations.NewClientFromCmd(cmd)
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+
+		plan, err := client.PlanLayout(layout, prune)
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+
+		s.Stop()
+		if plan.IsEmpty() {
+			fmt.Println("The cluster already matches the layout.")
+			return
+		}
+		fmt.Println(plan.String())
+
+		//Ensure user knows what he does
+		confirmed, err := tools.ConfirmOperation(cmd, "The changes above will be applied to the cluster.", nil)
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
+		if !confirmed {
+			return
+		}
+
+		s = tools.CreateStandardSpinner(tools.MESSAGE_UPDATE_OBJECTS)
+		err = client.ApplyLayoutPlan(plan)
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+
+		s.Stop()
+		for _, change := range plan.Changes {
+			if change.Kind == "tenant" && change.Action == "create" {
+				fmt.Println("Get the credentials of the new tenant " + change.Name + " with 'kufast get tenant-creds " + change.Name + "'.")
+			}
+		}
+		fmt.Println(tools.MESSAGE_DONE)
+	},
+}
+
+// init is a helper function from cobra to initialize the command. It sets all flags, standard values and documentation for this command.
+func init() {
+	RootCmd.AddCommand(applyCmd)
+
+	applyCmd.Flags().StringP("filename", "f", "", "The layout file to apply.")
+	_ = applyCmd.MarkFlagRequired("filename")
+	_ = applyCmd.MarkFlagFilename("filename", "yaml", "yml", "json")
+	applyCmd.Flags().BoolP("prune", "", false, "Delete target-groups, tenants and tenant-targets, which are missing in the layout.")
+
+}
+
+func CreateApplyDocs(linkH func(string) string) {
+	out, err := os.Create("./kufast.wiki/apply.md")
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		err := out.Close()
+		if err != nil {
+			panic(err)
+		}
+	}()
+
+	err = doc.GenMarkdownCustom(applyCmd, out, linkH)
+	if err != nil {
+		panic(err)
+	}
+
+}
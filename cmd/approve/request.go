@@ -0,0 +1,103 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package approve
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"kufast/clusterOperations"
+	"kufast/cmd"
+	"kufast/tools"
+	"os"
+)
+
+// approveRequestCmd represents the approve request command
+var approveRequestCmd = &cobra.Command{
+	Use:   "request <id>..",
+	Short: "Approve requests for tenant-targets and create them.",
+	Long: `Approve pending requests for tenant-targets. The requested tenant-targets are created with the requested limits.
+Requests are only approved for the tenant, which filed them, and only if the tenant exists. Please check the limits
+with 'kufast list requests' before approving. Can only be used by admins.`,
+	ValidArgsFunction: cmd.CompleteRequests,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		if len(args) < 1 {
+			tools.HandleError(tools.CreateWrongNumberArgumentsError(), cmd)
+		}
+
+		s := tools.CreateStandardSpinner(tools.MESSAGE_GET_OBJECTS)
+
+		client, err := clusterOperations.NewClientFromCmd(cmd)
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+
+		var objects []string
+		for _, requestID := range args {
+			request, err := client.GetTenantTargetRequest(requestID)
+			if err != nil {
+				s.Stop()
+				tools.HandleError(err, cmd)
+			}
+			view := tools.NewRequestView(request)
+			objects = append(objects, "tenant-target/"+view.Tenant+"-"+view.Target+" ("+view.ViewName()+"): cpu "+
+				view.Limits.CPU+", memory "+view.Limits.Memory+", storage "+view.Limits.Storage+", storage-min "+
+				view.MinStorage+", pods "+view.Limits.Pods)
+		}
+		s.Stop()
+
+		//Ensure user knows what he does
+		confirmed, err := tools.ConfirmOperation(cmd, "The following tenant-targets will be created:", objects)
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
+		if !confirmed {
+			return
+		}
+
+		s = tools.CreateStandardSpinner(tools.MESSAGE_CREATE_OBJECTS)
+
+		var errs []error
+		for _, requestID := range args {
+			err = client.ApproveTenantTargetRequest(requestID)
+			if err != nil {
+				s.Stop()
+				fmt.Fprintln(os.Stderr, err.Error())
+				s.Start()
+				errs = append(errs, err)
+			}
+		}
+
+		s.Stop()
+		tools.ExitWithErrors(errs)
+		fmt.Println(tools.MESSAGE_DONE)
+	},
+}
+
+// init is a helper function from cobra to initialize the command. It sets all flags, standard values and documentation for this command.
+func init() {
+	approveCmd.AddCommand(approveRequestCmd)
+
+}
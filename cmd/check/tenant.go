@@ -0,0 +1,108 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package check
+
+import (
+	"fmt"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"kufast/clusterOperations"
+	"kufast/cmd"
+	"kufast/tools"
+	"os"
+)
+
+// checkTenantCmd represents the check tenant command
+var checkTenantCmd = &cobra.Command{
+	Use:   "tenant <tenant>",
+	Short: "Find objects of a tenant, which are missing or have been modified.",
+	Long: `Find objects of a tenant and its tenant-targets, which are missing or differ from the objects kufast creates.
+This includes quotas, limit ranges, network policies, roles, role bindings and the node selector of tenant-targets.
+Quotas are compared with the limits the tenant-targets have been created or updated with. The command exits with code 1, if
+drift has been found. Use 'kufast repair tenant' to restore the objects. Can only be used by admins.`,
+	ValidArgsFunction: cmd.SingleArg(cmd.CompleteTenants),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		if len(args) != 1 {
+			tools.HandleError(tools.CreateWrongNumberArgumentsError(), cmd)
+		}
+
+		s := tools.CreateStandardSpinner(tools.MESSAGE_GET_OBJECTS)
+
+		client, err := clusterOperations.NewClientFromCmd(cmd)
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+
+		drifts, err := client.CheckTenant(args[0])
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+
+		s.Stop()
+		err = printDrifts(cmd, drifts)
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
+
+		if len(drifts) > 0 {
+			format, _ := cmd.Flags().GetString("output")
+			if format == "" || format == "wide" {
+				fmt.Println("Run 'kufast repair tenant " + args[0] + "' to restore these objects.")
+			}
+			os.Exit(1)
+		}
+	},
+}
+
+// printDrifts prints the drifted objects of a tenant in the output format selected by the user.
+func printDrifts(cmd *cobra.Command, drifts []clusterOperations.Drift) error {
+	var views []tools.View
+	for _, drift := range drifts {
+		views = append(views, drift)
+	}
+
+	return tools.PrintViews(cmd, views, func(wide bool) {
+		if len(drifts) == 0 {
+			fmt.Println("No drift found.")
+			return
+		}
+		t := table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		t.AppendHeader(table.Row{"KIND", "NAMESPACE", "NAME", "PROBLEM"})
+		for _, drift := range drifts {
+			t.AppendRow(table.Row{drift.Kind, drift.Namespace, drift.Name, drift.Problem})
+		}
+		t.AppendSeparator()
+		t.Render()
+	})
+}
+
+// init is a helper function from cobra to initialize the command. It sets all flags, standard values and documentation for this command.
+func init() {
+	checkCmd.AddCommand(checkTenantCmd)
+
+}
@@ -0,0 +1,194 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"kufast/clusterOperations"
+	"kufast/objectFactory"
+	"kufast/tools"
+	"strings"
+)
+
+// completionFunc is the signature of cobra functions completing arguments and flags.
+type completionFunc func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// listFunc lists the names to complete with a client created from the flags of the command.
+type listFunc func(cmd *cobra.Command, client *clusterOperations.Client) ([]string, error)
+
+// CompleteTenants completes the names of all tenants of the cluster.
+var CompleteTenants = newCompletion(func(cmd *cobra.Command, client *clusterOperations.Client) ([]string, error) {
+	tenants, err := client.ListTenants()
+	var names []string
+	for _, tenant := range tenants {
+		names = append(names, tenant.Labels[tools.KUFAST_TENANT_LABEL])
+	}
+	return names, err
+})
+
+// CompleteTargets completes the names of all targets of the cluster.
+var CompleteTargets = newCompletion(func(cmd *cobra.Command, client *clusterOperations.Client) ([]string, error) {
+	return listTargetNames(client, "", true)
+})
+
+// CompleteTenantTargets completes the targets of the tenant selected with --tenant or the tenant of the credentials.
+var CompleteTenantTargets = newCompletion(func(cmd *cobra.Command, client *clusterOperations.Client) ([]string, error) {
+	return listTargetNames(client, scopeFromCmd(cmd).Tenant, false)
+})
+
+// CompleteTargetGroups completes the names of all target-groups of the cluster.
+var CompleteTargetGroups = newCompletion(func(cmd *cobra.Command, client *clusterOperations.Client) ([]string, error) {
+	groups, err := client.ListTargetGroups()
+	var names []string
+	for name := range groups {
+		names = append(names, name)
+	}
+	return names, err
+})
+
+// CompletePods completes the pods of the tenant-target selected with --tenant and --target.
+var CompletePods = newCompletion(func(cmd *cobra.Command, client *clusterOperations.Client) ([]string, error) {
+	scope := scopeFromCmd(cmd)
+	namespaceName, err := client.GetTenantTargetName(scope)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := client.ListTenantPods(tools.GetTenantFromNamespace(namespaceName))
+	var names []string
+	for _, pod := range pods {
+		if pod.Namespace == namespaceName {
+			names = append(names, pod.Name)
+		}
+	}
+	return names, err
+})
+
+// CompleteSecrets completes the secrets of the tenant-target selected with --tenant and --target.
+var CompleteSecrets = newCompletion(func(cmd *cobra.Command, client *clusterOperations.Client) ([]string, error) {
+	scope := scopeFromCmd(cmd)
+	namespaceName, err := client.GetTenantTargetName(scope)
+	if err != nil {
+		return nil, err
+	}
+	secrets, err := client.ListSecrets(tools.GetTenantFromNamespace(namespaceName))
+	var names []string
+	for _, secret := range secrets {
+		if secret.Namespace == namespaceName {
+			names = append(names, secret.Name)
+		}
+	}
+	return names, err
+})
+
+// CompleteRequests completes the ids of all pending requests for tenant-targets.
+var CompleteRequests = newCompletion(func(cmd *cobra.Command, client *clusterOperations.Client) ([]string, error) {
+	requests, err := client.ListTenantTargetRequests("", objectFactory.RequestStatePending)
+	var ids []string
+	for _, request := range requests {
+		ids = append(ids, request.Data["id"])
+	}
+	return ids, err
+})
+
+// SingleArg restricts a completion to the first argument of a command.
+func SingleArg(complete completionFunc) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return complete(cmd, args, toComplete)
+	}
+}
+
+// newCompletion creates a completion, which lists the names from the cluster. Names starting with toComplete are
+// returned, if they are not already part of the arguments.
+func newCompletion(list listFunc) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		//Completions do not run the PersistentPreRun of the root command
+		if err := tools.ApplyProfile(cmd); err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		client, err := clusterOperations.NewClientFromCmd(cmd)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		names, err := list(cmd, client)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		used := map[string]bool{}
+		for _, arg := range args {
+			used[arg] = true
+		}
+		var results []string
+		for _, name := range names {
+			if name != "" && !used[name] && strings.HasPrefix(name, toComplete) {
+				results = append(results, name)
+			}
+		}
+		return results, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// scopeFromCmd reads the tenant and target flags of a command, if it has them.
+func scopeFromCmd(cmd *cobra.Command) clusterOperations.ScopeOptions {
+	var scope clusterOperations.ScopeOptions
+	if flag := cmd.Flags().Lookup("tenant"); flag != nil && flag.Value.Type() == "string" {
+		scope.Tenant = flag.Value.String()
+	}
+	if flag := cmd.Flags().Lookup("target"); flag != nil && flag.Value.Type() == "string" {
+		scope.Target = flag.Value.String()
+	}
+	return scope
+}
+
+// listTargetNames lists the names of the targets of a tenant or of all targets of the cluster.
+func listTargetNames(client *clusterOperations.Client, tenantName string, all bool) ([]string, error) {
+	targets, err := client.ListTargets(tenantName, all)
+	var names []string
+	for _, target := range targets {
+		names = append(names, target.Name)
+	}
+	return names, err
+}
+
+// registerFlagCompletions registers the completion of the --tenant and --target flags of all commands. Flags
+// accepting several targets create new tenant-targets, so they complete all targets of the cluster.
+func registerFlagCompletions(command *cobra.Command) {
+	command.Flags().VisitAll(func(flag *pflag.Flag) {
+		switch {
+		case flag.Name == "tenant":
+			_ = command.RegisterFlagCompletionFunc(flag.Name, CompleteTenants)
+		case flag.Name == "target" && flag.Value.Type() == "string":
+			_ = command.RegisterFlagCompletionFunc(flag.Name, CompleteTenantTargets)
+		case flag.Name == "target":
+			_ = command.RegisterFlagCompletionFunc(flag.Name, CompleteTargets)
+		}
+	})
+	for _, child := range command.Commands() {
+		registerFlagCompletions(child)
+	}
+}
@@ -0,0 +1,70 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package config
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"kufast/tools"
+	"strings"
+)
+
+// configSetCmd represents the config set command
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set a default of a profile.",
+	Long: `Set a default of the current profile or the profile selected with --profile. Use an empty value to remove
+the default. The following keys are available: ` + strings.Join(tools.ProfileKeys(), ", ") + `.
+Example: kufast config set pod.memory 256Mi`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		if len(args) != 2 {
+			tools.HandleError(tools.CreateWrongNumberArgumentsError(), cmd)
+		}
+
+		profileName, _ := cmd.Flags().GetString("profile")
+
+		config, err := tools.LoadConfig()
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
+
+		err = config.Set(profileName, args[0], args[1])
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
+
+		err = config.Save()
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
+		fmt.Println("Set " + args[0] + " of profile " + config.ProfileName(profileName) + ".")
+	},
+}
+
+// init is a helper function from cobra to initialize the command. It sets all flags, standard values and documentation for this command.
+func init() {
+	configCmd.AddCommand(configSetCmd)
+
+}
@@ -0,0 +1,67 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package config
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"kufast/tools"
+)
+
+// configUseProfileCmd represents the config use-profile command
+var configUseProfileCmd = &cobra.Command{
+	Use:   "use-profile <profile>",
+	Short: "Select the profile used by all commands.",
+	Long: `Select the profile used by all commands. Profiles, which do not exist yet, are created empty.
+Use --profile to select another profile for a single command.`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		if len(args) != 1 {
+			tools.HandleError(tools.CreateWrongNumberArgumentsError(), cmd)
+		}
+
+		config, err := tools.LoadConfig()
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
+
+		if config.Profiles[args[0]] == nil {
+			config.Profiles[args[0]] = &tools.Profile{}
+			fmt.Println("Created profile " + args[0] + ".")
+		}
+		config.CurrentProfile = args[0]
+
+		err = config.Save()
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
+		fmt.Println("Switched to profile " + args[0] + ".")
+	},
+}
+
+// init is a helper function from cobra to initialize the command. It sets all flags, standard values and documentation for this command.
+func init() {
+	configCmd.AddCommand(configUseProfileCmd)
+
+}
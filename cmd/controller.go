@@ -0,0 +1,111 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+	"kufast/clusterOperations"
+	"kufast/tools"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// controllerCmd represents the controller command
+var controllerCmd = &cobra.Command{
+	Use:   "controller",
+	Short: "Run the kufast controller, which reconciles Tenant, TenantTarget and TargetGroup custom resources.",
+	Long: `Run the kufast controller, which reconciles Tenant, TenantTarget and TargetGroup custom resources. For every
+custom resource the controller creates the same objects as the kufast CLI and restores them, if they are modified.
+Limits of tenant-targets changed by hand are reverted to the spec on every resync; the reverted changes are logged
+and reported in the status message of the TenantTarget. Deleting a custom resource deletes its objects; deleting a
+Tenant deletes its tenant-targets first. Use --install-crds to install the custom resources first.
+Create custom resources with 'kubectl apply' or with the --use-crds flag of the create and delete commands.
+The controller runs until it is interrupted. This operation can only be executed by a cluster admin.`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		if len(args) != 0 {
+			tools.HandleError(tools.CreateWrongNumberArgumentsError(), cmd)
+		}
+
+		installCRDs, _ := cmd.Flags().GetBool("install-crds")
+		workers, _ := cmd.Flags().GetInt("workers")
+		resync, _ := cmd.Flags().GetDuration("resync")
+
+		client, err := clusterOperations.NewClientFromCmd(cmd)
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
+
+		if installCRDs {
+			err = client.InstallCustomResourceDefinitions()
+			if err != nil {
+				tools.HandleError(err, cmd)
+			}
+			fmt.Println("Installed the kufast custom resources.")
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		fmt.Println("Reconciling kufast custom resources. Press Ctrl+C to stop.")
+		err = clusterOperations.NewController(client, resync).Run(ctx, workers)
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
+	},
+}
+
+// init is a helper function from cobra to initialize the command. It sets all flags, standard values and documentation for this command.
+func init() {
+	RootCmd.AddCommand(controllerCmd)
+
+	controllerCmd.Flags().BoolP("install-crds", "", false, "Install or update the kufast custom resource definitions before starting.")
+	controllerCmd.Flags().IntP("workers", "", 2, "The number of custom resources reconciled concurrently.")
+	controllerCmd.Flags().DurationP("resync", "", 10*time.Minute, "The period after which all custom resources are reconciled again.")
+}
+
+func CreateControllerDocs(linkH func(string) string) {
+	out, err := os.Create("./kufast.wiki/controller.md")
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		err := out.Close()
+		if err != nil {
+			panic(err)
+		}
+	}()
+
+	err = doc.GenMarkdownCustom(controllerCmd, out, linkH)
+	if err != nil {
+		panic(err)
+	}
+
+}
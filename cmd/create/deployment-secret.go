@@ -24,11 +24,11 @@ SOFTWARE.
 package create
 
 import (
-	"errors"
 	"fmt"
 	"github.com/spf13/cobra"
 	"kufast/clusterOperations"
 	"kufast/tools"
+	"os"
 )
 
 // createDeploySecretCmd represents the create deploy-secret command
@@ -47,12 +47,27 @@ from dockerconfig files. A prerequisite to this command is, to store this file o
 		}
 
 		if len(args) != 1 {
-			tools.HandleError(errors.New(tools.ERROR_WRONG_NUMBER_ARGUMENTS), cmd)
+			tools.HandleError(tools.CreateWrongNumberArgumentsError(), cmd)
+		}
+
+		fileName, _ := cmd.Flags().GetString("input")
+		tenant, _ := cmd.Flags().GetString("tenant")
+		target, _ := cmd.Flags().GetString("target")
+
+		creds, err := os.ReadFile(fileName)
+		if err != nil {
+			tools.HandleError(err, cmd)
 		}
 
 		s := tools.CreateStandardSpinner(tools.MESSAGE_CREATE_OBJECTS)
 
-		err := clusterOperations.CreateDeploymentSecret(args[0], cmd)
+		client, err := clusterOperations.NewClientFromCmd(cmd)
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+
+		err = client.CreateDeploymentSecret(args[0], creds, clusterOperations.ScopeOptions{Tenant: tenant, Target: target})
 		if err != nil {
 			s.Stop()
 			tools.HandleError(err, cmd)
@@ -24,7 +24,6 @@ SOFTWARE.
 package create
 
 import (
-	"errors"
 	"fmt"
 	"github.com/spf13/cobra"
 	"kufast/clusterOperations"
@@ -44,15 +43,32 @@ You can customize your deployment with the flags below or by using the interacti
 			args = createPodInteractive(cmd)
 		}
 		if len(args) != 2 {
-			tools.HandleError(errors.New(tools.ERROR_WRONG_NUMBER_ARGUMENTS), cmd)
+			tools.HandleError(tools.CreateWrongNumberArgumentsError(), cmd)
 		}
+		opts := clusterOperations.CreatePodOptions{Name: args[0], Image: args[1]}
+		opts.Tenant, _ = cmd.Flags().GetString("tenant")
+		opts.Target, _ = cmd.Flags().GetString("target")
+		opts.Memory, _ = cmd.Flags().GetString("memory")
+		opts.CPU, _ = cmd.Flags().GetString("cpu")
+		opts.Storage, _ = cmd.Flags().GetString("storage")
+		opts.KeepAlive, _ = cmd.Flags().GetBool("keep-alive")
+		opts.Secrets, _ = cmd.Flags().GetStringArray("secrets")
+		opts.DeploySecret, _ = cmd.Flags().GetString("deploy-secret")
+		opts.Ports, _ = cmd.Flags().GetInt32Slice("port")
+		opts.Command, _ = cmd.Flags().GetStringArray("cmd")
+
 		s := tools.CreateStandardSpinner(tools.MESSAGE_CREATE_OBJECTS)
 
-		res := clusterOperations.CreatePod(cmd, args)
-		err := <-res
+		client, err := clusterOperations.NewClientFromCmd(cmd)
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+
+		err = <-client.CreatePod(opts)
 		s.Stop()
-		if err != "" {
-			tools.HandleError(errors.New(err), cmd)
+		if err != nil {
+			tools.HandleError(err, cmd)
 		}
 		fmt.Println(tools.MESSAGE_DONE)
 
@@ -90,7 +106,7 @@ func createPodInteractive(cmd *cobra.Command) []string {
 
 // init is a helper function from cobra to initialize the command. It sets all flags, standard values and documentation for this command.
 func init() {
-	createCmds.AddCommand(createPodCmds)
+	createCmd.AddCommand(createPodCmds)
 
 	//Settings for the pod
 	createPodCmds.Flags().BoolP("keep-alive", "", false, "Pod will be restarted upon termination.")
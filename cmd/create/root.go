@@ -27,6 +27,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/cobra/doc"
 	"kufast/cmd"
+	"kufast/tools"
 	"log"
 	"os"
 )
@@ -43,6 +44,9 @@ Use these features to create tenants, pods and more.`,
 func init() {
 	cmd.RootCmd.AddCommand(createCmd)
 
+	//Enables dry runs for all commands in create.
+	tools.AddDryRunFlags(createCmd)
+
 	//Enables interactive mode for all commands in create.
 	createCmd.PersistentFlags().BoolP("interactive", "i", false, "Start interactive mode for the creation of this object.")
 
@@ -24,7 +24,6 @@ SOFTWARE.
 package create
 
 import (
-	"errors"
 	"fmt"
 	"github.com/spf13/cobra"
 	"kufast/clusterOperations"
@@ -45,15 +44,24 @@ on the cluster.`,
 		}
 
 		if len(args) != 1 {
-			tools.HandleError(errors.New(tools.ERROR_WRONG_NUMBER_ARGUMENTS), cmd)
+			tools.HandleError(tools.CreateWrongNumberArgumentsError(), cmd)
 		}
 
 		//Get the secret
 		secretData := tools.GetPasswordAnswer("Enter your secret here:")
 
+		tenant, _ := cmd.Flags().GetString("tenant")
+		target, _ := cmd.Flags().GetString("target")
+
 		s := tools.CreateStandardSpinner(tools.MESSAGE_CREATE_OBJECTS)
 
-		err := clusterOperations.CreateSecret(args[0], secretData, cmd)
+		client, err := clusterOperations.NewClientFromCmd(cmd)
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+
+		err = client.CreateSecret(args[0], secretData, clusterOperations.ScopeOptions{Tenant: tenant, Target: target})
 		if err != nil {
 			s.Stop()
 			tools.HandleError(err, cmd)
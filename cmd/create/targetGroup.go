@@ -24,7 +24,6 @@ SOFTWARE.
 package create
 
 import (
-	"errors"
 	"fmt"
 	"github.com/spf13/cobra"
 	"kufast/clusterOperations"
@@ -45,13 +44,30 @@ instead of a single node.`,
 		}
 
 		if len(args) < 2 {
-			tools.HandleError(errors.New(tools.ERROR_WRONG_NUMBER_ARGUMENTS), cmd)
+			tools.HandleError(tools.CreateWrongNumberArgumentsError(), cmd)
 		}
 
 		s := tools.CreateStandardSpinner(tools.MESSAGE_CREATE_OBJECTS)
 
-		err := clusterOperations.SetTargetGroupToNodes(args[0], args[1:], cmd)
+		client, err := clusterOperations.NewClientFromCmd(cmd)
 		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+
+		if client.IsValidTarget(args[0], "", true) {
+			s.Stop()
+			tools.HandleError(tools.NewError(tools.ERROR_KIND_CONFLICT, "The target "+args[0]+" already exists. Use 'kufast update target-group' to change its nodes."), cmd)
+		}
+
+		useCRDs, _ := cmd.Flags().GetBool("use-crds")
+		if useCRDs {
+			err = client.SetTargetGroupResource(args[0], args[1:])
+		} else {
+			err = client.SetTargetGroupToNodes(args[0], args[1:])
+		}
+		if err != nil {
+			s.Stop()
 			tools.HandleError(err, cmd)
 		}
 
@@ -80,4 +96,6 @@ func createTargetGroupInteractive() []string {
 func init() {
 	createCmd.AddCommand(createTargetGroupCmd)
 
+	createTargetGroupCmd.Flags().BoolP("use-crds", "", false, "Create a TargetGroup custom resource, which is reconciled by the kufast controller.")
+
 }
@@ -24,11 +24,12 @@ SOFTWARE.
 package create
 
 import (
-	"errors"
 	"fmt"
+	"github.com/briandowns/spinner"
 	"github.com/spf13/cobra"
 	"kufast/clusterOperations"
 	"kufast/tools"
+	"time"
 )
 
 // createTenantCmd represents the create tenant command
@@ -45,12 +46,26 @@ partner that is supposed to work with your cluster.
 			args = createTenantInteractive()
 		}
 		if len(args) < 1 {
-			tools.HandleError(errors.New(tools.ERROR_WRONG_NUMBER_ARGUMENTS), cmd)
+			tools.HandleError(tools.CreateWrongNumberArgumentsError(), cmd)
 		}
 
+		//Read targets and limits from Cobra
+		targets, _ := cmd.Flags().GetStringArray("target")
+		outputDir, _ := cmd.Flags().GetString("output-dir")
+		duration, _ := cmd.Flags().GetDuration("duration")
+		opts := tenantTargetOptionsFromCmd(cmd)
+		useCRDs, _ := cmd.Flags().GetBool("use-crds")
+
 		//Activate spinner
 		s := tools.CreateStandardSpinner(tools.MESSAGE_CREATE_OBJECTS)
 
+		clients, err := clusterOperations.NewClientsFromCmd(cmd)
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+
+		var results []error
 		for _, tenantName := range args {
 			if !tools.IsAlphaNumeric(tenantName) {
 				s.Stop()
@@ -59,62 +74,114 @@ partner that is supposed to work with your cluster.
 				continue
 			}
 
-			err := clusterOperations.CreateTenant(tenantName, cmd)
-			if err != nil {
-				tools.HandleError(err, cmd)
+			for _, client := range clients {
+				if useCRDs {
+					results = append(results, createTenantResources(cmd, client, tenantName, targets, opts, s)...)
+				} else {
+					results = append(results, createTenantObjects(cmd, client, tenantName, targets, opts, s)...)
+				}
 			}
 
-			//Read targets from Cobra
-			targets, _ := cmd.Flags().GetStringArray("target")
-
-			var createTargetOps []<-chan string
-			var targetResults []string
-
-			if targets != nil {
-				for _, targetName := range targets {
-					if !tools.IsAlphaNumeric(targetName) {
-						s.Stop()
-						fmt.Println(tools.CreateAlphaNumericError(targetName))
-						s.Start()
-						continue
-					}
-
-					err = clusterOperations.AddTargetToTenant(cmd, targetName, tenantName)
-					if err != nil {
-						s.Stop()
-						fmt.Println(err)
-						s.Start()
-						continue
-					}
-					createTargetOps = append(createTargetOps, clusterOperations.CreateTenantTarget(tenantName, targetName, cmd))
-
-				}
-				//Ensure all operations are done
-				for _, op := range createTargetOps {
-					targetResults = append(targetResults, <-op)
-				}
+			//No credentials can be issued for a tenant, which has not been persisted
+			if clients[0].IsDryRun() {
+				continue
+			}
 
-				for _, res := range targetResults {
-					if res != "" {
-						s.Stop()
-						fmt.Println(res)
-						s.Start()
-					}
-				}
+			//The credentials contain one context per cluster
+			config, err := clusterOperations.GetTenantKubeconfigForClusters(clients, tenantName, clusterOperations.CredentialOptions{Duration: duration})
+			if err != nil {
+				s.Stop()
+				tools.HandleError(err, cmd)
 			}
 
-			err = tools.WriteNewUserYamlToFile(tenantName, cmd, s)
+			err = tools.WriteNewUserYamlToFile(tenantName, config, outputDir, s)
 			if err != nil {
+				s.Stop()
 				tools.HandleError(err, cmd)
 			}
 
 		}
 
 		s.Stop()
+		tools.ExitWithErrors(results)
 		fmt.Println(tools.MESSAGE_DONE)
 	},
 }
 
+// createTenantObjects is a helper function to create a tenant and its tenant-targets directly. The errors of
+// tenant-targets, which could not be created, are printed and returned.
+func createTenantObjects(cmd *cobra.Command, client *clusterOperations.Client, tenantName string, targets []string, opts clusterOperations.TenantTargetOptions, s *spinner.Spinner) []error {
+	err := client.CreateTenant(tenantName, clusterOperations.TenantOptions{Upsert: opts.Upsert})
+	if err != nil {
+		s.Stop()
+		tools.HandleError(err, cmd)
+	}
+
+	var createTargetOps []<-chan error
+	var targetResults []error
+
+	if targets != nil {
+		for _, targetName := range targets {
+			if !tools.IsAlphaNumeric(targetName) {
+				s.Stop()
+				fmt.Println(tools.CreateAlphaNumericError(targetName))
+				s.Start()
+				continue
+			}
+			createTargetOps = append(createTargetOps, client.CreateTenantTarget(tenantName, targetName, opts))
+
+		}
+		//Ensure all operations are done
+		for _, op := range createTargetOps {
+			targetResults = append(targetResults, <-op)
+		}
+
+		for _, res := range targetResults {
+			if res != nil {
+				s.Stop()
+				fmt.Println(res)
+				s.Start()
+			}
+		}
+	}
+	return targetResults
+}
+
+// createTenantResources is a helper function to create the custom resources of a tenant and its tenant-targets. It
+// waits for the kufast controller to create the tenant, as its credentials are written afterwards. The errors of
+// tenant-targets, which could not be created, are printed and returned.
+func createTenantResources(cmd *cobra.Command, client *clusterOperations.Client, tenantName string, targets []string, opts clusterOperations.TenantTargetOptions, s *spinner.Spinner) []error {
+	err := client.CreateTenantResource(tenantName, clusterOperations.TenantOptions{Upsert: opts.Upsert})
+	if err != nil {
+		s.Stop()
+		tools.HandleError(err, cmd)
+	}
+
+	var errs []error
+	for _, targetName := range targets {
+		if !tools.IsAlphaNumeric(targetName) {
+			s.Stop()
+			fmt.Println(tools.CreateAlphaNumericError(targetName))
+			s.Start()
+			continue
+		}
+		err = client.CreateTenantTargetResource(tenantName, targetName, opts)
+		if err != nil {
+			s.Stop()
+			fmt.Println(err)
+			s.Start()
+			errs = append(errs, err)
+		}
+	}
+
+	err = client.WaitForTenant(tenantName)
+	if err != nil {
+		s.Stop()
+		tools.HandleError(err, cmd)
+	}
+	return errs
+}
+
 // createTenantInteractive is a helper function to create a tenant interactively
 func createTenantInteractive() []string {
 	fmt.Println(tools.MESSAGE_INTERACTIVE_IGNORE_INPUT)
@@ -141,10 +208,13 @@ func init() {
 	createTenantCmd.Flags().StringP("pods", "", "1", "Limit the Number of pods that can be created for the tenant-target(s)")
 
 	createTenantCmd.Flags().StringArrayP("target", "", nil, "Deployment target for the tenant. Can be specified multiple times.")
+	createTenantCmd.Flags().BoolP("upsert", "", false, "Update existing tenants and tenant-targets to the requested spec instead of failing.")
+	createTenantCmd.Flags().BoolP("use-crds", "", false, "Create Tenant and TenantTarget custom resources, which are reconciled by the kufast controller.")
 
 	//Allow User definition
-	createTenantCmd.Flags().StringP("output", "o", ".", "Folder to store the created client credentials.")
-	_ = createTenantCmd.MarkFlagDirname("output")
-	_ = createTenantCmd.MarkFlagRequired("output")
+	createTenantCmd.Flags().StringP("output-dir", "d", ".", "Folder to store the created client credentials.")
+	_ = createTenantCmd.MarkFlagDirname("output-dir")
+	_ = createTenantCmd.MarkFlagRequired("output-dir")
+	createTenantCmd.Flags().DurationP("duration", "", 8760*time.Hour, "Lifetime of the tenant credentials. Use 0 to issue long-lived credentials, which are valid until they are revoked.")
 
 }
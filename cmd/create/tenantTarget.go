@@ -24,10 +24,10 @@ SOFTWARE.
 package create
 
 import (
-	"errors"
 	"fmt"
 	"github.com/spf13/cobra"
 	"kufast/clusterOperations"
+	"kufast/cmd"
 	"kufast/tools"
 )
 
@@ -39,6 +39,7 @@ var createTenantTargetCmd = &cobra.Command{
 Tenant-targets will be attached to tenants and give them the ability to deploy pods to the target
 until the specified resource limit is reached. Write multiple targets to create multiple tenant-targets at once. 
 `,
+	ValidArgsFunction: cmd.CompleteTargets,
 	Run: func(cmd *cobra.Command, args []string) {
 
 		isInteractive, _ := cmd.Flags().GetBool("interactive")
@@ -47,19 +48,28 @@ until the specified resource limit is reached. Write multiple targets to create
 		}
 
 		if len(args) < 1 {
-			tools.HandleError(errors.New(tools.ERROR_WRONG_NUMBER_ARGUMENTS), cmd)
+			tools.HandleError(tools.CreateWrongNumberArgumentsError(), cmd)
 		}
 
+		tenantName, err := cmd.Flags().GetString("tenant")
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
+		opts := tenantTargetOptionsFromCmd(cmd)
+		useCRDs, _ := cmd.Flags().GetBool("use-crds")
+
 		//Activate spinner
 		s := tools.CreateStandardSpinner(tools.MESSAGE_CREATE_OBJECTS)
 
-		tenantName, err := cmd.Flags().GetString("tenant")
+		clients, err := clusterOperations.NewClientsFromCmd(cmd)
 		if err != nil {
+			s.Stop()
 			tools.HandleError(err, cmd)
 		}
 
-		var createTargetOps []<-chan string
-		var targetResults []string
+		var createTargetOps []<-chan error
+		var opClusters []string
+		var targetResults []error
 
 		for _, targetName := range args {
 			if !tools.IsAlphaNumeric(targetName) {
@@ -68,25 +78,31 @@ until the specified resource limit is reached. Write multiple targets to create
 				s.Start()
 				continue
 			}
-
-			err = clusterOperations.AddTargetToTenant(cmd, targetName, tenantName)
-			if err != nil {
-				s.Stop()
-				fmt.Println(err)
-				s.Start()
-				continue
+			for _, client := range clients {
+				if useCRDs {
+					err = client.CreateTenantTargetResource(tenantName, targetName, opts)
+					if err != nil {
+						targetResults = append(targetResults, err)
+					}
+					continue
+				}
+				createTargetOps = append(createTargetOps, client.CreateTenantTarget(tenantName, targetName, opts))
+				opClusters = append(opClusters, client.Cluster())
 			}
-			createTargetOps = append(createTargetOps, clusterOperations.CreateTenantTarget(tenantName, targetName, cmd))
 
 		}
 
 		//Ensure all operations are done
-		for _, op := range createTargetOps {
-			targetResults = append(targetResults, <-op)
+		for i, op := range createTargetOps {
+			if res := <-op; res != nil && opClusters[i] != "" {
+				targetResults = append(targetResults, tools.WithMessage(res, "Cluster "+opClusters[i]+": "+res.Error()))
+			} else {
+				targetResults = append(targetResults, res)
+			}
 		}
 
 		for _, res := range targetResults {
-			if res != "" {
+			if res != nil {
 				s.Stop()
 				fmt.Println(res)
 				s.Start()
@@ -94,6 +110,7 @@ until the specified resource limit is reached. Write multiple targets to create
 		}
 
 		s.Stop()
+		tools.ExitWithErrors(targetResults)
 		fmt.Println(tools.MESSAGE_DONE)
 
 	},
@@ -126,6 +143,18 @@ func createTenantTargetInteractive(cmd *cobra.Command) []string {
 	return args
 }
 
+// tenantTargetOptionsFromCmd is a helper function to read the limits of a tenant-target from the command line
+func tenantTargetOptionsFromCmd(cmd *cobra.Command) clusterOperations.TenantTargetOptions {
+	var opts clusterOperations.TenantTargetOptions
+	opts.Memory, _ = cmd.Flags().GetString("memory")
+	opts.CPU, _ = cmd.Flags().GetString("cpu")
+	opts.Storage, _ = cmd.Flags().GetString("storage")
+	opts.MinStorage, _ = cmd.Flags().GetString("storage-min")
+	opts.Pods, _ = cmd.Flags().GetString("pods")
+	opts.Upsert, _ = cmd.Flags().GetBool("upsert")
+	return opts
+}
+
 // init is a helper function from cobra to initialize the command. It sets all flags, standard values and documentation for this command.
 func init() {
 	createCmd.AddCommand(createTenantTargetCmd)
@@ -139,6 +168,8 @@ func init() {
 
 	//Tenant for the operation must be always specified
 	createTenantTargetCmd.Flags().StringP("tenant", "t", "", "The tenant for the tenant-target(s).")
+	createTenantTargetCmd.Flags().BoolP("upsert", "", false, "Update existing tenant-targets to the requested limits instead of failing.")
+	createTenantTargetCmd.Flags().BoolP("use-crds", "", false, "Create TenantTarget custom resources, which are reconciled by the kufast controller.")
 	_ = createTenantTargetCmd.MarkFlagRequired("tenant")
 
 }
@@ -24,57 +24,84 @@ SOFTWARE.
 package delete
 
 import (
-	"errors"
 	"fmt"
 	"github.com/spf13/cobra"
 	"kufast/clusterOperations"
+	"kufast/cmd"
 	"kufast/tools"
 )
 
 // deletePodCmd represents the delete pod command
 var deletePodCmd = &cobra.Command{
-	Use:   "pod <pods>..",
-	Short: "Delete the selected pod.",
-	Long:  `Delete the selected pod including its storage. Please use with care! Deleted data cannot be restored.`,
+	Use:               "pod <pods>..",
+	Short:             "Delete the selected pod.",
+	Long:              `Delete the selected pod including its storage. Please use with care! Deleted data cannot be restored.`,
+	ValidArgsFunction: cmd.CompletePods,
 	Run: func(cmd *cobra.Command, args []string) {
 
 		//Check that exactly one arg has been provided (the namespace)
 		if len(args) != 1 {
-			tools.HandleError(errors.New(tools.ERROR_WRONG_NUMBER_ARGUMENTS), cmd)
+			tools.HandleError(tools.CreateWrongNumberArgumentsError(), cmd)
 		}
 
-		//Ensure user knows what he does
-		answer := tools.GetDialogAnswer("Pod " + args[0] + " will be deleted together with its storage and logs! Continue? (No/yes)")
-		if answer == "yes" {
+		tenant, _ := cmd.Flags().GetString("tenant")
+		target, _ := cmd.Flags().GetString("target")
+		scope := clusterOperations.ScopeOptions{Tenant: tenant, Target: target}
 
-			s := tools.CreateStandardSpinner(tools.MESSAGE_DELETE_OBJECTS)
+		s := tools.CreateStandardSpinner(tools.MESSAGE_GET_OBJECTS)
 
-			var deleteTargetOps []<-chan string
-			var targetResults []string
+		client, err := clusterOperations.NewClientFromCmd(cmd)
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
 
-			for _, podName := range args {
-				deleteTargetOps = append(deleteTargetOps, clusterOperations.DeletePod(cmd, podName))
+		namespaceName, err := client.GetTenantTargetName(scope)
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+		s.Stop()
 
-			}
+		var objects []string
+		for _, podName := range args {
+			objects = append(objects, "pod/"+podName+" ("+namespaceName+")")
+		}
 
-			//Ensure all operations are done
-			for _, op := range deleteTargetOps {
-				targetResults = append(targetResults, <-op)
-			}
+		//Ensure user knows what he does
+		confirmed, err := tools.ConfirmOperation(cmd, "The following objects will be deleted:", objects)
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
+		if !confirmed {
+			return
+		}
 
-			for _, res := range targetResults {
-				if res != "" {
-					s.Stop()
-					fmt.Println(res)
-					s.Start()
-				}
-			}
+		s = tools.CreateStandardSpinner(tools.MESSAGE_DELETE_OBJECTS)
 
-			s.Stop()
-			fmt.Println(tools.MESSAGE_DONE)
+		var deleteTargetOps []<-chan error
+		var targetResults []error
 
+		for _, podName := range args {
+			deleteTargetOps = append(deleteTargetOps, client.DeletePod(podName, scope))
+		}
+
+		//Ensure all operations are done
+		for _, op := range deleteTargetOps {
+			targetResults = append(targetResults, <-op)
+		}
+
+		for _, res := range targetResults {
+			if res != nil {
+				s.Stop()
+				fmt.Println(res)
+				s.Start()
+			}
 		}
 
+		s.Stop()
+		tools.ExitWithErrors(targetResults)
+		fmt.Println(tools.MESSAGE_DONE)
 	},
 }
 
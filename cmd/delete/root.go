@@ -26,6 +26,7 @@ package delete
 import (
 	"github.com/spf13/cobra/doc"
 	"kufast/cmd"
+	"kufast/tools"
 	"log"
 	"os"
 
@@ -44,6 +45,9 @@ Use these features to delete tenants, pods and more.`,
 func init() {
 	cmd.RootCmd.AddCommand(deleteCmd)
 
+	//Enables dry runs for all commands in delete.
+	tools.AddDryRunFlags(deleteCmd)
+
 }
 
 func CreateDeleteDocs(fileP func(string) string, linkH func(string) string) {
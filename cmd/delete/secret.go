@@ -24,10 +24,10 @@ SOFTWARE.
 package delete
 
 import (
-	"errors"
 	"fmt"
 	"github.com/spf13/cobra"
 	"kufast/clusterOperations"
+	"kufast/cmd"
 	"kufast/tools"
 )
 
@@ -37,41 +37,71 @@ var deleteSecretCmd = &cobra.Command{
 	Short: "Deletes a secret from a tenant-target.",
 	Long: `Deletes a secret from a tenant-target.
 Please use with care! Deleted data cannot be restored. Can be used for normal secrets and deploy-secrets.`,
+	ValidArgsFunction: cmd.CompleteSecrets,
 	Run: func(cmd *cobra.Command, args []string) {
 		//Check that exactly one arg has been provided (the namespace)
 		if len(args) < 1 {
-			tools.HandleError(errors.New(tools.ERROR_WRONG_NUMBER_ARGUMENTS), cmd)
+			tools.HandleError(tools.CreateWrongNumberArgumentsError(), cmd)
 		}
 
-		//Ensure user knows what he does
-		answer := tools.GetDialogAnswer("Secrets will be deleted! Continue? (No/yes)")
-		if answer == "yes" {
+		tenant, _ := cmd.Flags().GetString("tenant")
+		target, _ := cmd.Flags().GetString("target")
+		scope := clusterOperations.ScopeOptions{Tenant: tenant, Target: target}
 
-			s := tools.CreateStandardSpinner(tools.MESSAGE_DELETE_OBJECTS)
+		s := tools.CreateStandardSpinner(tools.MESSAGE_GET_OBJECTS)
 
-			var deleteOps []<-chan string
-			var results []string
+		client, err := clusterOperations.NewClientFromCmd(cmd)
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
 
-			for _, secret := range args {
-				deleteOps = append(deleteOps, clusterOperations.DeleteSecret(secret, cmd))
-			}
+		namespaceName, err := client.GetTenantTargetName(scope)
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+		s.Stop()
 
-			for _, op := range deleteOps {
-				results = append(results, <-op)
-			}
+		var objects []string
+		for _, secret := range args {
+			objects = append(objects, "secret/"+secret+" ("+namespaceName+")")
+		}
 
-			for _, res := range results {
-				if res != "" {
-					s.Stop()
-					fmt.Println(res)
-					s.Start()
-				}
-			}
+		//Ensure user knows what he does
+		confirmed, err := tools.ConfirmOperation(cmd, "The following objects will be deleted:", objects)
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
+		if !confirmed {
+			return
+		}
 
-			s.Stop()
-			fmt.Println(tools.MESSAGE_DONE)
+		s = tools.CreateStandardSpinner(tools.MESSAGE_DELETE_OBJECTS)
+
+		var deleteOps []<-chan error
+		var results []error
+
+		for _, secret := range args {
+			deleteOps = append(deleteOps, client.DeleteSecret(secret, scope))
+		}
 
+		//Ensure all operations are done
+		for _, op := range deleteOps {
+			results = append(results, <-op)
 		}
+
+		for _, res := range results {
+			if res != nil {
+				s.Stop()
+				fmt.Println(res)
+				s.Start()
+			}
+		}
+
+		s.Stop()
+		tools.ExitWithErrors(results)
+		fmt.Println(tools.MESSAGE_DONE)
 	},
 }
 
@@ -24,11 +24,12 @@ SOFTWARE.
 package delete
 
 import (
-	"errors"
 	"fmt"
 	"github.com/spf13/cobra"
 	"kufast/clusterOperations"
+	"kufast/cmd"
 	"kufast/tools"
+	"strings"
 )
 
 // deleteTargetGroupCmd represents the delete target-group command
@@ -37,30 +38,59 @@ var deleteTargetGroupCmd = &cobra.Command{
 	Short: "Deletes a target-group from the cluster.",
 	Long: `Deletes a target-group from the cluster. This operation can only be executed by a cluster admin.
 Please use with care! Tenant-targets pointing to these target-groups remain intact, but cannot deploy new pods.`,
+	ValidArgsFunction: cmd.CompleteTargetGroups,
 	Run: func(cmd *cobra.Command, args []string) {
 		//Check that exactly one arg has been provided (the namespace)
 		if len(args) < 1 {
-			tools.HandleError(errors.New("Too few arguments provided."), cmd)
+			tools.HandleError(tools.CreateWrongNumberArgumentsError(), cmd)
 		}
 
-		//Ensure user knows what he does
-		answer := tools.GetDialogAnswer("Targetgroup will be deleted! Spaces with that target group remain intact but are unable to deploy! Continue (yes/No)")
-		if answer == "yes" {
-
-			s := tools.CreateStandardSpinner(tools.MESSAGE_DELETE_OBJECTS)
+		s := tools.CreateStandardSpinner(tools.MESSAGE_GET_OBJECTS)
 
-			for _, group := range args {
-				err := clusterOperations.DeleteTargetGroupFromNodes(group, cmd)
-				if err != nil {
-					s.Stop()
-					tools.HandleError(err, cmd)
-				}
-			}
+		client, err := clusterOperations.NewClientFromCmd(cmd)
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
 
+		groups, err := client.ListTargetGroups()
+		if err != nil {
 			s.Stop()
-			fmt.Println(tools.MESSAGE_DONE)
+			tools.HandleError(err, cmd)
+		}
+		s.Stop()
+
+		var objects []string
+		for _, group := range args {
+			objects = append(objects, "target-group/"+group+" (nodes: "+strings.Join(groups[group], ", ")+")")
+		}
 
+		//Ensure user knows what he does
+		confirmed, err := tools.ConfirmOperation(cmd, "The following objects will be deleted. Tenant-targets using them remain intact, but cannot deploy new pods:", objects)
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
+		if !confirmed {
+			return
+		}
+
+		s = tools.CreateStandardSpinner(tools.MESSAGE_DELETE_OBJECTS)
+
+		useCRDs, _ := cmd.Flags().GetBool("use-crds")
+		for _, group := range args {
+			if useCRDs {
+				err = client.DeleteTargetGroupResource(group)
+			} else {
+				err = client.DeleteTargetGroupFromNodes(group)
+			}
+			if err != nil {
+				s.Stop()
+				tools.HandleError(err, cmd)
+			}
 		}
+
+		s.Stop()
+		fmt.Println(tools.MESSAGE_DONE)
 	},
 }
 
@@ -70,5 +100,6 @@ func init() {
 
 	deleteTargetGroupCmd.Flags().StringP("target", "", "", tools.DOCU_FLAG_TARGET)
 	deleteTargetGroupCmd.Flags().StringP("tenant", "", "", tools.DOCU_FLAG_TENANT)
+	deleteTargetGroupCmd.Flags().BoolP("use-crds", "", false, "Delete the TargetGroup custom resources, which are reconciled by the kufast controller.")
 
 }
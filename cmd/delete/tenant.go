@@ -24,10 +24,10 @@ SOFTWARE.
 package delete
 
 import (
-	"errors"
 	"fmt"
 	"github.com/spf13/cobra"
 	"kufast/clusterOperations"
+	"kufast/cmd"
 	"kufast/tools"
 )
 
@@ -37,61 +37,99 @@ var deleteTenantCmd = &cobra.Command{
 	Short: "Delete tenants, their tenant-targets, pods, secrets and their credentials.",
 	Long: `Delete tenants, their tenant-targets and their credentials. This operation can only be executed by a cluster admin.
 Please use with care! Deleted data cannot be restored.`,
+	ValidArgsFunction: cmd.CompleteTenants,
 	Run: func(cmd *cobra.Command, args []string) {
 		//Check that at least one tenant has been provided
 		if len(args) < 1 {
-			tools.HandleError(errors.New(tools.ERROR_WRONG_NUMBER_ARGUMENTS), cmd)
+			tools.HandleError(tools.CreateWrongNumberArgumentsError(), cmd)
 		}
 
+		s := tools.CreateStandardSpinner(tools.MESSAGE_GET_OBJECTS)
+
+		client, err := clusterOperations.NewClientFromCmd(cmd)
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+
+		var objects []string
+		for _, tenantName := range args {
+			tenantObjects, err := client.ListTenantObjects(tenantName)
+			if err != nil {
+				s.Stop()
+				tools.HandleError(err, cmd)
+			}
+			objects = append(objects, tenantObjects...)
+		}
+		s.Stop()
+
 		//Ensure user knows what he does
-		answer := tools.GetDialogAnswer("Tenant will be deleted along with all deployment-targets, continue(yes/No)?")
-		if answer == "yes" {
+		confirmed, err := tools.ConfirmOperation(cmd, "The following objects will be deleted:", objects)
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
+		if !confirmed {
+			return
+		}
 
-			s := tools.CreateStandardSpinner(tools.MESSAGE_DELETE_OBJECTS)
+		s = tools.CreateStandardSpinner(tools.MESSAGE_DELETE_OBJECTS)
 
-			for _, tenantName := range args {
+		var errs []error
+		useCRDs, _ := cmd.Flags().GetBool("use-crds")
+		for _, tenantName := range args {
 
-				tenantTargets, err := clusterOperations.ListTargetsFromString(cmd, tenantName, false)
+			if useCRDs {
+				err = client.DeleteTenantResource(tenantName)
 				if err != nil {
+					s.Stop()
 					tools.HandleError(err, cmd)
 				}
+				continue
+			}
 
-				var deleteTargetOps []<-chan string
-				var targetResults []string
+			tenantTargets, err := client.ListTargets(tenantName, false)
+			if err != nil {
+				s.Stop()
+				tools.HandleError(err, cmd)
+			}
 
-				for _, tenantTarget := range tenantTargets {
+			var deleteTargetOps []<-chan error
+			var targetResults []error
 
-					deleteTargetOps = append(deleteTargetOps, clusterOperations.DeleteTenantTarget(tenantTarget.Name, tenantName, cmd))
-				}
+			for _, tenantTarget := range tenantTargets {
 
-				//Ensure all operations are done
-				for _, op := range deleteTargetOps {
-					targetResults = append(targetResults, <-op)
-				}
+				deleteTargetOps = append(deleteTargetOps, client.DeleteTenantTarget(tenantTarget.Name, tenantName))
+			}
 
-				errorInDeletion := false
-				for _, res := range targetResults {
-					if res != "" {
-						s.Stop()
-						fmt.Println(res)
-						s.Start()
-						errorInDeletion = true
-					}
-				}
-				if errorInDeletion {
-					continue
-				}
+			//Ensure all operations are done
+			for _, op := range deleteTargetOps {
+				targetResults = append(targetResults, <-op)
+			}
 
-				err = clusterOperations.DeleteTenant(tenantName, cmd)
-				if err != nil {
-					tools.HandleError(err, cmd)
+			errorInDeletion := false
+			for _, res := range targetResults {
+				if res != nil {
+					s.Stop()
+					fmt.Println(res)
+					s.Start()
+					errs = append(errs, res)
+					errorInDeletion = true
 				}
 			}
+			if errorInDeletion {
+				continue
+			}
 
-			s.Stop()
-			fmt.Println(tools.MESSAGE_DONE)
-
+			err = client.DeleteTenant(tenantName)
+			if err != nil {
+				s.Stop()
+				tools.HandleError(err, cmd)
+			}
 		}
+
+		s.Stop()
+		tools.ExitWithErrors(errs)
+		fmt.Println(tools.MESSAGE_DONE)
 	},
 }
 
@@ -99,4 +137,6 @@ Please use with care! Deleted data cannot be restored.`,
 func init() {
 	deleteCmd.AddCommand(deleteTenantCmd)
 
+	deleteTenantCmd.Flags().BoolP("use-crds", "", false, "Delete the Tenant and TenantTarget custom resources, which are reconciled by the kufast controller.")
+
 }
@@ -0,0 +1,86 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package delete
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"kufast/clusterOperations"
+	"kufast/cmd"
+	"kufast/tools"
+)
+
+// deleteTenantCredsCmd represents the delete tenant-creds command
+var deleteTenantCredsCmd = &cobra.Command{
+	Use:   "tenant-creds <tenant>..",
+	Short: "Revoke all credentials of tenants.",
+	Long: `Revoke all credentials of tenants, without deleting the tenants or their tenant-targets. New credentials can
+be issued with 'kufast get tenant-creds'. This operation can only be executed by a cluster admin.`,
+	ValidArgsFunction: cmd.CompleteTenants,
+	Run: func(cmd *cobra.Command, args []string) {
+		//Check that at least one tenant has been provided
+		if len(args) < 1 {
+			tools.HandleError(tools.CreateWrongNumberArgumentsError(), cmd)
+		}
+
+		var objects []string
+		for _, tenantName := range args {
+			objects = append(objects, "tenant/"+tenantName)
+		}
+
+		//Ensure user knows what he does
+		confirmed, err := tools.ConfirmOperation(cmd, "All credentials of the following tenants will stop working:", objects)
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
+		if !confirmed {
+			return
+		}
+
+		s := tools.CreateStandardSpinner(tools.MESSAGE_DELETE_OBJECTS)
+
+		client, err := clusterOperations.NewClientFromCmd(cmd)
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+
+		for _, tenantName := range args {
+			err = client.RevokeTenantCredentials(tenantName)
+			if err != nil {
+				s.Stop()
+				tools.HandleError(err, cmd)
+			}
+		}
+
+		s.Stop()
+		fmt.Println(tools.MESSAGE_DONE)
+	},
+}
+
+// init is a helper function from cobra to initialize the command. It sets all flags, standard values and documentation for this command.
+func init() {
+	deleteCmd.AddCommand(deleteTenantCredsCmd)
+
+}
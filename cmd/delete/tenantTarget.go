@@ -24,16 +24,11 @@ SOFTWARE.
 package delete
 
 import (
-	"context"
-	"errors"
 	"fmt"
-	"github.com/briandowns/spinner"
 	"github.com/spf13/cobra"
-	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"kufast/clusterOperations"
+	"kufast/cmd"
 	"kufast/tools"
-	"os"
-	"time"
 )
 
 // deleteTenantTargetCmd represents the delete tenant-target command
@@ -42,64 +37,91 @@ var deleteTenantTargetCmd = &cobra.Command{
 	Short: "Delete tenant-targets of a tenant including all pods and secrets in it.",
 	Long: `Delete tenant-targets of a tenant including all pods and secrets in it. This operation can only be executed by a cluster admin.
 Please use with care! Deleted data cannot be restored.`,
+	ValidArgsFunction: cmd.SingleArg(cmd.CompleteTenantTargets),
 	Run: func(cmd *cobra.Command, args []string) {
 
-		//Ensure user knows what he does
-		answer := tools.GetDialogAnswer("Namespaces will be deleted together with all users and pods! Continue? (No/yes)")
-		if answer == "yes" {
+		//Check that at least one arg has been provided (the target)
+		if len(args) < 1 {
+			tools.HandleError(tools.CreateWrongNumberArgumentsError(), cmd)
+		}
 
-			//Configblock
-			clientset, _, err := tools.GetUserClient(cmd)
-			if err != nil {
-				fmt.Println(err.Error())
-			}
+		tenantName, err := cmd.Flags().GetString("tenant")
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
 
-			//Check that exactly one arg has been provided (the namespace)
-			if len(args) != 1 {
-				tools.HandleError(errors.New(tools.ERROR_WRONG_NUMBER_ARGUMENTS), cmd)
-			}
+		//Activate spinner
+		s := tools.CreateStandardSpinner(tools.MESSAGE_GET_OBJECTS)
 
-			tenantName, err := cmd.Flags().GetString("tenant")
+		client, err := clusterOperations.NewClientFromCmd(cmd)
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+
+		var objects []string
+		for _, tenantTargetName := range args {
+			tenantTargetObjects, err := client.ListTenantTargetObjects(tenantName, tenantTargetName)
 			if err != nil {
+				s.Stop()
 				tools.HandleError(err, cmd)
 			}
+			objects = append(objects, tenantTargetObjects...)
+		}
+		s.Stop()
 
-			//Activate spinner
-			s := spinner.New(spinner.CharSets[9], 100*time.Millisecond, spinner.WithWriter(os.Stderr))
-			s.Prefix = tools.MESSAGE_DELETE_OBJECTS
-			s.Start()
+		//Ensure user knows what he does
+		confirmed, err := tools.ConfirmOperation(cmd, "The following objects will be deleted:", objects)
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
+		if !confirmed {
+			return
+		}
 
-			var results []int
+		s = tools.CreateStandardSpinner(tools.MESSAGE_DELETE_OBJECTS)
 
+		useCRDs, _ := cmd.Flags().GetBool("use-crds")
+		if useCRDs {
 			for _, tenantTargetName := range args {
-
-				err = clientset.CoreV1().Namespaces().Delete(context.TODO(), tenantName+"-"+tenantTargetName, v1.DeleteOptions{})
+				err = client.DeleteTenantTargetResource(tenantName, tenantTargetName)
 				if err != nil {
 					s.Stop()
-					fmt.Println(err)
-					s.Start()
-					results = append(results, 1)
-				} else {
-					results = append(results, 0)
-				}
-			}
-
-			//Remove capability from user
-			for i, res := range results {
-				if res == 0 {
-					err := clusterOperations.DeleteTargetFromTenant(args[i], tenantName, cmd)
-					if err != nil {
-						s.Stop()
-						tools.HandleError(err, cmd)
-					}
+					tools.HandleError(err, cmd)
 				}
 			}
 
 			s.Stop()
 			fmt.Println(tools.MESSAGE_DONE)
+			return
+		}
 
+		var errs []error
+		var deleteOps []<-chan error
+		for _, tenantTargetName := range args {
+			deleteOps = append(deleteOps, client.DeleteTenantTarget(tenantTargetName, tenantName))
+		}
+
+		//Remove capability from user
+		for i, op := range deleteOps {
+			res := <-op
+			if res != nil {
+				s.Stop()
+				fmt.Println(res)
+				s.Start()
+				errs = append(errs, res)
+				continue
+			}
+			err := client.DeleteTargetFromTenant(args[i], tenantName)
+			if err != nil {
+				s.Stop()
+				tools.HandleError(err, cmd)
+			}
 		}
 
+		s.Stop()
+		tools.ExitWithErrors(errs)
+		fmt.Println(tools.MESSAGE_DONE)
 	},
 }
 
@@ -109,5 +131,6 @@ func init() {
 
 	deleteTenantTargetCmd.Flags().StringP("tenant", "t", "", "The tenant owning this tenant-target.")
 	_ = deleteTenantTargetCmd.MarkFlagRequired("tenant")
+	deleteTenantTargetCmd.Flags().BoolP("use-crds", "", false, "Delete the TenantTarget custom resources, which are reconciled by the kufast controller.")
 
 }
@@ -0,0 +1,77 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package deny
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"kufast/clusterOperations"
+	"kufast/cmd"
+	"kufast/tools"
+)
+
+// denyRequestCmd represents the deny request command
+var denyRequestCmd = &cobra.Command{
+	Use:   "request <id>..",
+	Short: "Deny requests for tenant-targets.",
+	Long: `Deny pending requests for tenant-targets. The requests are kept, so tenants can see the reason with
+'kufast list requests --all --wide'. Can only be used by admins.`,
+	ValidArgsFunction: cmd.CompleteRequests,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		if len(args) < 1 {
+			tools.HandleError(tools.CreateWrongNumberArgumentsError(), cmd)
+		}
+
+		reason, _ := cmd.Flags().GetString("reason")
+
+		s := tools.CreateStandardSpinner(tools.MESSAGE_UPDATE_OBJECTS)
+
+		client, err := clusterOperations.NewClientFromCmd(cmd)
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+
+		for _, requestID := range args {
+			err = client.DenyTenantTargetRequest(requestID, reason)
+			if err != nil {
+				s.Stop()
+				fmt.Println(err.Error())
+				s.Start()
+			}
+		}
+
+		s.Stop()
+		fmt.Println(tools.MESSAGE_DONE)
+	},
+}
+
+// init is a helper function from cobra to initialize the command. It sets all flags, standard values and documentation for this command.
+func init() {
+	denyCmd.AddCommand(denyRequestCmd)
+
+	denyRequestCmd.Flags().StringP("reason", "", "", "The reason for denying the request(s), which is shown to the tenant.")
+
+}
@@ -24,12 +24,8 @@ SOFTWARE.
 package cmd
 
 import (
-	"errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/cobra/doc"
-	v1 "k8s.io/api/core/v1"
-	"k8s.io/client-go/kubernetes/scheme"
-	"k8s.io/client-go/tools/remotecommand"
 	"kufast/clusterOperations"
 	"kufast/tools"
 	"os"
@@ -43,57 +39,26 @@ var execCmd = &cobra.Command{
 container (provided one exists) and execute commands in the context of your container. This
 will start an interactive CLI Session. To leave the container and get back to your normal
 command line type "exit".`,
+	ValidArgsFunction: SingleArg(CompletePods),
 	Run: func(cmd *cobra.Command, args []string) {
 
-		//Populate and set the command to be executed
-		command, _ := cmd.Flags().GetString("command")
-
-		//Populate namespace field
-		namespaceName, err := clusterOperations.GetTenantTargetNameFromCmd(cmd)
-		if err != nil {
-			tools.HandleError(err, cmd)
-		}
-
-		clientset, config, err := tools.GetUserClient(cmd)
-		if err != nil {
-			tools.HandleError(err, cmd)
-		}
-
 		//Check that exactly one arg has been provided (the pod)
 		if len(args) != 1 {
-			tools.HandleError(errors.New(tools.ERROR_WRONG_NUMBER_ARGUMENTS), cmd)
-		}
-
-		//Set the command
-		comm := []string{
-			"sh",
-			"-c",
-			command,
+			tools.HandleError(tools.CreateWrongNumberArgumentsError(), cmd)
 		}
 
-		req := clientset.CoreV1().RESTClient().Post().Resource("pods").Name(args[0]).
-			Namespace(namespaceName).SubResource("exec")
-		option := &v1.PodExecOptions{
-			Command: comm,
-			Stdin:   true,
-			Stdout:  true,
-			Stderr:  true,
-			TTY:     true,
-		}
-		req.VersionedParams(
-			option,
-			scheme.ParameterCodec,
-		)
+		//Populate and set the command to be executed
+		command, _ := cmd.Flags().GetString("command")
+		tenant, _ := cmd.Flags().GetString("tenant")
+		target, _ := cmd.Flags().GetString("target")
 
-		exec, err := remotecommand.NewSPDYExecutor(config, "POST", req.URL())
+		client, err := clusterOperations.NewClientFromCmd(cmd)
 		if err != nil {
 			tools.HandleError(err, cmd)
 		}
-		err = exec.Stream(remotecommand.StreamOptions{
-			Stdin:  os.Stdin,
-			Stdout: os.Stdout,
-			Stderr: os.Stderr,
-		})
+
+		err = client.ExecInPod(args[0], command, clusterOperations.ScopeOptions{Tenant: tenant, Target: target},
+			os.Stdin, os.Stdout, os.Stderr)
 		if err != nil {
 			tools.HandleError(err, cmd)
 		}
@@ -24,64 +24,46 @@ SOFTWARE.
 package get
 
 import (
-	"context"
-	"fmt"
 	"github.com/spf13/cobra"
 	"io"
-	v1 "k8s.io/api/core/v1"
 	"kufast/clusterOperations"
+	"kufast/cmd"
 	"kufast/tools"
+	"os"
 )
 
 // getLogsCmd represents the get logs command
 var getLogsCmd = &cobra.Command{
-	Use:   "logs <podname>",
-	Short: "Get the logs of a pod",
-	Long:  `Get the logs of a pod.`,
+	Use:               "logs <podname>",
+	Short:             "Get the logs of a pod",
+	Long:              `Get the logs of a pod.`,
+	ValidArgsFunction: cmd.SingleArg(cmd.CompletePods),
 	Run: func(cmd *cobra.Command, args []string) {
-		//Initial config block
-		namespaceName, err := clusterOperations.GetTenantTargetNameFromCmd(cmd)
-		if err != nil {
-			tools.HandleError(err, cmd)
-		}
 
-		clientset, _, err := tools.GetUserClient(cmd)
-		if err != nil {
-			tools.HandleError(err, cmd)
-		}
-		count := int64(100)
-		options := v1.PodLogOptions{
-			Follow:    true,
-			TailLines: &count,
+		if len(args) != 1 {
+			tools.HandleError(tools.CreateWrongNumberArgumentsError(), cmd)
 		}
 
-		//execute request
-		log := clientset.CoreV1().Pods(namespaceName).GetLogs(args[0], &options)
+		tenant, _ := cmd.Flags().GetString("tenant")
+		target, _ := cmd.Flags().GetString("target")
+
+		//Initial config block
+		client, err := clusterOperations.NewClientFromCmd(cmd)
 		if err != nil {
 			tools.HandleError(err, cmd)
 		}
 
-		podLogs, err := log.Stream(context.TODO())
+		//execute request
+		podLogs, err := client.GetPodLogs(args[0], clusterOperations.ScopeOptions{Tenant: tenant, Target: target}, 100, true)
 		if err != nil {
 			tools.HandleError(err, cmd)
 		}
 
 		defer podLogs.Close()
 
-		for {
-			buf := make([]byte, 2000)
-			numBytes, err := podLogs.Read(buf)
-			if numBytes == 0 {
-				continue
-			}
-			if err == io.EOF {
-				break
-			}
-			if err != nil {
-				tools.HandleError(err, cmd)
-			}
-			message := string(buf[:numBytes])
-			fmt.Print(message)
+		_, err = io.Copy(os.Stdout, podLogs)
+		if err != nil {
+			tools.HandleError(err, cmd)
 		}
 
 	},
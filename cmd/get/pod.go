@@ -24,11 +24,11 @@ SOFTWARE.
 package get
 
 import (
-	"errors"
 	"fmt"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
 	"kufast/clusterOperations"
+	"kufast/cmd"
 	"kufast/tools"
 	"os"
 )
@@ -39,62 +39,79 @@ var getPodCmd = &cobra.Command{
 	Short: "Gain information about a deployed pod.",
 	Long: `Gain information about a deployed pod. Output includes name, tenant-target, status, node, limits, image,
 restart policy and IP-address`,
+	ValidArgsFunction: cmd.SingleArg(cmd.CompletePods),
 	Run: func(cmd *cobra.Command, args []string) {
 
 		if len(args) != 1 {
-			tools.HandleError(errors.New(tools.ERROR_WRONG_NUMBER_ARGUMENTS), cmd)
+			tools.HandleError(tools.CreateWrongNumberArgumentsError(), cmd)
 		}
 
+		tenant, _ := cmd.Flags().GetString("tenant")
+		target, _ := cmd.Flags().GetString("target")
+		scope := clusterOperations.ScopeOptions{Tenant: tenant, Target: target}
+
 		s := tools.CreateStandardSpinner(tools.MESSAGE_GET_OBJECTS)
 
-		pod, err := clusterOperations.GetPod(args[0], cmd)
+		client, err := clusterOperations.NewClientFromCmd(cmd)
 		if err != nil {
+			s.Stop()
 			tools.HandleError(err, cmd)
 		}
 
-		podEvents, err := clusterOperations.GetPodEvents(args[0], cmd)
+		pod, err := client.GetPod(args[0], scope)
 		if err != nil {
+			s.Stop()
 			tools.HandleError(err, cmd)
 		}
 
-		cpuLim, _ := pod.Spec.Containers[0].Resources.Limits["cpu"].MarshalJSON()
-		cpuReq, _ := pod.Spec.Containers[0].Resources.Requests["cpu"].MarshalJSON()
-		memLim, _ := pod.Spec.Containers[0].Resources.Limits["memory"].MarshalJSON()
-		memReq, _ := pod.Spec.Containers[0].Resources.Requests["memory"].MarshalJSON()
-		storageLim, _ := pod.Spec.Containers[0].Resources.Limits["ephemeral-storage"].MarshalJSON()
-		storageReq, _ := pod.Spec.Containers[0].Resources.Requests["ephemeral-storage"].MarshalJSON()
-
-		t := table.NewWriter()
-		t.SetOutputMirror(os.Stdout)
-		t.AppendHeader(table.Row{"ATTRIBUTE", "VALUE"})
-		t.AppendRow(table.Row{"Name", pod.Name})
-		t.AppendRow(table.Row{"Tenant-Target", pod.Namespace})
-		t.AppendRow(table.Row{"Status", pod.Status.Phase})
-		t.AppendRow(table.Row{"Deployed on", pod.Spec.NodeName})
-		t.AppendSeparator()
-		t.AppendRow(table.Row{"CPU-Limit", "Limit: " + string(cpuLim) +
-			"\nRequests: " + string(cpuReq)})
-		t.AppendSeparator()
-		t.AppendRow(table.Row{"Memory-Limit", "Limit: " + string(memLim) +
-			"\nRequests: " + string(memReq)})
-		t.AppendSeparator()
-		t.AppendRow(table.Row{"Storage-Limit", "Limit: " + string(storageLim) +
-			"\nRequests: " + string(storageReq)})
-		t.AppendSeparator()
-		t.AppendRow(table.Row{"Attached Storage"})
-		t.AppendRow(table.Row{"Deployed Image", pod.Spec.Containers[0].Image})
-		t.AppendRow(table.Row{"Restart Policy", pod.Spec.RestartPolicy})
-		t.AppendRow(table.Row{"IP Address", pod.Status.PodIP})
-
-		t.AppendSeparator()
-		s.Stop()
-		t.Render()
+		podEvents, err := client.GetPodEvents(args[0], scope)
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
 
-		fmt.Println("\n" + "Event Messages:")
-		for _, podEvent := range podEvents {
-			fmt.Println("\n" + podEvent.CreationTimestamp.String() + ":")
-			fmt.Println(podEvent.Message)
-			fmt.Println("Reason " + podEvent.Reason)
+		view := tools.NewPodView(pod, podEvents)
+
+		s.Stop()
+		err = tools.PrintView(cmd, view, func(wide bool) {
+			t := table.NewWriter()
+			t.SetOutputMirror(os.Stdout)
+			t.AppendHeader(table.Row{"ATTRIBUTE", "VALUE"})
+			t.AppendRow(table.Row{"Name", view.Name})
+			t.AppendRow(table.Row{"Tenant-Target", view.TenantTarget})
+			t.AppendRow(table.Row{"Status", view.Status})
+			t.AppendRow(table.Row{"Deployed on", view.Node})
+			t.AppendSeparator()
+			t.AppendRow(table.Row{"CPU-Limit", "Limit: " + view.Limits.CPU +
+				"\nRequests: " + view.Requests.CPU})
+			t.AppendSeparator()
+			t.AppendRow(table.Row{"Memory-Limit", "Limit: " + view.Limits.Memory +
+				"\nRequests: " + view.Requests.Memory})
+			t.AppendSeparator()
+			t.AppendRow(table.Row{"Storage-Limit", "Limit: " + view.Limits.Storage +
+				"\nRequests: " + view.Requests.Storage})
+			t.AppendSeparator()
+			t.AppendRow(table.Row{"Attached Storage"})
+			t.AppendRow(table.Row{"Deployed Image", view.Image})
+			t.AppendRow(table.Row{"Restart Policy", view.RestartPolicy})
+			t.AppendRow(table.Row{"IP Address", view.IP})
+			if wide {
+				t.AppendRow(table.Row{"Restarts", view.Restarts})
+				t.AppendRow(table.Row{"Created At", view.CreatedAt})
+			}
+
+			t.AppendSeparator()
+			t.Render()
+
+			fmt.Println("\n" + "Event Messages:")
+			for _, podEvent := range view.Events {
+				fmt.Println("\n" + podEvent.Time.String() + ":")
+				fmt.Println(podEvent.Message)
+				fmt.Println("Reason " + podEvent.Reason)
+			}
+		})
+		if err != nil {
+			tools.HandleError(err, cmd)
 		}
 
 	},
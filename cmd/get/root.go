@@ -26,6 +26,7 @@ package get
 import (
 	"github.com/spf13/cobra/doc"
 	"kufast/cmd"
+	"kufast/tools"
 	"log"
 	"os"
 
@@ -44,6 +45,9 @@ Use these features to get tenants, pods and more.`,
 func init() {
 	cmd.RootCmd.AddCommand(getCmd)
 
+	//Enables machine-readable output for all commands in get.
+	tools.AddOutputFlag(getCmd)
+
 }
 
 func CreateGetDocs(fileP func(string) string, linkH func(string) string) {
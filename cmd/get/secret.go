@@ -28,25 +28,37 @@ import (
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
 	"kufast/clusterOperations"
+	"kufast/cmd"
 	"kufast/tools"
 	"os"
 )
 
 // getSecretCmd represents the get secret command
 var getSecretCmd = &cobra.Command{
-	Use:   "secret <secret>",
-	Short: "Gain information about a secret.",
-	Long:  `Gain information about a secret. Output includes name, tenant-target and the secret data.`,
+	Use:               "secret <secret>",
+	Short:             "Gain information about a secret.",
+	Long:              `Gain information about a secret. Output includes name, tenant-target and the secret data.`,
+	ValidArgsFunction: cmd.SingleArg(cmd.CompleteSecrets),
 	Run: func(cmd *cobra.Command, args []string) {
 
 		if len(args) != 1 {
-			tools.HandleError(errors.New(tools.ERROR_WRONG_NUMBER_ARGUMENTS), cmd)
+			tools.HandleError(tools.CreateWrongNumberArgumentsError(), cmd)
 		}
 
+		tenant, _ := cmd.Flags().GetString("tenant")
+		target, _ := cmd.Flags().GetString("target")
+
 		s := tools.CreateStandardSpinner(tools.MESSAGE_GET_OBJECTS)
 
-		secret, err := clusterOperations.GetSecret(args[0], cmd)
+		client, err := clusterOperations.NewClientFromCmd(cmd)
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+
+		secret, err := client.GetSecret(args[0], clusterOperations.ScopeOptions{Tenant: tenant, Target: target})
 		if err != nil {
+			s.Stop()
 			tools.HandleError(err, cmd)
 		}
 
@@ -55,17 +67,26 @@ var getSecretCmd = &cobra.Command{
 			tools.HandleError(err, cmd)
 		}
 
-		t := table.NewWriter()
-		t.SetOutputMirror(os.Stdout)
-		t.AppendHeader(table.Row{"ATTRIBUTE", "VALUE"})
-		t.AppendRow(table.Row{"Name", secret.Name})
-		t.AppendRow(table.Row{"Namespace", secret.Namespace})
-		t.AppendRow(table.Row{"Data", string(secret.Data["secret"])})
+		view := tools.NewSecretView(secret, "secret")
 
 		s.Stop()
-
-		t.AppendSeparator()
-		t.Render()
+		err = tools.PrintView(cmd, view, func(wide bool) {
+			t := table.NewWriter()
+			t.SetOutputMirror(os.Stdout)
+			t.AppendHeader(table.Row{"ATTRIBUTE", "VALUE"})
+			t.AppendRow(table.Row{"Name", view.Name})
+			t.AppendRow(table.Row{"Namespace", view.TenantTarget})
+			if wide {
+				t.AppendRow(table.Row{"Type", view.Type})
+				t.AppendRow(table.Row{"Created At", view.CreatedAt})
+			}
+			t.AppendRow(table.Row{"Data", view.Data})
+			t.AppendSeparator()
+			t.Render()
+		})
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
 
 	},
 }
@@ -24,58 +24,78 @@ SOFTWARE.
 package get
 
 import (
-	"errors"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
 	"kufast/clusterOperations"
+	"kufast/cmd"
 	"kufast/tools"
 	"os"
 )
 
 // getTenantCmd represents the get tenant command
 var getTenantCmd = &cobra.Command{
-	Use:   "tenant <tenant name>",
-	Short: "Gain information about a deployed tenant.",
-	Long:  `Gain information about a deployed tenant. Output includes name, node access and group access`,
+	Use:               "tenant <tenant name>",
+	Short:             "Gain information about a deployed tenant.",
+	Long:              `Gain information about a deployed tenant. Output includes name, node access and group access`,
+	ValidArgsFunction: cmd.SingleArg(cmd.CompleteTenants),
 	Run: func(cmd *cobra.Command, args []string) {
 
 		if len(args) != 1 {
-			tools.HandleError(errors.New(tools.ERROR_WRONG_NUMBER_ARGUMENTS), cmd)
+			tools.HandleError(tools.CreateWrongNumberArgumentsError(), cmd)
 		}
 
 		s := tools.CreateStandardSpinner(tools.MESSAGE_GET_OBJECTS)
 
-		tenant, err := clusterOperations.GetTenantFromString(cmd, args[0])
+		client, err := clusterOperations.NewClientFromCmd(cmd)
 		if err != nil {
+			s.Stop()
 			tools.HandleError(err, cmd)
 		}
 
-		targets, err := clusterOperations.ListTargetsFromString(cmd, args[0], false)
+		tenant, err := client.GetTenant(args[0])
 		if err != nil {
+			s.Stop()
 			tools.HandleError(err, cmd)
 		}
-		var groupTargets []string
-		var nodeTargets []string
-
-		for _, target := range targets {
-			if target.AccessType == "group" {
-				groupTargets = append(groupTargets, target.Name)
-			} else {
-				nodeTargets = append(nodeTargets, target.Name)
-			}
-		}
 
-		t := table.NewWriter()
-		t.SetOutputMirror(os.Stdout)
-		t.AppendHeader(table.Row{"ATTRIBUTE", "VALUE"})
-		t.AppendRow(table.Row{"Name", tenant.Name})
-		t.AppendRow(table.Row{"Node Access", nodeTargets})
-		t.AppendRow(table.Row{"Group Access", groupTargets})
+		targets, err := client.ListTargets(args[0], false)
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+		view := tools.NewTenantView(tenant, targets)
 
 		s.Stop()
+		err = tools.PrintView(cmd, view, func(wide bool) {
+			var groupTargets []string
+			var nodeTargets []string
+
+			for _, target := range view.Targets {
+				if target.AccessType == "group" {
+					groupTargets = append(groupTargets, target.Name)
+				} else {
+					nodeTargets = append(nodeTargets, target.Name)
+				}
+			}
 
-		t.AppendSeparator()
-		t.Render()
+			t := table.NewWriter()
+			t.SetOutputMirror(os.Stdout)
+			t.AppendHeader(table.Row{"ATTRIBUTE", "VALUE"})
+			t.AppendRow(table.Row{"Name", view.Name})
+			t.AppendRow(table.Row{"Node Access", nodeTargets})
+			t.AppendRow(table.Row{"Group Access", groupTargets})
+			if wide {
+				t.AppendRow(table.Row{"Default Target", view.DefaultTarget})
+				t.AppendRow(table.Row{"Created At", view.CreatedAt})
+				t.AppendRow(table.Row{"Credentials Rotated At", view.CredentialsRotatedAt})
+			}
+
+			t.AppendSeparator()
+			t.Render()
+		})
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
 
 	},
 }
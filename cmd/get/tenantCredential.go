@@ -24,26 +24,45 @@ SOFTWARE.
 package get
 
 import (
-	"errors"
 	"fmt"
 	"github.com/spf13/cobra"
+	"kufast/clusterOperations"
+	"kufast/cmd"
 	"kufast/tools"
+	"time"
 )
 
 // getTenantCredsCmd represents the get tenant-creds command
 var getTenantCredsCmd = &cobra.Command{
 	Use:   "tenant-creds <tenant>",
 	Short: "Generate tenant credentials for specific tenant.",
-	Long:  `Generate tenant credentials for specific user. Can only be used by admins.`,
+	Long: `Generate tenant credentials for specific user. With --clusters, the credentials contain one context per
+cluster. Can only be used by admins.`,
+	ValidArgsFunction: cmd.SingleArg(cmd.CompleteTenants),
 	Run: func(cmd *cobra.Command, args []string) {
 
 		if len(args) != 1 {
-			tools.HandleError(errors.New(tools.ERROR_WRONG_NUMBER_ARGUMENTS), cmd)
+			tools.HandleError(tools.CreateWrongNumberArgumentsError(), cmd)
 		}
 
+		outputDir, _ := cmd.Flags().GetString("output-dir")
+		duration, _ := cmd.Flags().GetDuration("duration")
+
 		s := tools.CreateStandardSpinner(tools.MESSAGE_GET_OBJECTS)
 
-		err := tools.WriteNewUserYamlToFile(args[0], cmd, s)
+		clients, err := clusterOperations.NewClientsFromCmd(cmd)
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+
+		config, err := clusterOperations.GetTenantKubeconfigForClusters(clients, args[0], clusterOperations.CredentialOptions{Duration: duration})
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+
+		err = tools.WriteNewUserYamlToFile(args[0], config, outputDir, s)
 		if err != nil {
 			s.Stop()
 			tools.HandleError(err, cmd)
@@ -58,7 +77,8 @@ var getTenantCredsCmd = &cobra.Command{
 // init is a helper function from cobra to initialize the command. It sets all flags, standard values and documentation for this command.
 func init() {
 	getCmd.AddCommand(getTenantCredsCmd)
-	getTenantCredsCmd.Flags().StringP("output", "o", ".", "Folder to store the created client credentials. Mandatory, when defining -u")
-	_ = getTenantCredsCmd.MarkFlagRequired("output")
+	getTenantCredsCmd.Flags().StringP("output-dir", "d", ".", "Folder to store the created client credentials.")
+	_ = getTenantCredsCmd.MarkFlagRequired("output-dir")
+	getTenantCredsCmd.Flags().DurationP("duration", "", 8760*time.Hour, "Lifetime of the tenant credentials. Use 0 to issue long-lived credentials, which are valid until they are revoked.")
 
 }
@@ -24,86 +24,95 @@ SOFTWARE.
 package get
 
 import (
-	"context"
-	"errors"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"kufast/clusterOperations"
+	"kufast/cmd"
 	"kufast/tools"
 	"os"
 )
 
 // getTenantTargetCmd represents the tenant-target command
 var getTenantTargetCmd = &cobra.Command{
-	Use:   "tenant-target <tenant-target>",
-	Short: "Gain information on a tenant target.",
-	Long:  `Gain information on a tenant target. Lists name, status, limits, their usage, and the number of included pods`,
+	Use:               "tenant-target <tenant-target>",
+	Short:             "Gain information on a tenant target.",
+	Long:              `Gain information on a tenant target. Lists name, status, limits, their usage, and the number of included pods`,
+	ValidArgsFunction: cmd.SingleArg(cmd.CompleteTenantTargets),
 	Run: func(cmd *cobra.Command, args []string) {
 
 		if len(args) != 1 {
-			tools.HandleError(errors.New(tools.ERROR_WRONG_NUMBER_ARGUMENTS), cmd)
+			tools.HandleError(tools.CreateWrongNumberArgumentsError(), cmd)
 		}
 
 		s := tools.CreateStandardSpinner(tools.MESSAGE_GET_OBJECTS)
 
 		//Initial config block
-		clientset, _, err := tools.GetUserClient(cmd)
+		client, err := clusterOperations.NewClientFromCmd(cmd)
 		if err != nil {
+			s.Stop()
 			tools.HandleError(err, cmd)
 		}
 
-		tenantName, err := clusterOperations.GetTenantNameFromCmd(cmd)
+		tenant, _ := cmd.Flags().GetString("tenant")
+		tenantName, err := client.GetTenantName(tenant)
 		if err != nil {
+			s.Stop()
 			tools.HandleError(err, cmd)
 		}
 
-		tenantTargetName := tenantName + "-" + args[0]
-
-		nameSpace, err := clientset.CoreV1().Namespaces().Get(context.TODO(), tenantTargetName, metav1.GetOptions{})
+		nameSpace, err := client.GetTenantTarget(tenantName, args[0])
 		if err != nil {
+			s.Stop()
 			tools.HandleError(err, cmd)
 		}
 
-		quota, err := clientset.CoreV1().ResourceQuotas(tenantTargetName).Get(context.TODO(), tenantTargetName+"-limits", metav1.GetOptions{})
+		quota, err := client.GetTenantTargetQuota(tenantName, args[0])
 		if err != nil {
+			s.Stop()
 			tools.HandleError(err, cmd)
 		}
 
-		pods, err := clientset.CoreV1().Pods(tenantTargetName).List(context.TODO(), metav1.ListOptions{})
+		pods, err := client.ListTenantTargetPods(tenantName, args[0])
 		if err != nil {
+			s.Stop()
 			tools.HandleError(err, cmd)
 		}
 
-		cpuLim, _ := quota.Spec.Hard["limits.cpu"].MarshalJSON()
-		cpuReq, _ := quota.Spec.Hard["requests.cpu"].MarshalJSON()
-		memLim, _ := quota.Spec.Hard["limits.memory"].MarshalJSON()
-		memReq, _ := quota.Spec.Hard["requests.memory"].MarshalJSON()
-		StorageLim, _ := quota.Spec.Hard["limits.ephemeral-storage"].MarshalJSON()
-		StorageReq, _ := quota.Spec.Hard["requests.ephemeral-storage"].MarshalJSON()
-
-		t := table.NewWriter()
-		t.SetOutputMirror(os.Stdout)
-		t.AppendHeader(table.Row{"ATTRIBUTE", "VALUE"})
-		t.AppendRow(table.Row{"name", nameSpace.Name})
-		t.AppendRow(table.Row{"Status", nameSpace.Status.Phase})
-		t.AppendSeparator()
-		t.AppendRow(table.Row{"CPU-Limit", "Limit: " + string(cpuLim) +
-			"\nRequests: " + string(cpuReq)})
-		t.AppendRow(table.Row{"Memory-Limit", "Limit: " + string(memLim) +
-			"\nRequests: " + string(memReq)})
-		t.AppendRow(table.Row{"Storage-Limit", "Limit: " + string(StorageLim) +
-			"\nRequests: " + string(StorageReq)})
-		t.AppendSeparator()
-		t.AppendRow(table.Row{"Used CPU", quota.Status.Used.Cpu()})
-		t.AppendRow(table.Row{"Used Memory", quota.Status.Used.Memory()})
-		t.AppendRow(table.Row{"Used Storage", quota.Status.Used.Storage()})
-		t.AppendSeparator()
-		t.AppendRow(table.Row{"# Pods", len(pods.Items)})
-		t.AppendSeparator()
+		view := tools.NewTenantTargetView(nameSpace, quota, pods)
 
 		s.Stop()
-		t.Render()
+		err = tools.PrintView(cmd, view, func(wide bool) {
+			t := table.NewWriter()
+			t.SetOutputMirror(os.Stdout)
+			t.AppendHeader(table.Row{"ATTRIBUTE", "VALUE"})
+			t.AppendRow(table.Row{"name", view.Name})
+			t.AppendRow(table.Row{"Status", view.Status})
+			if wide {
+				t.AppendRow(table.Row{"Node Selector", view.NodeSelector})
+			}
+			t.AppendSeparator()
+			t.AppendRow(table.Row{"CPU-Limit", "Limit: " + view.Limits.CPU +
+				"\nRequests: " + view.Requests.CPU})
+			t.AppendRow(table.Row{"Memory-Limit", "Limit: " + view.Limits.Memory +
+				"\nRequests: " + view.Requests.Memory})
+			t.AppendRow(table.Row{"Storage-Limit", "Limit: " + view.Limits.Storage +
+				"\nRequests: " + view.Requests.Storage})
+			if wide {
+				t.AppendRow(table.Row{"Pod-Limit", view.Limits.Pods})
+			}
+			t.AppendSeparator()
+			t.AppendRow(table.Row{"Used CPU", view.Used.CPU})
+			t.AppendRow(table.Row{"Used Memory", view.Used.Memory})
+			t.AppendRow(table.Row{"Used Storage", view.Used.Storage})
+			t.AppendSeparator()
+			t.AppendRow(table.Row{"# Pods", view.Pods})
+			t.AppendSeparator()
+
+			t.Render()
+		})
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
 	},
 }
 
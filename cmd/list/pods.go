@@ -39,25 +39,59 @@ var listPodsCmd = &cobra.Command{
 To gain further information see the kubectl get pod command.`,
 	Run: func(cmd *cobra.Command, args []string) {
 
+		tenant, _ := cmd.Flags().GetString("tenant")
+
 		s := tools.CreateStandardSpinner(tools.MESSAGE_GET_OBJECTS)
 
-		pods, err := clusterOperations.ListTenantPods(cmd)
+		clients, err := clusterOperations.NewClientsFromCmd(cmd)
 		if err != nil {
 			s.Stop()
 			tools.HandleError(err, cmd)
 		}
 
-		//build table
-		t := table.NewWriter()
-		t.SetOutputMirror(os.Stdout)
-		t.AppendHeader(table.Row{"NAME", "NAMESPACE", "STATUS", "POD MESSAGE"})
-		for _, pod := range pods {
-			t.AppendRow(table.Row{pod.Name, pod.Namespace, pod.Status.Phase, pod.Status.Message})
+		var views []tools.View
+		for _, client := range clients {
+			pods, err := client.ListTenantPods(tenant)
+			if err != nil {
+				s.Stop()
+				tools.HandleError(err, cmd)
+			}
 
+			for _, pod := range pods {
+				view := tools.NewPodView(&pod, nil)
+				view.Cluster = client.Cluster()
+				views = append(views, view)
+			}
 		}
+
 		s.Stop()
-		t.AppendSeparator()
-		t.Render()
+		err = tools.PrintViews(cmd, views, func(wide bool) {
+			//build table
+			t := table.NewWriter()
+			t.SetOutputMirror(os.Stdout)
+			var header table.Row
+			if wide {
+				header = table.Row{"NAME", "NAMESPACE", "STATUS", "RESTARTS", "NODE", "IP", "IMAGE", "POD MESSAGE"}
+			} else {
+				header = table.Row{"NAME", "NAMESPACE", "STATUS", "POD MESSAGE"}
+			}
+			t.AppendHeader(withClusterColumn(len(clients), "CLUSTER", header))
+			for _, view := range views {
+				pod := view.(tools.PodView)
+				var row table.Row
+				if wide {
+					row = table.Row{pod.Name, pod.TenantTarget, pod.Status, pod.Restarts, pod.Node, pod.IP, pod.Image, pod.Message}
+				} else {
+					row = table.Row{pod.Name, pod.TenantTarget, pod.Status, pod.Message}
+				}
+				t.AppendRow(withClusterColumn(len(clients), pod.Cluster, row))
+			}
+			t.AppendSeparator()
+			t.Render()
+		})
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
 
 	},
 }
@@ -0,0 +1,104 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package list
+
+import (
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"kufast/clusterOperations"
+	"kufast/objectFactory"
+	"kufast/tools"
+	"os"
+)
+
+// listRequestsCmd represents the list requests command
+var listRequestsCmd = &cobra.Command{
+	Use:   "requests",
+	Short: "List requests of tenants for tenant-targets.",
+	Long: `List the pending requests of tenants for tenant-targets along with the requested limits. Use --all to include
+approved and denied requests. Admins see the requests of all tenants, tenants see their own requests.`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		tenantName, _ := cmd.Flags().GetString("tenant")
+		all, _ := cmd.Flags().GetBool("all")
+		state := objectFactory.RequestStatePending
+		if all {
+			state = ""
+		}
+
+		s := tools.CreateStandardSpinner(tools.MESSAGE_GET_OBJECTS)
+
+		client, err := clusterOperations.NewClientFromCmd(cmd)
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+
+		requests, err := client.ListTenantTargetRequests(tenantName, state)
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+
+		var views []tools.View
+		for _, request := range requests {
+			views = append(views, tools.NewRequestView(&request))
+		}
+
+		s.Stop()
+		err = tools.PrintViews(cmd, views, func(wide bool) {
+			t := table.NewWriter()
+			t.SetOutputMirror(os.Stdout)
+			if wide {
+				t.AppendHeader(table.Row{"ID", "TENANT", "TARGET", "CPU", "MEMORY", "STORAGE", "MIN STORAGE", "PODS", "STATE", "REASON", "Created At"})
+			} else {
+				t.AppendHeader(table.Row{"ID", "TENANT", "TARGET", "CPU", "MEMORY", "STORAGE", "PODS", "STATE"})
+			}
+			for _, view := range views {
+				request := view.(tools.RequestView)
+				if wide {
+					t.AppendRow(table.Row{request.ID, request.Tenant, request.Target, request.Limits.CPU, request.Limits.Memory,
+						request.Limits.Storage, request.MinStorage, request.Limits.Pods, request.State, request.Reason, request.CreatedAt})
+				} else {
+					t.AppendRow(table.Row{request.ID, request.Tenant, request.Target, request.Limits.CPU, request.Limits.Memory,
+						request.Limits.Storage, request.Limits.Pods, request.State})
+				}
+			}
+			t.AppendSeparator()
+			t.Render()
+		})
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
+	},
+}
+
+// init is a helper function from cobra to initialize the command. It sets all flags, standard values and documentation for this command.
+func init() {
+	listCmd.AddCommand(listRequestsCmd)
+
+	listRequestsCmd.Flags().BoolP("all", "a", false, "Include approved and denied requests.")
+	listRequestsCmd.Flags().StringP("tenant", "", "", "Only list the requests of this tenant. Admins see all tenants by default.")
+
+}
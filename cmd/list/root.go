@@ -24,8 +24,10 @@ SOFTWARE.
 package list
 
 import (
+	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra/doc"
 	"kufast/cmd"
+	"kufast/tools"
 	"log"
 	"os"
 
@@ -44,6 +46,18 @@ Use these features to list tenants, pods and more.`,
 func init() {
 	cmd.RootCmd.AddCommand(listCmd)
 
+	//Enables machine-readable output for all commands in list.
+	tools.AddOutputFlag(listCmd)
+
+}
+
+// withClusterColumn is a helper function to prepend the cluster to a row of a table, if objects of several clusters
+// are listed.
+func withClusterColumn(clusters int, cluster string, row table.Row) table.Row {
+	if clusters < 2 {
+		return row
+	}
+	return append(table.Row{cluster}, row...)
 }
 
 func CreateListDocs(fileP func(string) string, linkH func(string) string) {
@@ -39,25 +39,43 @@ var listSecretsCmd = &cobra.Command{
 To gain further information see the kubectl get pod command.`,
 	Run: func(cmd *cobra.Command, args []string) {
 
+		tenant, _ := cmd.Flags().GetString("tenant")
+
 		s := tools.CreateStandardSpinner(tools.MESSAGE_GET_OBJECTS)
 
-		secrets, err := clusterOperations.ListSecrets(cmd)
+		client, err := clusterOperations.NewClientFromCmd(cmd)
 		if err != nil {
 			s.Stop()
 			tools.HandleError(err, cmd)
 		}
 
-		//build table
-		t := table.NewWriter()
-		t.SetOutputMirror(os.Stdout)
-		t.AppendHeader(table.Row{"NAME", "NAMESPACE", "TYPE", "CREATED AT"})
+		secrets, err := client.ListSecrets(tenant)
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+
+		var views []tools.View
 		for _, secret := range secrets {
-			t.AppendRow(table.Row{secret.Name, secret.Namespace, secret.Type, secret.CreationTimestamp})
+			views = append(views, tools.NewSecretView(&secret, ""))
 		}
 
 		s.Stop()
-		t.AppendSeparator()
-		t.Render()
+		err = tools.PrintViews(cmd, views, func(wide bool) {
+			//build table
+			t := table.NewWriter()
+			t.SetOutputMirror(os.Stdout)
+			t.AppendHeader(table.Row{"NAME", "NAMESPACE", "TYPE", "CREATED AT"})
+			for _, view := range views {
+				secret := view.(tools.SecretView)
+				t.AppendRow(table.Row{secret.Name, secret.TenantTarget, secret.Type, secret.CreatedAt})
+			}
+			t.AppendSeparator()
+			t.Render()
+		})
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
 	},
 }
 
@@ -43,20 +43,41 @@ var listTargetsCmd = &cobra.Command{
 			tools.HandleError(err, cmd)
 		}
 
+		tenant, _ := cmd.Flags().GetString("tenant")
+
 		s := tools.CreateStandardSpinner(tools.MESSAGE_GET_OBJECTS)
 
-		targets, err := clusterOperations.ListTargetsFromCmd(cmd, all)
+		client, err := clusterOperations.NewClientFromCmd(cmd)
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
 
-		t := table.NewWriter()
-		t.SetOutputMirror(os.Stdout)
-		t.AppendHeader(table.Row{"NAME", "Type"})
+		targets, err := client.ListTargets(tenant, all)
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+
+		var views []tools.View
 		for _, target := range targets {
-			t.AppendRow(table.Row{target.Name, target.AccessType})
+			views = append(views, target)
 		}
 
 		s.Stop()
-		t.AppendSeparator()
-		t.Render()
+		err = tools.PrintViews(cmd, views, func(wide bool) {
+			t := table.NewWriter()
+			t.SetOutputMirror(os.Stdout)
+			t.AppendHeader(table.Row{"NAME", "Type"})
+			for _, target := range targets {
+				t.AppendRow(table.Row{target.Name, target.AccessType})
+			}
+			t.AppendSeparator()
+			t.Render()
+		})
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
 	},
 }
 
@@ -24,10 +24,8 @@ SOFTWARE.
 package list
 
 import (
-	"context"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"kufast/clusterOperations"
 	"kufast/tools"
 	"os"
@@ -40,72 +38,72 @@ var listTenantTargetsCmd = &cobra.Command{
 	Long:  `List all tenant-targets of a tenant. The overview contains the limit information of each tenant target.`,
 	Run: func(cmd *cobra.Command, args []string) {
 
+		tenant, _ := cmd.Flags().GetString("tenant")
+
+		s := tools.CreateStandardSpinner(tools.MESSAGE_GET_OBJECTS)
+
 		//Initial config block
-		clientset, _, err := tools.GetUserClient(cmd)
+		client, err := clusterOperations.NewClientFromCmd(cmd)
 		if err != nil {
+			s.Stop()
 			tools.HandleError(err, cmd)
 		}
 
-		tenantName, err := clusterOperations.GetTenantNameFromCmd(cmd)
+		tenantName, err := client.GetTenantName(tenant)
 		if err != nil {
+			s.Stop()
 			tools.HandleError(err, cmd)
 		}
 
-		namespaces, err := clusterOperations.ListTenantTargets(tenantName, cmd)
+		tenantTargets, err := client.ListVisibleTenantTargets(tenantName)
 		if err != nil {
+			s.Stop()
 			tools.HandleError(err, cmd)
 		}
 
-		s := tools.CreateStandardSpinner(tools.MESSAGE_GET_OBJECTS)
-
-		//build table
-		t := table.NewWriter()
-		t.SetOutputMirror(os.Stdout)
-		t.AppendHeader(table.Row{"NAME", "STATUS", "CPU Limit", "Memory Limit", "Storage Limit"})
-		for _, namespace := range namespaces {
+		var views []tools.View
+		for _, tenantTarget := range tenantTargets {
+			views = append(views, tenantTarget)
+		}
 
-			quotas, err := clientset.CoreV1().ResourceQuotas(namespace.Name).List(context.TODO(), metav1.ListOptions{})
-			if err != nil {
-				tools.HandleError(err, cmd)
+		s.Stop()
+		err = tools.PrintViews(cmd, views, func(wide bool) {
+			//build table
+			t := table.NewWriter()
+			t.SetOutputMirror(os.Stdout)
+			if wide {
+				t.AppendHeader(table.Row{"NAME", "STATUS", "CPU Limit", "Memory Limit", "Storage Limit", "Pod Limit", "# Pods", "Node Selector"})
+			} else {
+				t.AppendHeader(table.Row{"NAME", "STATUS", "CPU Limit", "Memory Limit", "Storage Limit"})
 			}
-
-			var memoryQuota string
-			var cpuQuota string
-			var storageQuota string
-
-			if len(quotas.Items) == 1 {
-				cpuQuotaBytes, err := quotas.Items[0].Spec.Hard["limits.cpu"].MarshalJSON()
-				if err != nil {
-					cpuQuota = "None"
-				} else {
-					cpuQuota = string(cpuQuotaBytes)
-				}
-				memoryQuotaBytes, err := quotas.Items[0].Spec.Hard["limits.memory"].MarshalJSON()
-				if err != nil {
-					memoryQuota = "None"
-				} else {
-					memoryQuota = string(memoryQuotaBytes)
-				}
-				storageQuotaBytes, err := quotas.Items[0].Spec.Hard["requests.ephemeral-storage"].MarshalJSON()
-				if err != nil {
-					storageQuota = "None"
+			for _, view := range views {
+				tenantTarget := view.(tools.TenantTargetView)
+				if wide {
+					t.AppendRow(table.Row{tenantTarget.Name, tenantTarget.Status, valueOrNone(tenantTarget.Limits.CPU),
+						valueOrNone(tenantTarget.Limits.Memory), valueOrNone(tenantTarget.Requests.Storage),
+						valueOrNone(tenantTarget.Limits.Pods), tenantTarget.Pods, tenantTarget.NodeSelector})
 				} else {
-					storageQuota = string(storageQuotaBytes)
+					t.AppendRow(table.Row{tenantTarget.Name, tenantTarget.Status, valueOrNone(tenantTarget.Limits.CPU),
+						valueOrNone(tenantTarget.Limits.Memory), valueOrNone(tenantTarget.Requests.Storage)})
 				}
-			} else {
-				memoryQuota = "Quota missing or too many quotas"
-				cpuQuota = "Quota missing or too many quotas"
 			}
-
-			t.AppendRow(table.Row{namespace.Name, namespace.Status, cpuQuota, memoryQuota, storageQuota})
-
+			t.AppendSeparator()
+			t.Render()
+		})
+		if err != nil {
+			tools.HandleError(err, cmd)
 		}
-		s.Stop()
-		t.AppendSeparator()
-		t.Render()
 	},
 }
 
+// valueOrNone is a helper function to show missing limits in the table
+func valueOrNone(value string) string {
+	if value == "" {
+		return "None"
+	}
+	return value
+}
+
 // init is a helper function from cobra to initialize the command. It sets all flags, standard values and documentation for this command.
 func init() {
 	listCmd.AddCommand(listTenantTargetsCmd)
@@ -24,13 +24,12 @@ SOFTWARE.
 package list
 
 import (
-	"context"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"kufast/clusterOperations"
 	"kufast/tools"
 	"os"
+	"strings"
 )
 
 // listTenantsCmd represents the list tenants command
@@ -43,37 +42,61 @@ of targets, this tenant can deploy to and the create date of this tenant.`,
 
 		s := tools.CreateStandardSpinner(tools.MESSAGE_GET_OBJECTS)
 
-		clientset, _, err := tools.GetUserClient(cmd)
+		clients, err := clusterOperations.NewClientsFromCmd(cmd)
 		if err != nil {
 			s.Stop()
 			tools.HandleError(err, cmd)
 		}
 
-		//execute request
-		users, err := clientset.CoreV1().ServiceAccounts("default").List(context.TODO(), metav1.ListOptions{})
-		if err != nil {
-			s.Stop()
-			tools.HandleError(err, cmd)
-		}
-
-		//build table
-		t := table.NewWriter()
-		t.SetOutputMirror(os.Stdout)
-		t.AppendHeader(table.Row{"NAME", "NAMESPACE", "# Tenant Targets", "Created At"})
-		for _, user := range users.Items {
-			if user.ObjectMeta.Labels[tools.KUFAST_TENANT_LABEL] != "" {
-				targets, err := clusterOperations.ListTargetsFromString(cmd, user.ObjectMeta.Labels[tools.KUFAST_TENANT_LABEL], false)
-				if err != nil {
-
-				}
-				t.AppendRow(table.Row{user.Name, user.Namespace, len(targets), user.CreationTimestamp})
+		var views []tools.View
+		for _, client := range clients {
+			//execute request
+			users, err := client.ListTenants()
+			if err != nil {
+				s.Stop()
+				tools.HandleError(err, cmd)
 			}
 
+			for _, user := range users {
+				targets, _ := client.ListTargets(user.ObjectMeta.Labels[tools.KUFAST_TENANT_LABEL], false)
+				view := tools.NewTenantView(&user, targets)
+				view.Cluster = client.Cluster()
+				views = append(views, view)
+			}
 		}
 
 		s.Stop()
-		t.AppendSeparator()
-		t.Render()
+		err = tools.PrintViews(cmd, views, func(wide bool) {
+			//build table
+			t := table.NewWriter()
+			t.SetOutputMirror(os.Stdout)
+			var header table.Row
+			if wide {
+				header = table.Row{"NAME", "# Tenant Targets", "Default Target", "Targets", "Created At"}
+			} else {
+				header = table.Row{"NAME", "# Tenant Targets", "Created At"}
+			}
+			t.AppendHeader(withClusterColumn(len(clients), "CLUSTER", header))
+			for _, view := range views {
+				tenant := view.(tools.TenantView)
+				var row table.Row
+				if wide {
+					var targetNames []string
+					for _, target := range tenant.Targets {
+						targetNames = append(targetNames, target.Name)
+					}
+					row = table.Row{tenant.Name, len(tenant.Targets), tenant.DefaultTarget, strings.Join(targetNames, ","), tenant.CreatedAt}
+				} else {
+					row = table.Row{tenant.Name, len(tenant.Targets), tenant.CreatedAt}
+				}
+				t.AppendRow(withClusterColumn(len(clients), tenant.Cluster, row))
+			}
+			t.AppendSeparator()
+			t.Render()
+		})
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
 	},
 }
 
@@ -0,0 +1,64 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package repair
+
+import (
+	"github.com/spf13/cobra/doc"
+	"kufast/cmd"
+	"kufast/tools"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// repairCmd represents the repair command. It cannot be executed itself but only its subcommands.
+var repairCmd = &cobra.Command{
+	Use:   "repair",
+	Short: "Repair kufast objects",
+	Long: `The repair subcommand is a collection of all repair operations available in kufast.
+Use these features to restore objects of tenants, which have been deleted or modified outside of kufast.`,
+}
+
+// init is a helper function from cobra to initialize the command. It sets all flags, standard values and documentation for this command.
+func init() {
+	cmd.RootCmd.AddCommand(repairCmd)
+
+	//Enables machine-readable output for all commands in repair.
+	tools.AddOutputFlag(repairCmd)
+
+}
+
+func CreateRepairDocs(fileP func(string) string, linkH func(string) string) {
+
+	err := os.MkdirAll("./kufast.wiki/repair/", 0770)
+	if err != nil {
+		panic(err)
+	}
+
+	err = doc.GenMarkdownTreeCustom(repairCmd, "./kufast.wiki/repair/", fileP, linkH)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,98 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package repair
+
+import (
+	"fmt"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"kufast/clusterOperations"
+	"kufast/cmd"
+	"kufast/tools"
+	"os"
+)
+
+// repairTenantCmd represents the repair tenant command
+var repairTenantCmd = &cobra.Command{
+	Use:   "tenant <tenant>..",
+	Short: "Restore objects of tenants, which are missing or have been modified.",
+	Long: `Restore objects of tenants and their tenant-targets, which are missing or differ from the objects kufast
+creates. Quotas are restored with the limits the tenant-targets have been created or updated with; missing
+tenant-targets and limit ranges are restored with the default limits of kufast. Use 'kufast check tenant' to see what
+will be restored. Can only be used by admins.`,
+	ValidArgsFunction: cmd.CompleteTenants,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		if len(args) < 1 {
+			tools.HandleError(tools.CreateWrongNumberArgumentsError(), cmd)
+		}
+
+		s := tools.CreateStandardSpinner(tools.MESSAGE_UPDATE_OBJECTS)
+
+		client, err := clusterOperations.NewClientFromCmd(cmd)
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+
+		var repaired []tools.View
+		for _, tenantName := range args {
+			drifts, err := client.RepairTenant(tenantName)
+			for _, drift := range drifts {
+				repaired = append(repaired, drift)
+			}
+			if err != nil {
+				s.Stop()
+				tools.HandleError(err, cmd)
+			}
+		}
+
+		s.Stop()
+		err = tools.PrintViews(cmd, repaired, func(wide bool) {
+			if len(repaired) == 0 {
+				fmt.Println("No drift found.")
+				return
+			}
+			t := table.NewWriter()
+			t.SetOutputMirror(os.Stdout)
+			t.AppendHeader(table.Row{"KIND", "NAMESPACE", "NAME", "RESTORED"})
+			for _, view := range repaired {
+				drift := view.(clusterOperations.Drift)
+				t.AppendRow(table.Row{drift.Kind, drift.Namespace, drift.Name, drift.Problem})
+			}
+			t.AppendSeparator()
+			t.Render()
+			fmt.Println(tools.MESSAGE_DONE)
+		})
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
+	},
+}
+
+// init is a helper function from cobra to initialize the command. It sets all flags, standard values and documentation for this command.
+func init() {
+	repairCmd.AddCommand(repairTenantCmd)
+
+}
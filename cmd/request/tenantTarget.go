@@ -0,0 +1,101 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package request
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"kufast/clusterOperations"
+	"kufast/cmd"
+	"kufast/tools"
+	"os"
+)
+
+// requestTenantTargetCmd represents the request tenant-target command
+var requestTenantTargetCmd = &cobra.Command{
+	Use:   "tenant-target <target>..",
+	Short: "Request one or more new tenant-targets from the admins",
+	Long: `Request one or more new tenant-targets with the given limits. The requests are stored in the cluster, until
+an admin approves them with 'kufast approve request <id>' or denies them with 'kufast deny request <id>'.
+Use 'kufast list requests --all' to see the state of your requests.
+`,
+	ValidArgsFunction: cmd.CompleteTargets,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		if len(args) < 1 {
+			tools.HandleError(tools.CreateWrongNumberArgumentsError(), cmd)
+		}
+
+		tenantName, _ := cmd.Flags().GetString("tenant")
+		var opts clusterOperations.TenantTargetOptions
+		opts.Memory, _ = cmd.Flags().GetString("memory")
+		opts.CPU, _ = cmd.Flags().GetString("cpu")
+		opts.Storage, _ = cmd.Flags().GetString("storage")
+		opts.MinStorage, _ = cmd.Flags().GetString("storage-min")
+		opts.Pods, _ = cmd.Flags().GetString("pods")
+
+		s := tools.CreateStandardSpinner(tools.MESSAGE_CREATE_OBJECTS)
+
+		client, err := clusterOperations.NewClientFromCmd(cmd)
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+
+		var filed []string
+		var errs []error
+		for _, targetName := range args {
+			request, err := client.CreateTenantTargetRequest(tenantName, targetName, opts)
+			if err != nil {
+				s.Stop()
+				fmt.Fprintln(os.Stderr, err.Error())
+				s.Start()
+				errs = append(errs, err)
+				continue
+			}
+			filed = append(filed, "Filed request "+request.Data["id"]+" for the target "+targetName+".")
+		}
+
+		s.Stop()
+		for _, message := range filed {
+			fmt.Println(message)
+		}
+		tools.ExitWithErrors(errs)
+		fmt.Println(tools.MESSAGE_DONE)
+	},
+}
+
+// init is a helper function from cobra to initialize the command. It sets all flags, standard values and documentation for this command.
+func init() {
+	requestCmd.AddCommand(requestTenantTargetCmd)
+
+	requestTenantTargetCmd.Flags().StringP("memory", "", "1Gi", "The RAM limit requested for the tenant-target(s)")
+	requestTenantTargetCmd.Flags().StringP("cpu", "", "500m", "The CPU limit requested for the tenant-target(s)")
+	requestTenantTargetCmd.Flags().StringP("pods", "", "1", "The number of pods requested for the tenant-target(s)")
+	requestTenantTargetCmd.Flags().StringP("storage", "", "10Gi", "The total storage requested for the tenant-target(s)")
+	requestTenantTargetCmd.Flags().StringP("storage-min", "", "1Gi", "The amount of storage, each pod must consume")
+
+	requestTenantTargetCmd.Flags().StringP("tenant", "t", "", "The tenant, which requests the tenant-target(s). Defaults to the tenant of your credentials.")
+
+}
@@ -26,7 +26,11 @@ package cmd
 import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/cobra/doc"
+	"kufast/tools"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 )
 
 // RootCmd represents the base command when called without any subcommands
@@ -36,20 +40,56 @@ var RootCmd = &cobra.Command{
 	Long: `A small tool for creating a simple multi tenant environment on bare Kubernetes environments. The
 tool is especially designed for people with limited Kubernetes experience, who still want to use
 a Kubernetes deployment environment for their containerized applications.`,
+	//Flags not set by the user take their values from the profile of the kufast config file
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return tools.ApplyProfile(cmd)
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
+	//Installed as kubectl-kufast, kufast is run by kubectl as the plugin "kubectl kufast"
+	if strings.HasPrefix(filepath.Base(os.Args[0]), "kubectl-") {
+		RootCmd.Use = "kubectl-kufast verb object [options]"
+	}
+
+	registerFlagCompletions(RootCmd)
+
 	err := RootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		os.Exit(tools.GetExitCode(commandLineError(err)))
 	}
 }
 
+// commandLineError classifies an error returned by cobra. Commands handle their own errors, so errors without a kind
+// stem from parsing the command line, e.g. unknown commands, and are usage errors.
+func commandLineError(err error) error {
+	if err != nil && tools.GetErrorKind(err) == tools.ERROR_KIND_UNKNOWN {
+		return tools.NewError(tools.ERROR_KIND_USAGE, err.Error())
+	}
+	return err
+}
+
 // init is a helper function from cobra to initialize the command. It sets all flags, standard values and documentation for this command.
 func init() {
-	RootCmd.PersistentFlags().StringP("kubeconfig", "k", "", "Your kubeconfig to access the cluster. If not provided, we read it from $HOME/.kube/config")
+	//Invalid flags are usage errors of all commands
+	RootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return tools.NewError(tools.ERROR_KIND_USAGE, err.Error())
+	})
+
+	//Standard kubectl flags like --kubeconfig, --context, --namespace and --as
+	tools.AddKubeconfigFlags(RootCmd.PersistentFlags())
+	RootCmd.PersistentFlags().StringP("profile", "", "", "The profile of the kufast config file to use. Defaults to the current profile.")
+	RootCmd.PersistentFlags().StringSliceP("clusters", "", nil, "Run the command on several clusters, given as contexts of your kubeconfig, e.g. prod,staging. Use 'all' for every context.")
+	RootCmd.PersistentFlags().BoolP("wait", "", true, "Wait until pods are running and deleted objects are gone.")
+	RootCmd.PersistentFlags().BoolP("no-wait", "", false, "Return as soon as the cluster accepted the request. Same as --wait=false.")
+	RootCmd.PersistentFlags().BoolP("yes", "y", false, "Confirm destructive operations without asking. Required, if stdin is not a terminal.")
+	RootCmd.PersistentFlags().DurationP("timeout", "", 2*time.Minute, "The maximum time to wait for the cluster, e.g. 30s or 5m.")
+	RootCmd.PersistentFlags().Float32P("qps", "", 50, "The maximum number of requests per second sent to the cluster.")
+	RootCmd.PersistentFlags().IntP("burst", "", 100, "The maximum number of requests sent to the cluster at once, before --qps applies.")
+	RootCmd.PersistentFlags().CountP("verbose", "v", "Log every API call to the cluster on stderr. Use -vv to log the URLs and the responses of failed calls as well.")
+	RootCmd.PersistentFlags().StringP("trace-file", "", "", "Write all requests to and responses from the cluster to a file for support. Credentials and the data of secrets are redacted.")
 
 }
 
@@ -0,0 +1,87 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"io"
+	"kufast/tools"
+	"path/filepath"
+	"testing"
+)
+
+// executeTestCmd runs the root command with a subcommand, which does nothing, and returns the exit code of kufast.
+func executeTestCmd(t *testing.T, args ...string) int {
+	t.Helper()
+	t.Setenv("KUFAST_CONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+
+	testCmd := &cobra.Command{Use: "test-cmd", Run: func(cmd *cobra.Command, args []string) {}}
+	RootCmd.AddCommand(testCmd)
+	t.Cleanup(func() {
+		RootCmd.RemoveCommand(testCmd)
+		resetFlags(RootCmd.PersistentFlags())
+	})
+
+	RootCmd.SetArgs(args)
+	RootCmd.SetOut(io.Discard)
+	RootCmd.SetErr(io.Discard)
+	return tools.GetExitCode(commandLineError(RootCmd.Execute()))
+}
+
+// resetFlags sets all flags changed by a test back to their defaults, as the root command keeps them between runs.
+func resetFlags(flags *pflag.FlagSet) {
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if !flag.Changed {
+			return
+		}
+		if value, ok := flag.Value.(pflag.SliceValue); ok {
+			_ = value.Replace(nil)
+		} else {
+			_ = flag.Value.Set(flag.DefValue)
+		}
+		flag.Changed = false
+	})
+}
+
+func TestExecuteExitCodes(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{name: "success", args: []string{"test-cmd"}, code: 0},
+		{name: "unknown flag", args: []string{"test-cmd", "--bogus"}, code: 2},
+		{name: "invalid flag value", args: []string{"test-cmd", "--timeout", "soon"}, code: 2},
+		{name: "unknown command", args: []string{"bogus"}, code: 2},
+		{name: "missing profile", args: []string{"test-cmd", "--profile", "missing"}, code: 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if code := executeTestCmd(t, test.args...); code != test.code {
+				t.Errorf("expected exit code %d, got %d", test.code, code)
+			}
+		})
+	}
+}
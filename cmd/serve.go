@@ -0,0 +1,144 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+	"kufast/clusterOperations"
+	"kufast/tools"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the operations of kufast as a JSON REST API.",
+	Long: `Serve the operations of kufast as a JSON REST API, e.g. for a self-service portal. Callers authenticate with
+the bearer token of their tenant credentials or admin credentials, and every request is executed with that token.
+The credentials of the kubeconfig are only used to find the cluster. Version 1 of the API offers:
+
+GET, POST            /api/v1/tenants
+GET                  /api/v1/tenants/<tenant>
+GET                  /api/v1/tenants/<tenant>/credentials?duration=<duration>
+GET                  /api/v1/tenants/<tenant>/tenant-targets[/<target>]
+GET, POST            /api/v1/tenants/<tenant>/tenant-targets/<target>/pods
+GET, DELETE          /api/v1/tenants/<tenant>/tenant-targets/<target>/pods/<pod>
+GET                  /api/v1/tenants/<tenant>/tenant-targets/<target>/pods/<pod>/logs?tailLines=<lines>
+GET, POST            /api/v1/tenants/<tenant>/tenant-targets/<target>/secrets
+GET, DELETE          /api/v1/tenants/<tenant>/tenant-targets/<target>/secrets/<secret>
+
+Objects are returned in the same format as the json output of the CLI. Append ?wait=false to return as soon as
+the cluster accepted a request.`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		if len(args) != 0 {
+			tools.HandleError(tools.CreateWrongNumberArgumentsError(), cmd)
+		}
+
+		port, _ := cmd.Flags().GetInt("port")
+		certFile, _ := cmd.Flags().GetString("tls-cert-file")
+		keyFile, _ := cmd.Flags().GetString("tls-private-key-file")
+		if (certFile == "") != (keyFile == "") {
+			tools.HandleError(tools.NewError(tools.ERROR_KIND_USAGE, "Specify both --tls-cert-file and --tls-private-key-file."), cmd)
+		}
+
+		_, config, err := tools.GetUserClient(cmd)
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
+		logOpts, err := clusterOperations.LogOptionsFromCmd(cmd)
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
+		clusterOperations.WrapLogging(config, logOpts)
+
+		mux := http.NewServeMux()
+		mux.Handle(clusterOperations.API_PREFIX, clusterOperations.NewAPIHandler(config))
+		mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("ok"))
+		})
+		server := &http.Server{Addr: ":" + strconv.Itoa(port), Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+		//Stop serving gracefully, once the server is interrupted
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			_ = server.Shutdown(shutdownCtx)
+		}()
+
+		if certFile == "" {
+			fmt.Println("Warning: serving without TLS. Bearer tokens are sent in plain text, only use this behind a TLS-terminating proxy.")
+			fmt.Println("Serving the kufast API on port " + strconv.Itoa(port) + ". Press Ctrl+C to stop.")
+			err = server.ListenAndServe()
+		} else {
+			fmt.Println("Serving the kufast API on port " + strconv.Itoa(port) + ". Press Ctrl+C to stop.")
+			err = server.ListenAndServeTLS(certFile, keyFile)
+		}
+		if err != nil && err != http.ErrServerClosed {
+			tools.HandleError(err, cmd)
+		}
+	},
+}
+
+// init is a helper function from cobra to initialize the command. It sets all flags, standard values and documentation for this command.
+func init() {
+	RootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().IntP("port", "", 8080, "The port the API listens on.")
+	serveCmd.Flags().StringP("tls-cert-file", "", "", "The TLS certificate of the API. Without it, the API is served via plain HTTP.")
+	serveCmd.Flags().StringP("tls-private-key-file", "", "", "The private key of the TLS certificate.")
+	_ = serveCmd.MarkFlagFilename("tls-cert-file")
+	_ = serveCmd.MarkFlagFilename("tls-private-key-file")
+}
+
+func CreateServeDocs(linkH func(string) string) {
+	out, err := os.Create("./kufast.wiki/serve.md")
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		err := out.Close()
+		if err != nil {
+			panic(err)
+		}
+	}()
+
+	err = doc.GenMarkdownCustom(serveCmd, out, linkH)
+	if err != nil {
+		panic(err)
+	}
+
+}
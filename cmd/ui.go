@@ -0,0 +1,547 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+	"golang.org/x/term"
+	"io"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"kufast/clusterOperations"
+	"kufast/tools"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Escape sequences to control the terminal
+const (
+	uiEnterScreen = "\x1b[?1049h\x1b[?25l"
+	uiLeaveScreen = "\x1b[?25h\x1b[?1049l"
+	uiClearScreen = "\x1b[H\x1b[2J"
+	uiShowCursor  = "\x1b[?25h"
+	uiHideCursor  = "\x1b[?25l"
+	uiReverse     = "\x1b[7m"
+	uiBold        = "\x1b[1m"
+	uiReset       = "\x1b[0m"
+)
+
+// uiHelp lists the keybindings of the dashboard.
+const uiHelp = "↑/↓ select pod  tab/shift+tab switch tenant-target  l logs  e exec  d delete pod  r refresh  q quit"
+
+// uiCmd represents the ui command
+var uiCmd = &cobra.Command{
+	Use:   "ui",
+	Short: "Show an interactive dashboard of tenant-targets, pods and events.",
+	Long: `Show a full-screen dashboard of tenant-targets with their quota usage, the pods of the selected tenant-target
+with their status and restarts and its recent events. The dashboard refreshes itself and lets you tail logs, exec
+into pods, delete pods and switch between tenant-targets. Admins see the tenant-targets of all tenants, tenants see
+their own tenant-targets.
+
+Keys: ` + uiHelp,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		tenantName, _ := cmd.Flags().GetString("tenant")
+		refresh, _ := cmd.Flags().GetDuration("refresh")
+
+		if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+			tools.HandleError(tools.NewError(tools.ERROR_KIND_USAGE, "kufast ui needs an interactive terminal."), cmd)
+		}
+
+		//Log lines would break the dashboard, only a trace file is written
+		cmd.SetErr(io.Discard)
+		client, err := clusterOperations.NewClientFromCmd(cmd)
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
+
+		oldState, err := term.MakeRaw(int(os.Stdin.Fd()))
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
+		fmt.Print(uiEnterScreen)
+
+		ui := &dashboard{client: client, tenant: tenantName, keys: readKeys(os.Stdin), results: make(chan string)}
+		ui.selectTarget(client.GetTargetName())
+		ui.run(refresh)
+
+		fmt.Print(uiLeaveScreen)
+		_ = term.Restore(int(os.Stdin.Fd()), oldState)
+	},
+}
+
+// dashboard holds the state of the kufast ui.
+type dashboard struct {
+	client        *clusterOperations.Client
+	tenant        string
+	keys          <-chan string
+	results       chan string
+	tenantTargets []tools.TenantTargetView
+	pods          []tools.PodView
+	events        []tools.EventView
+	target        int
+	pod           int
+	status        string
+	confirm       func()
+}
+
+// run refreshes and renders the dashboard until the user quits.
+func (d *dashboard) run(refresh time.Duration) {
+	ticker := time.NewTicker(refresh)
+	defer ticker.Stop()
+
+	d.refresh()
+	for {
+		d.render()
+		select {
+		case <-ticker.C:
+			d.refresh()
+		case result := <-d.results:
+			d.status = result
+			d.refresh()
+		case key, ok := <-d.keys:
+			if !ok || !d.handleKey(key) {
+				return
+			}
+		}
+	}
+}
+
+// handleKey executes the action bound to a key. It returns false, if the user quits.
+func (d *dashboard) handleKey(key string) bool {
+	if d.confirm != nil {
+		if key == "y" {
+			d.confirm()
+		} else {
+			d.status = "Cancelled."
+		}
+		d.confirm = nil
+		return true
+	}
+
+	switch key {
+	case "q", "\x03":
+		return false
+	case "j", "\x1b[B":
+		d.pod = clampIndex(d.pod+1, len(d.pods))
+	case "k", "\x1b[A":
+		d.pod = clampIndex(d.pod-1, len(d.pods))
+	case "\t":
+		d.target = (d.target + 1) % maxInt(len(d.tenantTargets), 1)
+		d.pod = 0
+		d.refresh()
+	case "\x1b[Z":
+		d.target = (d.target - 1 + maxInt(len(d.tenantTargets), 1)) % maxInt(len(d.tenantTargets), 1)
+		d.pod = 0
+		d.refresh()
+	case "r":
+		d.refresh()
+	case "l":
+		d.showLogs()
+	case "e":
+		d.execInPod()
+	case "d":
+		if pod, scope, ok := d.selectedPod(); ok {
+			d.status = "Delete pod " + pod.Name + " including its storage? (y/N)"
+			d.confirm = func() {
+				d.status = "Deleting pod " + pod.Name + ".."
+				go func() {
+					if err := <-d.client.DeletePod(pod.Name, scope); err != nil {
+						d.results <- err.Error()
+						return
+					}
+					d.results <- "Deleted pod " + pod.Name + "."
+				}()
+			}
+		}
+	}
+	return true
+}
+
+// refresh reads the tenant-targets and the pods and events of the selected tenant-target from the cluster.
+func (d *dashboard) refresh() {
+	tenantTargets, err := d.client.ListVisibleTenantTargets(d.tenant)
+	if err != nil {
+		d.status = err.Error()
+		return
+	}
+	d.tenantTargets = tenantTargets
+	d.target = clampIndex(d.target, len(d.tenantTargets))
+	d.pods, d.events = nil, nil
+	if len(d.tenantTargets) == 0 {
+		return
+	}
+
+	tenantTarget := d.tenantTargets[d.target]
+	pods, err := d.client.ListTenantTargetPods(tenantTarget.Tenant, tenantTarget.Target)
+	if err != nil {
+		d.status = err.Error()
+		return
+	}
+	for _, pod := range pods {
+		d.pods = append(d.pods, tools.NewPodView(&pod, nil))
+	}
+	d.pod = clampIndex(d.pod, len(d.pods))
+
+	events, err := d.client.ListTenantTargetEvents(tenantTarget.Tenant, tenantTarget.Target, 10)
+	if err != nil {
+		d.status = err.Error()
+		return
+	}
+	for _, event := range events {
+		eventTime := event.LastTimestamp.Time
+		if eventTime.IsZero() {
+			eventTime = event.CreationTimestamp.Time
+		}
+		d.events = append(d.events, tools.EventView{Time: eventTime, Reason: event.Reason,
+			Message: event.InvolvedObject.Name + ": " + event.Message})
+	}
+}
+
+// selectTarget selects the tenant-target of the given target, if the tenant has access to it.
+func (d *dashboard) selectTarget(targetName string) {
+	tenantTargets, err := d.client.ListVisibleTenantTargets(d.tenant)
+	if err != nil {
+		return
+	}
+	for i, tenantTarget := range tenantTargets {
+		if tenantTarget.Target == targetName {
+			d.target = i
+			return
+		}
+	}
+}
+
+// selectedPod returns the selected pod along with the scope of its tenant-target.
+func (d *dashboard) selectedPod() (tools.PodView, clusterOperations.ScopeOptions, bool) {
+	if len(d.pods) == 0 {
+		d.status = "No pod selected."
+		return tools.PodView{}, clusterOperations.ScopeOptions{}, false
+	}
+	tenantTarget := d.tenantTargets[d.target]
+	return d.pods[d.pod], clusterOperations.ScopeOptions{Tenant: tenantTarget.Tenant, Target: tenantTarget.Target}, true
+}
+
+// render draws the dashboard to the terminal.
+func (d *dashboard) render() {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		width, height = 120, 40
+	}
+
+	var lines []string
+	title := "kufast ui"
+	if len(d.tenantTargets) > 0 {
+		title += " - " + d.tenantTargets[d.target].Name
+	}
+	lines = append(lines, uiBold+title+uiReset, "")
+
+	lines = append(lines, uiBold+"TENANT-TARGETS"+uiReset)
+	for i, tenantTarget := range d.tenantTargets {
+		line := fmt.Sprintf("%-24s %-12s cpu %s/%s  memory %s/%s  storage %s/%s  pods %d/%s", tenantTarget.Name,
+			tenantTarget.Status, usage(tenantTarget.Used.CPU), tenantTarget.Limits.CPU, usage(tenantTarget.Used.Memory),
+			tenantTarget.Limits.Memory, usage(tenantTarget.Used.Storage), tenantTarget.Limits.Storage, tenantTarget.Pods,
+			tenantTarget.Limits.Pods)
+		lines = append(lines, highlight(line, i == d.target, width))
+	}
+	if len(d.tenantTargets) == 0 {
+		lines = append(lines, "No tenant-targets found.")
+	}
+
+	lines = append(lines, "", uiBold+"PODS"+uiReset)
+	for i, pod := range d.pods {
+		line := fmt.Sprintf("%-30s %-10s restarts %-4d %-16s %s", pod.Name, pod.Status, pod.Restarts, pod.Node, pod.Message)
+		lines = append(lines, highlight(line, i == d.pod, width))
+	}
+	if len(d.pods) == 0 {
+		lines = append(lines, "No pods found.")
+	}
+
+	lines = append(lines, "", uiBold+"EVENTS"+uiReset)
+	for _, event := range d.events {
+		age := "-"
+		if !event.Time.IsZero() {
+			age = duration.HumanDuration(time.Since(event.Time))
+		}
+		lines = append(lines, truncate(fmt.Sprintf("%-6s %-20s %s", age, event.Reason, event.Message), width))
+	}
+
+	//Keep the status and help at the bottom of the screen
+	if len(lines) > height-2 {
+		lines = lines[:maxInt(height-2, 0)]
+	}
+	for len(lines) < height-2 {
+		lines = append(lines, "")
+	}
+	lines = append(lines, truncate(d.status, width), uiReverse+truncate(uiHelp, width)+uiReset)
+
+	fmt.Print(uiClearScreen + strings.Join(lines, "\r\n"))
+}
+
+// showLogs follows the logs of the selected pod until the user presses q.
+func (d *dashboard) showLogs() {
+	pod, scope, ok := d.selectedPod()
+	if !ok {
+		return
+	}
+
+	stream, err := d.client.GetPodLogs(pod.Name, scope, 100, true)
+	if err != nil {
+		d.status = err.Error()
+		return
+	}
+	fmt.Print(uiClearScreen + uiReverse + "Logs of pod " + pod.Name + ", press q to return" + uiReset + "\r\n")
+
+	done := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(crlfWriter{os.Stdout}, stream)
+		close(done)
+	}()
+	for key := range d.keys {
+		if key == "q" || key == "\x03" {
+			break
+		}
+	}
+	_ = stream.Close()
+	<-done
+}
+
+// execInPod starts a shell in the selected pod. Input is forwarded from the keys of the dashboard.
+func (d *dashboard) execInPod() {
+	pod, scope, ok := d.selectedPod()
+	if !ok {
+		return
+	}
+	fmt.Print(uiClearScreen + uiShowCursor)
+
+	reader, writer := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case key, ok := <-d.keys:
+				if !ok {
+					return
+				}
+				_, _ = writer.Write([]byte(key))
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err := d.client.ExecInPod(pod.Name, "sh", scope, reader, os.Stdout, os.Stderr)
+	close(done)
+	_ = writer.Close()
+	fmt.Print(uiHideCursor)
+	if err != nil {
+		d.status = err.Error()
+	}
+}
+
+// escapeTimeout is how long readKeys waits for the rest of an incomplete key, before the escape key is assumed.
+const escapeTimeout = 50 * time.Millisecond
+
+// readKeys reads the keys pressed by the user. A read can contain several keys, e.g. when text is pasted, so it is
+// split into single characters and escape sequences. Incomplete sequences are completed by the next read.
+func readKeys(in io.Reader) <-chan string {
+	reads := make(chan []byte)
+	go func() {
+		defer close(reads)
+		buf := make([]byte, 64)
+		for {
+			n, err := in.Read(buf)
+			if err != nil {
+				return
+			}
+			reads <- append([]byte{}, buf[:n]...)
+		}
+	}()
+
+	keys := make(chan string)
+	go func() {
+		defer close(keys)
+		var pending []byte
+		for {
+			var timeout <-chan time.Time
+			if len(pending) > 0 {
+				timeout = time.After(escapeTimeout)
+			}
+			select {
+			case data, ok := <-reads:
+				if !ok {
+					return
+				}
+				var split []string
+				split, pending = splitKeys(append(pending, data...))
+				for _, key := range split {
+					keys <- key
+				}
+			case <-timeout:
+				//No sequence follows, e.g. the escape key itself
+				keys <- string(pending)
+				pending = nil
+			}
+		}
+	}()
+	return keys
+}
+
+// splitKeys splits the input of a terminal into keys and returns the incomplete rest. Control sequences like
+// "\x1b[A" and "\x1bOP" and characters with alt ("\x1bx") are a single key.
+func splitKeys(input []byte) ([]string, []byte) {
+	var keys []string
+	for len(input) > 0 {
+		length := keyLength(input)
+		if length == 0 {
+			break
+		}
+		keys = append(keys, string(input[:length]))
+		input = input[length:]
+	}
+	return keys, input
+}
+
+// keyLength returns the length of the first key of the input or 0, if it is not complete yet.
+func keyLength(input []byte) int {
+	if input[0] != '\x1b' {
+		if !utf8.FullRune(input) {
+			return 0
+		}
+		_, size := utf8.DecodeRune(input)
+		return size
+	}
+	if len(input) == 1 {
+		return 0
+	}
+	switch input[1] {
+	case '[':
+		//Control sequence: parameter and intermediate bytes up to a final byte
+		for i := 2; i < len(input); i++ {
+			if input[i] >= 0x40 && input[i] <= 0x7e {
+				return i + 1
+			}
+			if input[i] < 0x20 || input[i] > 0x3f {
+				return i
+			}
+		}
+		return 0
+	case 'O':
+		if len(input) < 3 {
+			return 0
+		}
+		return 3
+	}
+	if length := keyLength(input[1:]); length > 0 {
+		return 1 + length
+	}
+	return 0
+}
+
+// crlfWriter writes to a terminal in raw mode, which needs a carriage return before each new line.
+type crlfWriter struct {
+	out io.Writer
+}
+
+func (w crlfWriter) Write(p []byte) (int, error) {
+	_, err := w.out.Write([]byte(strings.ReplaceAll(string(p), "\n", "\r\n")))
+	return len(p), err
+}
+
+// highlight truncates a line to the width of the terminal and shows selected lines in reverse video.
+func highlight(line string, selected bool, width int) string {
+	line = truncate(line, width)
+	if selected {
+		return uiReverse + line + uiReset
+	}
+	return line
+}
+
+// truncate shortens a line to the width of the terminal.
+func truncate(line string, width int) string {
+	runes := []rune(line)
+	if len(runes) > width {
+		return string(runes[:width])
+	}
+	return line
+}
+
+// usage returns the usage of a resource or 0, if nothing has been used yet.
+func usage(used string) string {
+	if used == "" {
+		return "0"
+	}
+	return used
+}
+
+// clampIndex keeps a selection within a list of the given length.
+func clampIndex(index int, length int) int {
+	if index >= length {
+		index = length - 1
+	}
+	if index < 0 {
+		index = 0
+	}
+	return index
+}
+
+// maxInt returns the larger of two numbers.
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// init is a helper function from cobra to initialize the command. It sets all flags, standard values and documentation for this command.
+func init() {
+	RootCmd.AddCommand(uiCmd)
+
+	uiCmd.Flags().StringP("tenant", "", "", "Only show the tenant-targets of this tenant. Admins see all tenants by default.")
+	uiCmd.Flags().DurationP("refresh", "", 5*time.Second, "The interval to refresh the dashboard.")
+
+}
+
+func CreateUiDocs(linkH func(string) string) {
+	out, err := os.Create("./kufast.wiki/ui.md")
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		err := out.Close()
+		if err != nil {
+			panic(err)
+		}
+	}()
+
+	err = doc.GenMarkdownCustom(uiCmd, out, linkH)
+	if err != nil {
+		panic(err)
+	}
+
+}
@@ -0,0 +1,85 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package cmd
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+func TestSplitKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		keys  []string
+		rest  string
+	}{
+		{name: "pasted text", input: "jk q", keys: []string{"j", "k", " ", "q"}},
+		{name: "arrow keys", input: "\x1b[A\x1b[Bj", keys: []string{"\x1b[A", "\x1b[B", "j"}},
+		{name: "shift tab and parameters", input: "\x1b[Z\x1b[1;5C", keys: []string{"\x1b[Z", "\x1b[1;5C"}},
+		{name: "function key", input: "\x1bOPr", keys: []string{"\x1bOP", "r"}},
+		{name: "alt", input: "\x1bx", keys: []string{"\x1bx"}},
+		{name: "escape", input: "q\x1b", keys: []string{"q"}, rest: "\x1b"},
+		{name: "unicode", input: "äy", keys: []string{"ä", "y"}},
+		{name: "incomplete sequence", input: "j\x1b[1;", keys: []string{"j"}, rest: "\x1b[1;"},
+		{name: "incomplete rune", input: "j\xc3", keys: []string{"j"}, rest: "\xc3"},
+	}
+	for _, test := range tests {
+		keys, rest := splitKeys([]byte(test.input))
+		if !reflect.DeepEqual(keys, test.keys) || string(rest) != test.rest {
+			t.Errorf("%s: expected %q and rest %q, got %q and rest %q", test.name, test.keys, test.rest, keys, rest)
+		}
+	}
+}
+
+func TestReadKeysCompletesSequences(t *testing.T) {
+	var keys []string
+	for key := range readKeys(iotest.OneByteReader(strings.NewReader("j\x1b[Bä\x03"))) {
+		keys = append(keys, key)
+	}
+	expected := []string{"j", "\x1b[B", "ä", "\x03"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected %q, got %q", expected, keys)
+	}
+}
+
+func TestReadKeysEscape(t *testing.T) {
+	reader, writer := io.Pipe()
+	defer writer.Close()
+	keys := readKeys(reader)
+
+	_, _ = writer.Write([]byte("\x1b"))
+	select {
+	case key := <-keys:
+		if key != "\x1b" {
+			t.Errorf("expected the escape key, got %q", key)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the escape key after a timeout")
+	}
+}
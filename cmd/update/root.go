@@ -26,6 +26,7 @@ package update
 import (
 	"github.com/spf13/cobra/doc"
 	"kufast/cmd"
+	"kufast/tools"
 	"log"
 	"os"
 
@@ -44,6 +45,9 @@ Use these features to update tenants and more.`,
 func init() {
 	cmd.RootCmd.AddCommand(updateCmd)
 
+	//Enables dry runs for all commands in update.
+	tools.AddDryRunFlags(updateCmd)
+
 }
 
 func CreateUpdateDocs(fileP func(string) string, linkH func(string) string) {
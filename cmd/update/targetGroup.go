@@ -24,10 +24,10 @@ SOFTWARE.
 package update
 
 import (
-	"errors"
 	"fmt"
 	"github.com/spf13/cobra"
 	"kufast/clusterOperations"
+	"kufast/cmd"
 	"kufast/tools"
 )
 
@@ -37,19 +37,40 @@ var updateTargetGroupCmd = &cobra.Command{
 	Short: "Update the nodes on an existing target group.",
 	Long: `Update the nodes on an existing target group. Specify all nodes that should be in the group after the reassignment. 
  Already existing pods on nodes will not be affected of this change.`,
+	ValidArgsFunction: cmd.SingleArg(cmd.CompleteTargetGroups),
 	Run: func(cmd *cobra.Command, args []string) {
 
 		if len(args) < 2 {
-			tools.HandleError(errors.New(tools.ERROR_WRONG_NUMBER_ARGUMENTS), cmd)
+			tools.HandleError(tools.CreateWrongNumberArgumentsError(), cmd)
 		}
 
 		s := tools.CreateStandardSpinner(tools.MESSAGE_UPDATE_OBJECTS)
 
-		if clusterOperations.IsValidTarget(cmd, args[0], true) {
-			err := clusterOperations.SetTargetGroupToNodes(args[0], args[:1], cmd)
-			if err != nil {
-				tools.HandleError(err, cmd)
-			}
+		client, err := clusterOperations.NewClientFromCmd(cmd)
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+
+		groups, err := client.ListTargetGroups()
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+		if _, ok := groups[args[0]]; !ok {
+			s.Stop()
+			tools.HandleError(tools.NewError(tools.ERROR_KIND_NOT_FOUND, "Not a valid target-group: "+args[0]), cmd)
+		}
+
+		useCRDs, _ := cmd.Flags().GetBool("use-crds")
+		if useCRDs {
+			err = client.SetTargetGroupResource(args[0], args[1:])
+		} else {
+			err = client.SetTargetGroupToNodes(args[0], args[1:])
+		}
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
 		}
 
 		s.Stop()
@@ -62,4 +83,6 @@ var updateTargetGroupCmd = &cobra.Command{
 func init() {
 	updateCmd.AddCommand(updateTargetGroupCmd)
 
+	updateTargetGroupCmd.Flags().BoolP("use-crds", "", false, "Update the TargetGroup custom resource, which is reconciled by the kufast controller.")
+
 }
@@ -0,0 +1,110 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package update
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"kufast/clusterOperations"
+	"kufast/cmd"
+	"kufast/tools"
+	"time"
+)
+
+// updateTenantCredsCmd represents the update tenant-creds command
+var updateTenantCredsCmd = &cobra.Command{
+	Use:   "tenant-creds <tenant>",
+	Short: "Rotate the credentials of a tenant.",
+	Long: `Rotate the credentials of a tenant. All existing credentials of the tenant are revoked and new credentials
+are written to the folder specified by --output-dir. Can only be used by admins.`,
+	ValidArgsFunction: cmd.SingleArg(cmd.CompleteTenants),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		if len(args) != 1 {
+			tools.HandleError(tools.CreateWrongNumberArgumentsError(), cmd)
+		}
+
+		rotate, _ := cmd.Flags().GetBool("rotate")
+		if !rotate {
+			tools.HandleError(tools.NewError(tools.ERROR_KIND_USAGE, "Nothing to update. Use --rotate to revoke the credentials of the tenant and issue new ones."), cmd)
+		}
+		outputDir, _ := cmd.Flags().GetString("output-dir")
+		duration, _ := cmd.Flags().GetDuration("duration")
+
+		//Ensure user knows what he does
+		confirmed, err := tools.ConfirmOperation(cmd, "All existing credentials of the following tenants will stop working:", []string{"tenant/" + args[0]})
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
+		if !confirmed {
+			return
+		}
+
+		s := tools.CreateStandardSpinner(tools.MESSAGE_UPDATE_OBJECTS)
+
+		client, err := clusterOperations.NewClientFromCmd(cmd)
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+
+		//No credentials can be issued during a dry run, so only their revocation is previewed
+		if client.IsDryRun() {
+			err = client.RevokeTenantCredentials(args[0])
+			if err != nil {
+				s.Stop()
+				tools.HandleError(err, cmd)
+			}
+			s.Stop()
+			fmt.Println(tools.MESSAGE_DONE)
+			return
+		}
+
+		config, err := client.RotateTenantCredentials(args[0], clusterOperations.CredentialOptions{Duration: duration})
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+
+		err = tools.WriteNewUserYamlToFile(args[0], config, outputDir, s)
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+
+		s.Stop()
+		fmt.Println(tools.MESSAGE_DONE)
+	},
+}
+
+// init is a helper function from cobra to initialize the command. It sets all flags, standard values and documentation for this command.
+func init() {
+	updateCmd.AddCommand(updateTenantCredsCmd)
+
+	updateTenantCredsCmd.Flags().BoolP("rotate", "", false, "Revoke all existing credentials of the tenant and issue new ones.")
+	updateTenantCredsCmd.Flags().StringP("output-dir", "d", ".", "Folder to store the new client credentials.")
+	_ = updateTenantCredsCmd.MarkFlagDirname("output-dir")
+	updateTenantCredsCmd.Flags().DurationP("duration", "", 8760*time.Hour, "Lifetime of the tenant credentials. Use 0 to issue long-lived credentials, which are valid until they are revoked.")
+
+}
@@ -24,28 +24,38 @@ SOFTWARE.
 package update
 
 import (
-	"errors"
 	"fmt"
 	"github.com/spf13/cobra"
 	"kufast/clusterOperations"
+	"kufast/cmd"
 	"kufast/tools"
 )
 
 // updateTenantDefaultCmd represents the update tenant-default command
 var updateTenantDefaultCmd = &cobra.Command{
-	Use:   "tenant-default <newDefault>",
-	Short: "Set a new default tenant-target for a tenant.",
-	Long:  `Set a new default tenant-target for a tenant. The target must be valid for this tenant.`,
+	Use:               "tenant-default <newDefault>",
+	Short:             "Set a new default tenant-target for a tenant.",
+	Long:              `Set a new default tenant-target for a tenant. The target must be valid for this tenant.`,
+	ValidArgsFunction: cmd.SingleArg(cmd.CompleteTenantTargets),
 	Run: func(cmd *cobra.Command, args []string) {
 
 		if len(args) != 1 {
-			tools.HandleError(errors.New(tools.ERROR_WRONG_NUMBER_ARGUMENTS), cmd)
+			tools.HandleError(tools.CreateWrongNumberArgumentsError(), cmd)
 		}
 
+		tenant, _ := cmd.Flags().GetString("tenant")
+
 		s := tools.CreateStandardSpinner(tools.MESSAGE_UPDATE_OBJECTS)
 
-		err := clusterOperations.UpdateTenantDefaultDeployTarget(args[0], cmd)
+		client, err := clusterOperations.NewClientFromCmd(cmd)
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+
+		err = client.UpdateTenantDefaultDeployTarget(args[0], tenant)
 		if err != nil {
+			s.Stop()
 			tools.HandleError(err, cmd)
 		}
 
@@ -0,0 +1,82 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package use
+
+import (
+	"fmt"
+	"github.com/spf13/cobra/doc"
+	"kufast/clusterOperations"
+	"kufast/cmd"
+	"kufast/tools"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// useCmd represents the use command. Without a subcommand, it shows the tenant and target of your credentials.
+var useCmd = &cobra.Command{
+	Use:   "use",
+	Short: "Show or switch the tenant-target of your credentials",
+	Long: `Show the tenant and target your commands work on, if no --tenant or --target is given. They are read from
+the namespace of the current context in your kubeconfig. Use the subcommands to switch them.`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		client, err := clusterOperations.NewClientFromCmd(cmd)
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
+
+		tenantName, err := client.GetTenantName("")
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
+		targetName := client.GetTargetName()
+		if targetName == "" {
+			targetName = "none"
+		}
+
+		fmt.Println("Tenant: " + tenantName)
+		fmt.Println("Target: " + targetName)
+	},
+}
+
+// init is a helper function from cobra to initialize the command. It sets all flags, standard values and documentation for this command.
+func init() {
+	cmd.RootCmd.AddCommand(useCmd)
+
+}
+
+func CreateUseDocs(fileP func(string) string, linkH func(string) string) {
+
+	err := os.MkdirAll("./kufast.wiki/use/", 0770)
+	if err != nil {
+		panic(err)
+	}
+
+	err = doc.GenMarkdownTreeCustom(useCmd, "./kufast.wiki/use/", fileP, linkH)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
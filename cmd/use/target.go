@@ -0,0 +1,81 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package use
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"kufast/clusterOperations"
+	"kufast/cmd"
+	"kufast/tools"
+)
+
+// useTargetCmd represents the use target command
+var useTargetCmd = &cobra.Command{
+	Use:   "target <target>",
+	Short: "Switch the tenant-target your commands work on.",
+	Long: `Switch the tenant-target your commands work on by changing the namespace of the current context in your
+kubeconfig. Only your kubeconfig is changed; the default target of the tenant set by the admins with
+'kufast update tenant-default' stays the same.`,
+	ValidArgsFunction: cmd.SingleArg(cmd.CompleteTenantTargets),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		if len(args) != 1 {
+			tools.HandleError(tools.CreateWrongNumberArgumentsError(), cmd)
+		}
+
+		s := tools.CreateStandardSpinner(tools.MESSAGE_UPDATE_OBJECTS)
+
+		client, err := clusterOperations.NewClientFromCmd(cmd)
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+
+		tenantName, err := client.GetTenantName("")
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+		if !client.IsValidTarget(args[0], tenantName, false) {
+			s.Stop()
+			tools.HandleError(tools.NewError(tools.ERROR_KIND_INVALID_TARGET, "Not a valid target for this tenant: "+args[0]), cmd)
+		}
+
+		err = tools.SetNamespaceInUserConfig(cmd, tenantName+"-"+args[0])
+		if err != nil {
+			s.Stop()
+			tools.HandleError(err, cmd)
+		}
+
+		s.Stop()
+		fmt.Println("Switched to tenant-target " + tenantName + "-" + args[0] + ".")
+	},
+}
+
+// init is a helper function from cobra to initialize the command. It sets all flags, standard values and documentation for this command.
+func init() {
+	useCmd.AddCommand(useTargetCmd)
+
+}
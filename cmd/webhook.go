@@ -0,0 +1,169 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+	"kufast/clusterOperations"
+	"kufast/objectFactory"
+	"kufast/tools"
+	"net/http"
+	"os"
+	"os/signal"
+	"sigs.k8s.io/yaml"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// webhookCmd represents the webhook command
+var webhookCmd = &cobra.Command{
+	Use:   "webhook",
+	Short: "Run the kufast admission webhook, which enforces the policies of tenant-targets.",
+	Long: `Run the kufast admission webhook, which enforces the policies of tenant-targets without the PodNodeSelector
+admission plugin. Pods in tenant-targets are rejected, if they escape the node selector of their tenant-target,
+omit cpu or memory limits, exceed the limits of their tenant-target or use an image not matching --allowed-image.
+Ephemeral containers cannot have limits and are rejected in tenant-targets as well.
+
+The webhook serves admission.k8s.io/v1 AdmissionReviews via TLS on /validate-pods. Run it behind a service and
+register it with the ValidatingWebhookConfiguration printed by --print-config:
+
+kufast webhook --print-config --service-name kufast-webhook --service-namespace kufast --ca-file ca.crt | kubectl apply -f -
+
+This operation can only be executed by a cluster admin.`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		if len(args) != 0 {
+			tools.HandleError(tools.CreateWrongNumberArgumentsError(), cmd)
+		}
+
+		printConfig, _ := cmd.Flags().GetBool("print-config")
+		if printConfig {
+			printWebhookConfiguration(cmd)
+			return
+		}
+
+		port, _ := cmd.Flags().GetInt("port")
+		certFile, _ := cmd.Flags().GetString("tls-cert-file")
+		keyFile, _ := cmd.Flags().GetString("tls-private-key-file")
+		allowedImages, _ := cmd.Flags().GetStringArray("allowed-image")
+		if certFile == "" || keyFile == "" {
+			tools.HandleError(tools.NewError(tools.ERROR_KIND_USAGE, "The webhook requires --tls-cert-file and --tls-private-key-file."), cmd)
+		}
+
+		client, err := clusterOperations.NewClientFromCmd(cmd)
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle("/validate-pods", client.NewAdmissionHandler(clusterOperations.PodPolicy{AllowedImages: allowedImages}))
+		mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("ok"))
+		})
+		server := &http.Server{Addr: ":" + strconv.Itoa(port), Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+		//Stop serving gracefully, once the webhook is interrupted
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			_ = server.Shutdown(shutdownCtx)
+		}()
+
+		fmt.Println("Serving the kufast admission webhook on port " + strconv.Itoa(port) + ". Press Ctrl+C to stop.")
+		err = server.ListenAndServeTLS(certFile, keyFile)
+		if err != nil && err != http.ErrServerClosed {
+			tools.HandleError(err, cmd)
+		}
+	},
+}
+
+// printWebhookConfiguration prints the ValidatingWebhookConfiguration registering the webhook in the cluster.
+func printWebhookConfiguration(cmd *cobra.Command) {
+	serviceName, _ := cmd.Flags().GetString("service-name")
+	serviceNamespace, _ := cmd.Flags().GetString("service-namespace")
+	servicePort, _ := cmd.Flags().GetInt32("service-port")
+	caFile, _ := cmd.Flags().GetString("ca-file")
+
+	var caBundle []byte
+	if caFile != "" {
+		var err error
+		caBundle, err = os.ReadFile(caFile)
+		if err != nil {
+			tools.HandleError(err, cmd)
+		}
+	}
+
+	config := objectFactory.NewValidatingWebhookConfiguration(serviceName, serviceNamespace, servicePort, caBundle)
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		tools.HandleError(err, cmd)
+	}
+	fmt.Fprint(cmd.OutOrStdout(), string(data))
+}
+
+// init is a helper function from cobra to initialize the command. It sets all flags, standard values and documentation for this command.
+func init() {
+	RootCmd.AddCommand(webhookCmd)
+
+	webhookCmd.Flags().IntP("port", "", 8443, "The port the webhook listens on.")
+	webhookCmd.Flags().StringP("tls-cert-file", "", "", "The TLS certificate of the webhook.")
+	webhookCmd.Flags().StringP("tls-private-key-file", "", "", "The private key of the TLS certificate.")
+	webhookCmd.Flags().StringArrayP("allowed-image", "", nil, "A prefix of images tenants may use, e.g. registry.example.com/. Can be specified multiple times. All images are allowed, if it is not set.")
+	_ = webhookCmd.MarkFlagFilename("tls-cert-file")
+	_ = webhookCmd.MarkFlagFilename("tls-private-key-file")
+
+	webhookCmd.Flags().BoolP("print-config", "", false, "Print the ValidatingWebhookConfiguration registering the webhook and exit.")
+	webhookCmd.Flags().StringP("service-name", "", "kufast-webhook", "The service in front of the webhook. Used by --print-config.")
+	webhookCmd.Flags().StringP("service-namespace", "", "default", "The namespace of the service in front of the webhook. Used by --print-config.")
+	webhookCmd.Flags().Int32P("service-port", "", 443, "The port of the service in front of the webhook. Used by --print-config.")
+	webhookCmd.Flags().StringP("ca-file", "", "", "The CA which signed the TLS certificate of the webhook. Used by --print-config.")
+	_ = webhookCmd.MarkFlagFilename("ca-file")
+}
+
+func CreateWebhookDocs(linkH func(string) string) {
+	out, err := os.Create("./kufast.wiki/webhook.md")
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		err := out.Close()
+		if err != nil {
+			panic(err)
+		}
+	}()
+
+	err = doc.GenMarkdownCustom(webhookCmd, out, linkH)
+	if err != nil {
+		panic(err)
+	}
+
+}
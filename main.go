@@ -6,11 +6,18 @@ import (
 	"path"
 	"strings"
 )
+import a "kufast/cmd/approve"
+import ch "kufast/cmd/check"
+import cf "kufast/cmd/config"
 import c "kufast/cmd/create"
 import d "kufast/cmd/delete"
+import dn "kufast/cmd/deny"
 import g "kufast/cmd/get"
 import l "kufast/cmd/list"
+import r "kufast/cmd/repair"
+import rq "kufast/cmd/request"
 import u "kufast/cmd/update"
+import us "kufast/cmd/use"
 
 func main() {
 	if len(os.Args) > 1 && os.Args[1] == "gen-docu" {
@@ -36,9 +43,21 @@ func generateMdDocs() {
 
 	cmd.CreateRootDocs(linkHandler)
 	cmd.CreateExecDocs(linkHandler)
+	cmd.CreateApplyDocs(linkHandler)
+	cmd.CreateControllerDocs(linkHandler)
+	cmd.CreateWebhookDocs(linkHandler)
+	cmd.CreateServeDocs(linkHandler)
+	cmd.CreateUiDocs(linkHandler)
 	c.CreateCreateDocs(filePrepander, linkHandler)
 	d.CreateDeleteDocs(filePrepander, linkHandler)
 	g.CreateGetDocs(filePrepander, linkHandler)
 	l.CreateListDocs(filePrepander, linkHandler)
 	u.CreateUpdateDocs(filePrepander, linkHandler)
+	ch.CreateCheckDocs(filePrepander, linkHandler)
+	r.CreateRepairDocs(filePrepander, linkHandler)
+	rq.CreateRequestDocs(filePrepander, linkHandler)
+	a.CreateApproveDocs(filePrepander, linkHandler)
+	dn.CreateDenyDocs(filePrepander, linkHandler)
+	cf.CreateConfigDocs(filePrepander, linkHandler)
+	us.CreateUseDocs(filePrepander, linkHandler)
 }
@@ -0,0 +1,203 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package objectFactory
+
+import (
+	"embed"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"kufast/tools"
+	"sigs.k8s.io/yaml"
+)
+
+// crds contains the CustomResourceDefinitions of all kufast custom resources.
+//
+//go:embed crds/*.yaml
+var crds embed.FS
+
+// TenantResource is the resource of the Tenant custom resource.
+var TenantResource = schema.GroupVersionResource{Group: tools.KUFAST_API_GROUP, Version: tools.KUFAST_API_VERSION, Resource: "tenants"}
+
+// TenantTargetResource is the resource of the TenantTarget custom resource.
+var TenantTargetResource = schema.GroupVersionResource{Group: tools.KUFAST_API_GROUP, Version: tools.KUFAST_API_VERSION, Resource: "tenanttargets"}
+
+// TargetGroupResource is the resource of the TargetGroup custom resource.
+var TargetGroupResource = schema.GroupVersionResource{Group: tools.KUFAST_API_GROUP, Version: tools.KUFAST_API_VERSION, Resource: "targetgroups"}
+
+// CustomResourceDefinitionResource is the resource of CustomResourceDefinitions, which are used to install the
+// kufast custom resources.
+var CustomResourceDefinitionResource = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+// ResourceStatus is the status the kufast controller reports for all custom resources.
+type ResourceStatus struct {
+	Phase              string `json:"phase,omitempty"`
+	Message            string `json:"message,omitempty"`
+	ObservedGeneration int64  `json:"observedGeneration,omitempty"`
+}
+
+// Tenant is the custom resource of a tenant. Its name is the name of the tenant.
+type Tenant struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              TenantSpec     `json:"spec"`
+	Status            ResourceStatus `json:"status,omitempty"`
+}
+
+// TenantSpec is the desired state of a tenant.
+type TenantSpec struct {
+	DefaultTarget string `json:"defaultTarget,omitempty"`
+}
+
+// TenantTarget is the custom resource of a tenant-target. Its name is the name of the tenant-target namespace.
+type TenantTarget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              TenantTargetSpec `json:"spec"`
+	Status            ResourceStatus   `json:"status,omitempty"`
+}
+
+// TenantTargetSpec is the desired state of a tenant-target.
+type TenantTargetSpec struct {
+	Tenant string             `json:"tenant"`
+	Target string             `json:"target"`
+	Limits TenantTargetLimits `json:"limits,omitempty"`
+}
+
+// TenantTargetLimits are the limits of a tenant-target. Empty limits are not enforced.
+type TenantTargetLimits struct {
+	Memory     string `json:"memory,omitempty"`
+	CPU        string `json:"cpu,omitempty"`
+	Storage    string `json:"storage,omitempty"`
+	MinStorage string `json:"minStorage,omitempty"`
+	Pods       string `json:"pods,omitempty"`
+}
+
+// TargetGroup is the custom resource of a target-group. Its name is the name of the target-group.
+type TargetGroup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              TargetGroupSpec `json:"spec"`
+	Status            ResourceStatus  `json:"status,omitempty"`
+}
+
+// TargetGroupSpec is the desired state of a target-group.
+type TargetGroupSpec struct {
+	Nodes []string `json:"nodes"`
+}
+
+// NewTenantResource creates a new Tenant custom resource.
+// Created objects only exist locally and need to be deployed to the cluster.
+func NewTenantResource(tenantName string, defaultTarget string) *Tenant {
+	return &Tenant{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Tenant",
+			APIVersion: TenantResource.GroupVersion().String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: tenantName,
+		},
+		Spec: TenantSpec{
+			DefaultTarget: defaultTarget,
+		},
+	}
+}
+
+// NewTenantTargetResource creates a new TenantTarget custom resource based on several parameters.
+// Created objects only exist locally and need to be deployed to the cluster.
+func NewTenantTargetResource(tenantName string, targetName string, limits TenantTargetLimits) *TenantTarget {
+	return &TenantTarget{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "TenantTarget",
+			APIVersion: TenantTargetResource.GroupVersion().String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: tenantName + "-" + targetName,
+			Labels: map[string]string{
+				tools.KUFAST_TENANT_LABEL: tenantName,
+			},
+		},
+		Spec: TenantTargetSpec{
+			Tenant: tenantName,
+			Target: targetName,
+			Limits: limits,
+		},
+	}
+}
+
+// NewTargetGroupResource creates a new TargetGroup custom resource.
+// Created objects only exist locally and need to be deployed to the cluster.
+func NewTargetGroupResource(targetName string, nodes []string) *TargetGroup {
+	return &TargetGroup{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "TargetGroup",
+			APIVersion: TargetGroupResource.GroupVersion().String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: targetName,
+		},
+		Spec: TargetGroupSpec{
+			Nodes: nodes,
+		},
+	}
+}
+
+// NewCustomResourceDefinitions returns the CustomResourceDefinitions of all kufast custom resources.
+// Created objects only exist locally and need to be deployed to the cluster.
+func NewCustomResourceDefinitions() ([]*unstructured.Unstructured, error) {
+	files, err := crds.ReadDir("crds")
+	if err != nil {
+		return nil, err
+	}
+
+	var definitions []*unstructured.Unstructured
+	for _, file := range files {
+		data, err := crds.ReadFile("crds/" + file.Name())
+		if err != nil {
+			return nil, err
+		}
+		definition := &unstructured.Unstructured{}
+		err = yaml.Unmarshal(data, &definition.Object)
+		if err != nil {
+			return nil, err
+		}
+		definitions = append(definitions, definition)
+	}
+	return definitions, nil
+}
+
+// ToUnstructured converts a custom resource into an unstructured object, which can be deployed with a dynamic client.
+func ToUnstructured(resource interface{}) (*unstructured.Unstructured, error) {
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(resource)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: object}, nil
+}
+
+// FromUnstructured converts an unstructured object read with a dynamic client into a custom resource.
+func FromUnstructured(object *unstructured.Unstructured, resource interface{}) error {
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(object.UnstructuredContent(), resource)
+}
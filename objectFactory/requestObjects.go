@@ -0,0 +1,150 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package objectFactory
+
+import (
+	v1 "k8s.io/api/core/v1"
+	v12 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kufast/tools"
+)
+
+// The states of a request for a tenant-target.
+const (
+	RequestStatePending  = "pending"
+	RequestStateApproved = "approved"
+	RequestStateDenied   = "denied"
+)
+
+// RequestNamespace returns the name of the namespace storing the requests of a tenant for tenant-targets. Only the
+// tenant and admins may create requests in it, so the namespace identifies the tenant, which filed a request.
+func RequestNamespace(tenantName string) string {
+	return "kufast-requests-" + tenantName
+}
+
+// NewRequestNamespace creates a new Kubernetes namespace object for the requests of a tenant.
+// Created objects only exist locally and need to be deployed to the cluster.
+func NewRequestNamespace(tenantName string) *v1.Namespace {
+	return &v1.Namespace{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Namespace",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: RequestNamespace(tenantName),
+			Labels: map[string]string{
+				tools.KUFAST_TENANT_LABEL:  tenantName,
+				tools.KUFAST_REQUEST_LABEL: "true",
+			},
+		},
+	}
+}
+
+// NewTenantRequestRole creates a new Kubernetes Role object, which allows a tenant to file requests for
+// tenant-targets and to read them. Tenants may not update their requests, so only admins can approve or deny them.
+// Created objects only exist locally and need to be deployed to the cluster.
+func NewTenantRequestRole(tenantName string) *v12.Role {
+	return &v12.Role{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Role",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      tenantName + "-requestrole",
+			Namespace: RequestNamespace(tenantName),
+			Labels: map[string]string{
+				tools.KUFAST_TENANT_LABEL: tenantName,
+			},
+		},
+		Rules: []v12.PolicyRule{
+			{
+				Verbs:     []string{"get", "list", "watch", "create"},
+				APIGroups: []string{""},
+				Resources: []string{"configmaps"},
+			},
+		},
+	}
+}
+
+// NewTenantRequestRoleBinding creates a new Kubernetes RoleBinding object, which binds the request role to the user
+// of a tenant.
+// Created objects only exist locally and need to be deployed to the cluster.
+func NewTenantRequestRoleBinding(tenantName string) *v12.RoleBinding {
+	return &v12.RoleBinding{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "RoleBinding",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      tenantName + "-requestrolebinding",
+			Namespace: RequestNamespace(tenantName),
+			Labels: map[string]string{
+				tools.KUFAST_TENANT_LABEL: tenantName,
+			},
+		},
+		Subjects: []v12.Subject{
+			{
+				Kind:      "ServiceAccount",
+				Name:      tenantName + "-user",
+				Namespace: "default",
+			},
+		},
+		RoleRef: v12.RoleRef{
+			APIGroup: "rbac.authorization.k8s.io",
+			Kind:     "Role",
+			Name:     tenantName + "-requestrole",
+		},
+	}
+}
+
+// NewTenantTargetRequest creates a new Kubernetes ConfigMap storing the request of a tenant for a tenant-target
+// based on several parameters. Requests are stored in the request namespace of the tenant.
+// Created objects only exist locally and need to be deployed to the cluster.
+func NewTenantTargetRequest(requestID string, tenantName string, targetName string, ram string, cpu string, storage string, minStorage string, pods string) *v1.ConfigMap {
+	return &v1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kufast-request-" + requestID,
+			Namespace: RequestNamespace(tenantName),
+			Labels: map[string]string{
+				tools.KUFAST_REQUEST_LABEL:       "true",
+				tools.KUFAST_REQUEST_STATE_LABEL: RequestStatePending,
+				tools.KUFAST_TENANT_LABEL:        tenantName,
+			},
+		},
+		Data: map[string]string{
+			"id":         requestID,
+			"tenant":     tenantName,
+			"target":     targetName,
+			"memory":     ram,
+			"cpu":        cpu,
+			"storage":    storage,
+			"minStorage": minStorage,
+			"pods":       pods,
+		},
+	}
+}
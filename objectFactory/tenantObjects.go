@@ -24,7 +24,6 @@ SOFTWARE.
 package objectFactory
 
 import (
-	"github.com/spf13/cobra"
 	v1 "k8s.io/api/core/v1"
 	n1 "k8s.io/api/networking/v1"
 	v12 "k8s.io/api/rbac/v1"
@@ -35,7 +34,7 @@ import (
 
 // NewNamespace creates a new Kubernetes namespace object based on several parameters.
 // Created objects only exist locally and need to be deployed to the cluster.
-func NewNamespace(tenantName string, target tools.Target, cmd *cobra.Command) *v1.Namespace {
+func NewNamespace(tenantName string, target tools.Target) *v1.Namespace {
 	var newNamespace *v1.Namespace
 	newNamespace = &v1.Namespace{
 		TypeMeta: metav1.TypeMeta{
@@ -53,14 +52,20 @@ func NewNamespace(tenantName string, target tools.Target, cmd *cobra.Command) *v
 		Status: v1.NamespaceStatus{},
 	}
 
-	if target.AccessType == "node" {
-		newNamespace.ObjectMeta.Annotations["scheduler.alpha.kubernetes.io/node-selector"] = tools.KUFAST_NODE_HOSTNAME_LABEL + "=" + target.Name
-	} else {
-		newNamespace.ObjectMeta.Annotations["scheduler.alpha.kubernetes.io/node-selector"] = tools.KUFAST_NODE_GROUP_LABEL + target.Name + "=true"
+	for key, value := range NewNodeSelector(target) {
+		newNamespace.ObjectMeta.Annotations[tools.KUFAST_NAMESPACE_NODE_SELECTOR_ANNOTATION] = key + "=" + value
 	}
 	return newNamespace
 }
 
+// NewNodeSelector creates the node selector restricting pods to the nodes of a target.
+func NewNodeSelector(target tools.Target) map[string]string {
+	if target.AccessType == "node" {
+		return map[string]string{tools.KUFAST_NODE_HOSTNAME_LABEL: target.Name}
+	}
+	return map[string]string{tools.KUFAST_NODE_GROUP_LABEL + target.Name: "true"}
+}
+
 // NewLimitRange creates a new Kubernetes LimitRange object based on several parameters.
 // Created objects only exist locally and need to be deployed to the cluster.
 func NewLimitRange(namespaceName string, minStorage string, storage string) *v1.LimitRange {
@@ -176,6 +181,29 @@ func NewTenantUser(tenant string, namespaceName string) *v1.ServiceAccount {
 
 }
 
+// NewTenantTokenSecret creates a new Kubernetes secret object, which holds a long-lived token of the tenant user.
+// The token is populated by the cluster after the secret has been deployed.
+// Created objects only exist locally and need to be deployed to the cluster.
+func NewTenantTokenSecret(tenant string, namespaceName string) *v1.Secret {
+	return &v1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      tenant + "-user-token",
+			Namespace: namespaceName,
+			Labels: map[string]string{
+				tools.KUFAST_TENANT_LABEL: tenant,
+			},
+			Annotations: map[string]string{
+				v1.ServiceAccountNameKey: tenant + "-user",
+			},
+		},
+		Type: v1.SecretTypeServiceAccountToken,
+	}
+}
+
 // NewRole creates a new Kubernetes Role object based on several parameters.
 // This role object is optimized for tenant targets.
 // Created objects only exist locally and need to be deployed to the cluster.
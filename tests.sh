@@ -32,24 +32,24 @@ test_create_tenant() {
 test_create_nginx_pod() {
     echo "Test: Creating Nginx Pod"
     ./kufast create pod mypod nginx
-    echo "yes" | ./kufast delete pod mypod
+    ./kufast delete pod mypod --yes
 }
 
 test_delete_tenant() {
     echo "Test: Deleting tenant $tenantName"
-    echo "yes" | ./kufast delete tenant $tenantName
+    ./kufast delete tenant $tenantName --yes
 }
 
 test_create_and_delete_secret() {
     echo "Test: Creating and Deleting Secret"
     echo "password" | ./kufast create secret credentials
-    echo "yes" |  ./kufast delete secret credentials
+    ./kufast delete secret credentials --yes
 }
 
 test_create_and_delete_target_group() {
     echo "Test: Creating and Deleting Target Group"
     ./kufast create target-group $targetGroup $targetNode
-    echo "yes" | ./kufast delete target-group $targetGroup $targetNode
+    ./kufast delete target-group $targetGroup $targetNode --yes
 }
 
 # Main execution
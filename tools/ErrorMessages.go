@@ -23,13 +23,20 @@ SOFTWARE.
 */
 package tools
 
-import "errors"
-
 // ERROR_WRONG_NUMBER_ARGUMENTS returns the error message if the wrong number of arguments have been provided
 const ERROR_WRONG_NUMBER_ARGUMENTS = "Error: You did not provide a valid amount of arguments."
 
+// ERROR_NO_TERMINAL returns the error message if a destructive operation cannot be confirmed, as stdin is not a terminal
+const ERROR_NO_TERMINAL = "Cannot ask for confirmation, as stdin is not a terminal. Use --yes to confirm the operation."
+
 // CreateAlphaNumericError returns an error object with the hint that the name of the object passed by a string should be
 // alphanumeric.
 func CreateAlphaNumericError(objectName string) error {
-	return errors.New(objectName + ": Name has to be alphanumeric.")
+	return NewError(ERROR_KIND_USAGE, objectName+": Name has to be alphanumeric.")
+}
+
+// CreateWrongNumberArgumentsError returns an error object for commands, which have been called with the wrong
+// number of arguments.
+func CreateWrongNumberArgumentsError() error {
+	return NewError(ERROR_KIND_USAGE, ERROR_WRONG_NUMBER_ARGUMENTS)
 }
@@ -0,0 +1,226 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package tools
+
+import (
+	"errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/client-go/util/homedir"
+	"os"
+	"path/filepath"
+	"sigs.k8s.io/yaml"
+	"sort"
+	"strings"
+)
+
+// DEFAULT_PROFILE is the profile used, if the config file does not select one.
+const DEFAULT_PROFILE = "default"
+
+// Config is the kufast config file. It holds named profiles, which provide the defaults for the flags of all commands.
+type Config struct {
+	CurrentProfile string              `json:"currentProfile,omitempty"`
+	Profiles       map[string]*Profile `json:"profiles,omitempty"`
+}
+
+// Profile holds the defaults of a profile. Empty values are not applied.
+type Profile struct {
+	Kubeconfig string      `json:"kubeconfig,omitempty"`
+	Tenant     string      `json:"tenant,omitempty"`
+	Target     string      `json:"target,omitempty"`
+	Output     string      `json:"output,omitempty"`
+	Pod        PodDefaults `json:"pod,omitempty"`
+}
+
+// PodDefaults holds the default limits of new pods.
+type PodDefaults struct {
+	CPU     string `json:"cpu,omitempty"`
+	Memory  string `json:"memory,omitempty"`
+	Storage string `json:"storage,omitempty"`
+}
+
+// profileSetting connects a key of a profile with the flags it provides the default for.
+type profileSetting struct {
+	value func(p *Profile) *string
+	// matches returns true for the flags of a command, which take their default from the setting.
+	matches func(cmd *cobra.Command, flag *pflag.Flag) bool
+}
+
+// profileSettings are all keys, which can be set in a profile.
+var profileSettings = map[string]profileSetting{
+	"kubeconfig": {func(p *Profile) *string { return &p.Kubeconfig }, flagNamed("kubeconfig")},
+	"tenant":     {func(p *Profile) *string { return &p.Tenant }, flagNamed("tenant")},
+	"target": {func(p *Profile) *string { return &p.Target }, func(cmd *cobra.Command, flag *pflag.Flag) bool {
+		//Some commands accept several targets, these are not defaulted
+		return flag.Name == "target" && flag.Value.Type() == "string"
+	}},
+	"output":      {func(p *Profile) *string { return &p.Output }, flagNamed("output")},
+	"pod.cpu":     {func(p *Profile) *string { return &p.Pod.CPU }, podFlagNamed("cpu")},
+	"pod.memory":  {func(p *Profile) *string { return &p.Pod.Memory }, podFlagNamed("memory")},
+	"pod.storage": {func(p *Profile) *string { return &p.Pod.Storage }, podFlagNamed("storage")},
+}
+
+// flagNamed returns a matcher for the flags with the given name.
+func flagNamed(name string) func(cmd *cobra.Command, flag *pflag.Flag) bool {
+	return func(cmd *cobra.Command, flag *pflag.Flag) bool {
+		return flag.Name == name
+	}
+}
+
+// podFlagNamed returns a matcher for the flags with the given name of the create pod command.
+func podFlagNamed(name string) func(cmd *cobra.Command, flag *pflag.Flag) bool {
+	return func(cmd *cobra.Command, flag *pflag.Flag) bool {
+		return flag.Name == name && cmd.Name() == "pod" && cmd.HasParent() && cmd.Parent().Name() == "create"
+	}
+}
+
+// ProfileKeys returns the keys, which can be set in a profile.
+func ProfileKeys() []string {
+	var keys []string
+	for key := range profileSettings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// GetConfigPath returns the path of the kufast config file. It is read from $KUFAST_CONFIG or defaults to
+// ~/.config/kufast/config.yaml.
+func GetConfigPath() (string, error) {
+	if path := os.Getenv("KUFAST_CONFIG"); path != "" {
+		return path, nil
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "kufast", "config.yaml"), nil
+}
+
+// LoadConfig reads the kufast config file. If the file does not exist, an empty config is returned.
+func LoadConfig() (*Config, error) {
+	config := &Config{Profiles: map[string]*Profile{}}
+
+	path, err := GetConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	err = yaml.UnmarshalStrict(content, config)
+	if err != nil {
+		return nil, NewError(ERROR_KIND_USAGE, "Invalid config file "+path+": "+err.Error())
+	}
+	if config.Profiles == nil {
+		config.Profiles = map[string]*Profile{}
+	}
+	return config, nil
+}
+
+// Save writes the config to the kufast config file.
+func (c *Config) Save() error {
+	path, err := GetConfigPath()
+	if err != nil {
+		return err
+	}
+	content, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0600)
+}
+
+// ProfileName returns the name of the profile with the given name or the current profile, if name is empty.
+func (c *Config) ProfileName(name string) string {
+	if name != "" {
+		return name
+	}
+	if c.CurrentProfile != "" {
+		return c.CurrentProfile
+	}
+	return DEFAULT_PROFILE
+}
+
+// Set sets a key of a profile. If the profile does not exist, it is created.
+func (c *Config) Set(profileName string, key string, value string) error {
+	setting, ok := profileSettings[key]
+	if !ok {
+		return NewError(ERROR_KIND_USAGE, "Unknown key "+key+", use one of "+strings.Join(ProfileKeys(), ", "))
+	}
+
+	profileName = c.ProfileName(profileName)
+	if c.Profiles[profileName] == nil {
+		c.Profiles[profileName] = &Profile{}
+	}
+	*setting.value(c.Profiles[profileName]) = value
+	return nil
+}
+
+// ApplyProfile sets all flags of a command, which have not been set by the user, to the values of the selected
+// profile. The profile is selected with the --profile flag or the current profile of the config file.
+func ApplyProfile(cmd *cobra.Command) error {
+	config, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	profileName := ""
+	if cmd.Flags().Lookup("profile") != nil {
+		profileName, _ = cmd.Flags().GetString("profile")
+	}
+	profile := config.Profiles[config.ProfileName(profileName)]
+	if profile == nil {
+		if profileName != "" {
+			return NewError(ERROR_KIND_USAGE, "Profile "+profileName+" not found in the config file.")
+		}
+		return nil
+	}
+
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Changed {
+			return
+		}
+		for _, setting := range profileSettings {
+			value := *setting.value(profile)
+			if value != "" && setting.matches(cmd, flag) {
+				if strings.HasPrefix(value, "~/") {
+					value = filepath.Join(homedir.HomeDir(), value[2:])
+				}
+				if setErr := cmd.Flags().Set(flag.Name, value); setErr != nil {
+					err = NewError(ERROR_KIND_USAGE, "Invalid value for "+flag.Name+" in profile: "+setErr.Error())
+				}
+			}
+		}
+	})
+	return err
+}
@@ -0,0 +1,106 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package tools
+
+import (
+	"github.com/spf13/cobra"
+	"path/filepath"
+	"testing"
+)
+
+// newProfileCmds creates the commands create pod and create tenant-target with flags similar to kufast.
+func newProfileCmds() (*cobra.Command, *cobra.Command) {
+	root := &cobra.Command{Use: "kufast"}
+	root.PersistentFlags().StringP("profile", "", "", "")
+	create := &cobra.Command{Use: "create"}
+	root.AddCommand(create)
+
+	pod := &cobra.Command{Use: "pod", Run: func(cmd *cobra.Command, args []string) {}}
+	pod.Flags().StringP("cpu", "", "500m", "")
+	pod.Flags().StringP("memory", "", "500Mi", "")
+	pod.Flags().StringP("tenant", "", "", "")
+	pod.Flags().StringP("target", "", "", "")
+	tenantTarget := &cobra.Command{Use: "tenant-target", Run: func(cmd *cobra.Command, args []string) {}}
+	tenantTarget.Flags().StringP("cpu", "", "500m", "")
+	tenantTarget.Flags().StringP("tenant", "", "", "")
+	create.AddCommand(pod, tenantTarget)
+	return pod, tenantTarget
+}
+
+func TestApplyProfile(t *testing.T) {
+	t.Setenv("KUFAST_CONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+	config, _ := LoadConfig()
+	for key, value := range map[string]string{"tenant": "tenant1", "target": "w1", "pod.cpu": "1", "pod.memory": "2Gi"} {
+		if err := config.Set("", key, value); err != nil {
+			t.Fatalf("Set %s: %v", key, err)
+		}
+	}
+	_ = config.Set("other", "tenant", "tenant2")
+	if err := config.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	pod, tenantTarget := newProfileCmds()
+	_ = pod.ParseFlags([]string{"--memory", "1Gi"})
+	_ = tenantTarget.ParseFlags(nil)
+	for _, cmd := range []*cobra.Command{pod, tenantTarget} {
+		if err := ApplyProfile(cmd); err != nil {
+			t.Fatalf("ApplyProfile: %v", err)
+		}
+	}
+
+	expected := map[*cobra.Command]map[string]string{
+		pod:          {"tenant": "tenant1", "target": "w1", "cpu": "1", "memory": "1Gi"},
+		tenantTarget: {"tenant": "tenant1", "cpu": "500m"},
+	}
+	for cmd, flags := range expected {
+		for name, value := range flags {
+			if actual, _ := cmd.Flags().GetString(name); actual != value {
+				t.Errorf("%s: expected --%s=%s, got %s", cmd.Name(), name, value, actual)
+			}
+		}
+	}
+
+	pod, _ = newProfileCmds()
+	_ = pod.ParseFlags([]string{"--profile", "other"})
+	if err := ApplyProfile(pod); err != nil {
+		t.Fatalf("ApplyProfile: %v", err)
+	}
+	if tenant, _ := pod.Flags().GetString("tenant"); tenant != "tenant2" {
+		t.Errorf("expected the tenant of the selected profile, got %s", tenant)
+	}
+
+	pod, _ = newProfileCmds()
+	_ = pod.ParseFlags([]string{"--profile", "missing"})
+	if err := ApplyProfile(pod); GetErrorKind(err) != ERROR_KIND_USAGE {
+		t.Errorf("expected a usage error for a missing profile, got %v", err)
+	}
+}
+
+func TestConfigSetUnknownKey(t *testing.T) {
+	config := &Config{Profiles: map[string]*Profile{}}
+	if err := config.Set("", "cpu", "1"); GetErrorKind(err) != ERROR_KIND_USAGE {
+		t.Errorf("expected a usage error for an unknown key, got %v", err)
+	}
+}
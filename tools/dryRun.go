@@ -0,0 +1,101 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package tools
+
+import (
+	"bytes"
+	"github.com/spf13/cobra"
+	"io"
+	"sync"
+)
+
+// DRY_RUN_NONE returns the dry-run mode, in which all changes are sent to the cluster
+const DRY_RUN_NONE = "none"
+
+// DRY_RUN_CLIENT returns the dry-run mode, in which changes are only rendered and never sent to the cluster
+const DRY_RUN_CLIENT = "client"
+
+// DRY_RUN_SERVER returns the dry-run mode, in which changes are validated by the cluster without being persisted
+const DRY_RUN_SERVER = "server"
+
+// dryRunOutput collects the manifests and changes of a dry run, so they are not mixed with the spinner.
+var dryRunOutput = &syncBuffer{}
+
+// syncBuffer is a buffer, which can be written by concurrent operations.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// AddDryRunFlags adds the persistent dry-run and print-manifests flags to a cobra command and all its subcommands.
+// The output of a dry run is printed once the command has finished.
+func AddDryRunFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringP("dry-run", "", DRY_RUN_NONE, "Preview the changes without persisting them. One of: none, client, server. "+
+		"client only renders the objects, server lets the cluster validate them, including its admission control.")
+	cmd.PersistentFlags().BoolP("print-manifests", "", false, "Print the objects sent to the cluster as multi-document YAML.")
+	cmd.PersistentPostRun = func(cmd *cobra.Command, args []string) {
+		FlushDryRunOutput(cmd.OutOrStdout())
+	}
+}
+
+// GetDryRunMode returns the dry-run mode selected with the dry-run flag. Commands without the flag are never
+// run dry.
+func GetDryRunMode(cmd *cobra.Command) (string, error) {
+	if cmd.Flags().Lookup("dry-run") == nil {
+		return DRY_RUN_NONE, nil
+	}
+	mode, _ := cmd.Flags().GetString("dry-run")
+	switch mode {
+	case DRY_RUN_NONE, DRY_RUN_CLIENT, DRY_RUN_SERVER:
+		return mode, nil
+	}
+	return "", NewError(ERROR_KIND_USAGE, "Invalid dry-run mode "+mode+". One of: none, client, server.")
+}
+
+// GetPrintManifests returns true, if the user wants to see the objects sent to the cluster.
+func GetPrintManifests(cmd *cobra.Command) bool {
+	if cmd.Flags().Lookup("print-manifests") == nil {
+		return false
+	}
+	printManifests, _ := cmd.Flags().GetBool("print-manifests")
+	return printManifests
+}
+
+// DryRunOutput returns the writer collecting the output of a dry run until FlushDryRunOutput is called.
+func DryRunOutput() io.Writer {
+	return dryRunOutput
+}
+
+// FlushDryRunOutput writes the collected output of a dry run to out.
+func FlushDryRunOutput(out io.Writer) {
+	dryRunOutput.mu.Lock()
+	defer dryRunOutput.mu.Unlock()
+	_, _ = dryRunOutput.buf.WriteTo(out)
+}
@@ -0,0 +1,81 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package tools
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/spf13/cobra"
+	"testing"
+)
+
+func TestGetDryRunMode(t *testing.T) {
+	tests := []struct {
+		args    []string
+		mode    string
+		wantErr bool
+	}{
+		{args: nil, mode: DRY_RUN_NONE},
+		{args: []string{"--dry-run=client"}, mode: DRY_RUN_CLIENT},
+		{args: []string{"--dry-run=server"}, mode: DRY_RUN_SERVER},
+		{args: []string{"--dry-run=all"}, wantErr: true},
+	}
+
+	for _, test := range tests {
+		cmd := &cobra.Command{}
+		AddDryRunFlags(cmd)
+		if err := cmd.ParseFlags(test.args); err != nil {
+			t.Fatalf("parse %v: %v", test.args, err)
+		}
+		mode, err := GetDryRunMode(cmd)
+		if (err != nil) != test.wantErr || mode != test.mode {
+			t.Errorf("%v: expected mode %q (error %v), got %q (%v)", test.args, test.mode, test.wantErr, mode, err)
+		}
+	}
+
+	if mode, err := GetDryRunMode(&cobra.Command{}); err != nil || mode != DRY_RUN_NONE {
+		t.Errorf("expected commands without the flag not to be run dry, got %q (%v)", mode, err)
+	}
+}
+
+func TestFlushDryRunOutput(t *testing.T) {
+	cmd := &cobra.Command{Run: func(cmd *cobra.Command, args []string) {
+		fmt.Fprintln(DryRunOutput(), "namespaces/tenant1-w1 created (client dry run)")
+	}}
+	AddDryRunFlags(cmd)
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--dry-run=client"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if out.String() != "namespaces/tenant1-w1 created (client dry run)\n" {
+		t.Errorf("expected the output of the dry run after the command, got %q", out.String())
+	}
+	FlushDryRunOutput(&out)
+	if out.Len() != len("namespaces/tenant1-w1 created (client dry run)\n") {
+		t.Errorf("expected the output to be printed once")
+	}
+}
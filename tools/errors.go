@@ -0,0 +1,129 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package tools
+
+import (
+	"context"
+	"errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"strings"
+)
+
+// ErrorKind classifies the errors of kufast. Every kind has its own exit code.
+type ErrorKind int
+
+const (
+	// ERROR_KIND_UNKNOWN is the kind of all errors, which cannot be classified
+	ERROR_KIND_UNKNOWN ErrorKind = iota
+	// ERROR_KIND_USAGE is the kind of errors caused by invalid arguments or flags
+	ERROR_KIND_USAGE
+	// ERROR_KIND_NOT_FOUND is the kind of errors caused by objects, which do not exist
+	ERROR_KIND_NOT_FOUND
+	// ERROR_KIND_FORBIDDEN is the kind of errors caused by missing permissions
+	ERROR_KIND_FORBIDDEN
+	// ERROR_KIND_QUOTA_EXCEEDED is the kind of errors caused by exceeding the limits of a tenant-target
+	ERROR_KIND_QUOTA_EXCEEDED
+	// ERROR_KIND_INVALID_TARGET is the kind of errors caused by targets, which do not exist or the tenant has no access to
+	ERROR_KIND_INVALID_TARGET
+	// ERROR_KIND_TIMEOUT is the kind of errors caused by the cluster not reaching the requested state in time
+	ERROR_KIND_TIMEOUT
+	// ERROR_KIND_CONFLICT is the kind of errors caused by objects, which already exist or have been changed concurrently
+	ERROR_KIND_CONFLICT
+)
+
+// EXIT_CODES returns the exit code of kufast for every kind of error. Successful commands exit with 0.
+var EXIT_CODES = map[ErrorKind]int{
+	ERROR_KIND_UNKNOWN:        1,
+	ERROR_KIND_USAGE:          2,
+	ERROR_KIND_NOT_FOUND:      3,
+	ERROR_KIND_FORBIDDEN:      4,
+	ERROR_KIND_QUOTA_EXCEEDED: 5,
+	ERROR_KIND_INVALID_TARGET: 6,
+	ERROR_KIND_TIMEOUT:        7,
+	ERROR_KIND_CONFLICT:       8,
+}
+
+// Error is an error of a kufast operation. Its kind determines the exit code of kufast.
+type Error struct {
+	Kind    ErrorKind
+	Message string
+	// Err is the error causing this error, if any.
+	Err error
+}
+
+// Error returns the message of the error.
+func (e *Error) Error() string {
+	if e.Message == "" && e.Err != nil {
+		return e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap returns the error causing this error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NewError creates a new error of the given kind.
+func NewError(kind ErrorKind, message string) error {
+	return &Error{Kind: kind, Message: message}
+}
+
+// WithMessage replaces the message of an error. The kind of the error is kept.
+func WithMessage(err error, message string) error {
+	return &Error{Kind: GetErrorKind(err), Message: message, Err: err}
+}
+
+// GetErrorKind returns the kind of an error. Errors of the Kubernetes API are classified by their reason.
+func GetErrorKind(err error) ErrorKind {
+	var kufastErr *Error
+	if errors.As(err, &kufastErr) && kufastErr.Kind != ERROR_KIND_UNKNOWN {
+		return kufastErr.Kind
+	}
+
+	switch {
+	case err == nil:
+		return ERROR_KIND_UNKNOWN
+	case apierrors.IsNotFound(err):
+		return ERROR_KIND_NOT_FOUND
+	case apierrors.IsForbidden(err) && strings.Contains(err.Error(), "exceeded quota"):
+		return ERROR_KIND_QUOTA_EXCEEDED
+	case apierrors.IsForbidden(err), apierrors.IsUnauthorized(err):
+		return ERROR_KIND_FORBIDDEN
+	case apierrors.IsAlreadyExists(err), apierrors.IsConflict(err):
+		return ERROR_KIND_CONFLICT
+	case apierrors.IsTimeout(err), apierrors.IsServerTimeout(err), errors.Is(err, context.DeadlineExceeded), errors.Is(err, wait.ErrWaitTimeout):
+		return ERROR_KIND_TIMEOUT
+	}
+	return ERROR_KIND_UNKNOWN
+}
+
+// GetExitCode returns the exit code of kufast for an error.
+func GetExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	return EXIT_CODES[GetErrorKind(err)]
+}
@@ -0,0 +1,81 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package tools
+
+import (
+	"errors"
+	"fmt"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"testing"
+)
+
+func TestGetErrorKind(t *testing.T) {
+	pods := schema.GroupResource{Resource: "pods"}
+	tests := []struct {
+		name string
+		err  error
+		kind ErrorKind
+		code int
+	}{
+		{name: "plain error", err: errors.New("failed"), kind: ERROR_KIND_UNKNOWN, code: 1},
+		{name: "usage error", err: CreateWrongNumberArgumentsError(), kind: ERROR_KIND_USAGE, code: 2},
+		{name: "not found", err: apierrors.NewNotFound(pods, "nginx"), kind: ERROR_KIND_NOT_FOUND, code: 3},
+		{name: "forbidden", err: apierrors.NewForbidden(pods, "nginx", errors.New("no access")), kind: ERROR_KIND_FORBIDDEN, code: 4},
+		{name: "unauthorized", err: apierrors.NewUnauthorized("expired token"), kind: ERROR_KIND_FORBIDDEN, code: 4},
+		{name: "quota", err: apierrors.NewForbidden(pods, "nginx", errors.New("exceeded quota: tenant1-w1-limits")), kind: ERROR_KIND_QUOTA_EXCEEDED, code: 5},
+		{name: "invalid target", err: NewError(ERROR_KIND_INVALID_TARGET, "Invalid target: w9"), kind: ERROR_KIND_INVALID_TARGET, code: 6},
+		{name: "wait timeout", err: fmt.Errorf("waiting for pod: %w", wait.ErrWaitTimeout), kind: ERROR_KIND_TIMEOUT, code: 7},
+		{name: "already exists", err: apierrors.NewAlreadyExists(pods, "nginx"), kind: ERROR_KIND_CONFLICT, code: 8},
+	}
+
+	for _, test := range tests {
+		if kind := GetErrorKind(test.err); kind != test.kind {
+			t.Errorf("%s: expected kind %d, got %d", test.name, test.kind, kind)
+		}
+		if code := GetExitCode(test.err); code != test.code {
+			t.Errorf("%s: expected exit code %d, got %d", test.name, test.code, code)
+		}
+	}
+
+	if code := GetExitCode(nil); code != 0 {
+		t.Errorf("expected exit code 0 without an error, got %d", code)
+	}
+}
+
+func TestWithMessage(t *testing.T) {
+	cause := apierrors.NewAlreadyExists(schema.GroupResource{Resource: "namespaces"}, "tenant1-w1")
+	err := WithMessage(cause, "Failed to create tenant-target tenant1-w1 at step 'namespace'")
+
+	if err.Error() != "Failed to create tenant-target tenant1-w1 at step 'namespace'" {
+		t.Errorf("expected the new message, got %q", err.Error())
+	}
+	if GetErrorKind(err) != ERROR_KIND_CONFLICT {
+		t.Errorf("expected the kind of the cause to be kept, got %d", GetErrorKind(err))
+	}
+	if !errors.Is(err, cause) {
+		t.Errorf("expected the cause to be wrapped")
+	}
+}
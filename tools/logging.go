@@ -0,0 +1,71 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package tools
+
+import (
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"sync"
+)
+
+// traceFiles holds the trace files opened during this program run by their path. All clients of a command share
+// the same file, even if they connect to different clusters.
+var traceFiles = map[string]*os.File{}
+var traceFilesMu sync.Mutex
+
+// GetVerbosity returns how detailed the API calls to the cluster are logged, as selected with the verbose flag.
+// 0 logs nothing, 1 logs a line per API call and 2 adds the URL and the response of failed calls.
+func GetVerbosity(cmd *cobra.Command) int {
+	if cmd.Flags().Lookup("verbose") == nil {
+		return 0
+	}
+	verbosity, _ := cmd.Flags().GetCount("verbose")
+	return verbosity
+}
+
+// GetTraceFile returns the file selected with the trace-file flag to write all requests and responses to. The file
+// is created once per program run and is only readable by the user, as it contains the objects sent to the cluster.
+// If no trace file has been selected, nil is returned.
+func GetTraceFile(cmd *cobra.Command) (io.Writer, error) {
+	if cmd.Flags().Lookup("trace-file") == nil {
+		return nil, nil
+	}
+	path, _ := cmd.Flags().GetString("trace-file")
+	if path == "" {
+		return nil, nil
+	}
+
+	traceFilesMu.Lock()
+	defer traceFilesMu.Unlock()
+	if file, ok := traceFiles[path]; ok {
+		return file, nil
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, NewError(ERROR_KIND_USAGE, "Cannot write the trace file: "+err.Error())
+	}
+	traceFiles[path] = file
+	return file, nil
+}
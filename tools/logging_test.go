@@ -0,0 +1,97 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package tools
+
+import (
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newLoggingTestCmd creates a command with the logging flags of the root command, parsed from the given arguments.
+func newLoggingTestCmd(t *testing.T, args ...string) *cobra.Command {
+	t.Helper()
+
+	cmd := &cobra.Command{}
+	cmd.Flags().CountP("verbose", "v", "")
+	cmd.Flags().StringP("trace-file", "", "", "")
+	if err := cmd.ParseFlags(args); err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	return cmd
+}
+
+func TestGetVerbosity(t *testing.T) {
+	tests := []struct {
+		args      []string
+		verbosity int
+	}{
+		{args: nil, verbosity: 0},
+		{args: []string{"-v"}, verbosity: 1},
+		{args: []string{"-vv"}, verbosity: 2},
+		{args: []string{"--verbose=2"}, verbosity: 2},
+	}
+
+	for _, test := range tests {
+		if verbosity := GetVerbosity(newLoggingTestCmd(t, test.args...)); verbosity != test.verbosity {
+			t.Errorf("%v: expected verbosity %d, got %d", test.args, test.verbosity, verbosity)
+		}
+	}
+	if verbosity := GetVerbosity(&cobra.Command{}); verbosity != 0 {
+		t.Errorf("expected no logging for commands without the verbose flag, got %d", verbosity)
+	}
+}
+
+func TestGetTraceFile(t *testing.T) {
+	if trace, err := GetTraceFile(newLoggingTestCmd(t)); trace != nil || err != nil {
+		t.Errorf("expected no trace file, got %v, %v", trace, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "kufast.trace")
+	first, err := GetTraceFile(newLoggingTestCmd(t, "--trace-file", path))
+	if err != nil {
+		t.Fatalf("GetTraceFile: %v", err)
+	}
+	second, _ := GetTraceFile(newLoggingTestCmd(t, "--trace-file", path))
+	if first != second {
+		t.Errorf("expected all clients to share the trace file")
+	}
+	t.Cleanup(func() {
+		_ = first.(*os.File).Close()
+		delete(traceFiles, path)
+	})
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected the trace file to be readable by the user only, got %v", info.Mode().Perm())
+	}
+
+	if _, err := GetTraceFile(newLoggingTestCmd(t, "--trace-file", filepath.Join(path, "missing", "kufast.trace"))); GetErrorKind(err) != ERROR_KIND_USAGE {
+		t.Errorf("expected a usage error for a trace file, which cannot be created, got %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/yaml"
+	"strings"
+	"text/template"
+)
+
+// OUTPUT_FORMATS returns the documentation of all formats supported by the output flag
+const OUTPUT_FORMATS = "Output format. One of: json, yaml, wide, name, go-template=<template>, jsonpath=<template>. Defaults to a table."
+
+// ViewList is the representation of a list of views in machine-readable output formats.
+type ViewList struct {
+	Kind  string `json:"kind"`
+	Items []View `json:"items"`
+}
+
+// AddOutputFlag adds the persistent output flag to a cobra command and all its subcommands.
+func AddOutputFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringP("output", "o", "", OUTPUT_FORMATS)
+}
+
+// PrintView prints a single view in the output format selected by the user. renderTable is called to print the
+// view as a table, if no machine-readable format has been selected. Its argument is true for the wide format.
+func PrintView(cmd *cobra.Command, view View, renderTable func(wide bool)) error {
+	return printOutput(cmd, view, []View{view}, renderTable)
+}
+
+// PrintViews prints a list of views in the output format selected by the user. renderTable is called to print the
+// views as a table, if no machine-readable format has been selected. Its argument is true for the wide format.
+func PrintViews(cmd *cobra.Command, views []View, renderTable func(wide bool)) error {
+	if views == nil {
+		views = []View{}
+	}
+	return printOutput(cmd, ViewList{Kind: "List", Items: views}, views, renderTable)
+}
+
+// printOutput prints obj according to the output flag of the cobra command.
+func printOutput(cmd *cobra.Command, obj interface{}, views []View, renderTable func(wide bool)) error {
+	format, _ := cmd.Flags().GetString("output")
+	out := cmd.OutOrStdout()
+
+	switch {
+	case format == "":
+		renderTable(false)
+	case format == "wide":
+		renderTable(true)
+	case format == "name":
+		for _, view := range views {
+			fmt.Fprintln(out, view.ViewName())
+		}
+	case format == "json":
+		data, err := json.MarshalIndent(obj, "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, string(data))
+	case format == "yaml":
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(out, string(data))
+	case strings.HasPrefix(format, "go-template="):
+		tmpl, err := template.New("output").Parse(strings.TrimPrefix(format, "go-template="))
+		if err != nil {
+			return err
+		}
+		data, err := toGenericObject(obj)
+		if err != nil {
+			return err
+		}
+		return tmpl.Execute(out, data)
+	case strings.HasPrefix(format, "jsonpath="):
+		j := jsonpath.New("output")
+		err := j.Parse(strings.TrimPrefix(format, "jsonpath="))
+		if err != nil {
+			return err
+		}
+		data, err := toGenericObject(obj)
+		if err != nil {
+			return err
+		}
+		err = j.Execute(out, data)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out)
+	default:
+		return NewError(ERROR_KIND_USAGE, "Unknown output format: "+format+". "+OUTPUT_FORMATS)
+	}
+	return nil
+}
+
+// toGenericObject converts obj into maps and slices by its JSON representation, so templates can use the
+// same field names as the json output.
+func toGenericObject(obj interface{}) (interface{}, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var result interface{}
+	err = json.Unmarshal(data, &result)
+	return result, err
+}
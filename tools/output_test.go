@@ -0,0 +1,106 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package tools
+
+import (
+	"bytes"
+	"github.com/spf13/cobra"
+	"testing"
+	"time"
+)
+
+// runPrintViews prints views with the given output format and returns the output.
+func runPrintViews(t *testing.T, format string, views []View) (string, bool) {
+	t.Helper()
+
+	cmd := &cobra.Command{}
+	AddOutputFlag(cmd)
+	if err := cmd.ParseFlags([]string{"--output=" + format}); err != nil {
+		t.Fatalf("set output flag: %v", err)
+	}
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	tableRendered := false
+	err := PrintViews(cmd, views, func(wide bool) { tableRendered = true })
+	if err != nil {
+		t.Fatalf("print views with format %q: %v", format, err)
+	}
+	return out.String(), tableRendered
+}
+
+func TestPrintViews(t *testing.T) {
+	created := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	views := []View{
+		TenantView{Name: "tenant1", DefaultTarget: "w2", Targets: []Target{{Name: "w2", AccessType: "node"}}, CreatedAt: created},
+		TenantView{Name: "tenant2", Targets: []Target{}, CreatedAt: created},
+	}
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{"name", "tenant/tenant1\ntenant/tenant2\n"},
+		{"jsonpath={.items[*].name}", "tenant1 tenant2\n"},
+		{"go-template={{range .items}}{{.defaultTarget}};{{end}}", "w2;<no value>;"},
+		{"yaml", `items:
+- createdAt: "2023-05-01T12:00:00Z"
+  defaultTarget: w2
+  name: tenant1
+  targets:
+  - accessType: node
+    name: w2
+- createdAt: "2023-05-01T12:00:00Z"
+  name: tenant2
+  targets: []
+kind: List
+`},
+	}
+	for _, test := range tests {
+		actual, tableRendered := runPrintViews(t, test.format, views)
+		if tableRendered {
+			t.Errorf("format %q rendered a table", test.format)
+		}
+		if actual != test.expected {
+			t.Errorf("format %q: expected\n%s\ngot\n%s", test.format, test.expected, actual)
+		}
+	}
+}
+
+func TestPrintViewsTable(t *testing.T) {
+	for _, format := range []string{"", "wide"} {
+		if _, tableRendered := runPrintViews(t, format, nil); !tableRendered {
+			t.Errorf("format %q did not render a table", format)
+		}
+	}
+}
+
+func TestPrintViewsUnknownFormat(t *testing.T) {
+	cmd := &cobra.Command{}
+	AddOutputFlag(cmd)
+	_ = cmd.ParseFlags([]string{"--output=xml"})
+	if err := PrintViews(cmd, nil, func(wide bool) {}); GetErrorKind(err) != ERROR_KIND_USAGE {
+		t.Errorf("expected a usage error for an unknown output format, got %v", err)
+	}
+}
@@ -25,6 +25,11 @@ package tools
 
 // Target represents a deployment target and contains its name and the type of access (either group or node)
 type Target struct {
-	Name       string
-	AccessType string
+	Name       string `json:"name"`
+	AccessType string `json:"accessType"`
+}
+
+// ViewName returns the name of the target in the form target/<name>.
+func (t Target) ViewName() string {
+	return "target/" + t.Name
 }
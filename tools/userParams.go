@@ -26,25 +26,32 @@ package tools
 import (
 	"errors"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
-	"k8s.io/client-go/util/homedir"
+	"sort"
 	"strings"
 )
 
+// kubeconfigOverrides holds the values of the standard kubectl flags like --context, --namespace or --as. They are
+// bound once to the persistent flags of the root command.
+var kubeconfigOverrides = &clientcmd.ConfigOverrides{}
+
+// AddKubeconfigFlags adds the standard kubectl flags for selecting and overriding the kubeconfig to a flag set, so
+// kufast can be used as the kubectl plugin kubectl-kufast.
+func AddKubeconfigFlags(flags *pflag.FlagSet) {
+	flags.StringP("kubeconfig", "k", "", "Your kubeconfig to access the cluster. If not provided, we read it from $KUBECONFIG or $HOME/.kube/config")
+	clientcmd.BindOverrideFlags(kubeconfigOverrides, flags, clientcmd.RecommendedConfigOverrideFlags(""))
+}
+
 // GetUserClient creates an instance of clientset to communicate with the Kubernetes cluster
 // based on the credentials the user entered when using this program.
 func GetUserClient(cmd *cobra.Command) (*kubernetes.Clientset, *rest.Config, error) {
 	var config *rest.Config
 	var clientset *kubernetes.Clientset
 
-	path, err := getKubeconfigPath(cmd)
-	if err != nil {
-		return clientset, config, err
-	}
-
-	config, err = clientcmd.BuildConfigFromFlags("", path)
+	config, err := getClientConfig(cmd, "").ClientConfig()
 	if err != nil {
 		return clientset, config, err
 	}
@@ -58,6 +65,51 @@ func GetUserClient(cmd *cobra.Command) (*kubernetes.Clientset, *rest.Config, err
 	return clientset, config, nil
 }
 
+// GetClusterNames returns the clusters selected with the --clusters flag. Clusters are the contexts of the kubeconfig;
+// "all" selects every context. If no cluster has been selected, nil is returned and the current context is used.
+func GetClusterNames(cmd *cobra.Command) ([]string, error) {
+	if cmd.Flags().Lookup("clusters") == nil {
+		return nil, nil
+	}
+	clusters, err := cmd.Flags().GetStringSlice("clusters")
+	if err != nil || len(clusters) == 0 {
+		return nil, err
+	}
+	if len(clusters) > 1 || clusters[0] != "all" {
+		return clusters, nil
+	}
+
+	cfg, err := getClientConfig(cmd, "").RawConfig()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for name := range cfg.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// GetClusterConfig returns the rest config and the namespace of one of the clusters in the kubeconfig of the user.
+// If cluster is empty, the current context is used and a missing namespace is not fatal. The kubeconfig is parsed
+// only once for both.
+func GetClusterConfig(cmd *cobra.Command, cluster string) (*rest.Config, string, error) {
+	clientConfig := getClientConfig(cmd, cluster)
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		if cluster == "" {
+			return nil, "", err
+		}
+		return nil, "", errors.New("Cluster " + cluster + ": " + err.Error())
+	}
+	namespace, err := getNamespace(clientConfig, cluster)
+	if err != nil && cluster != "" {
+		return nil, "", err
+	}
+	return config, namespace, nil
+}
+
 // GetTenantFromNamespace returns the tenants name from one of its namespaces by leveraging
 // the namespace naming convention
 func GetTenantFromNamespace(namespaceName string) string {
@@ -65,38 +117,68 @@ func GetTenantFromNamespace(namespaceName string) string {
 }
 
 // GetNamespaceFromUserConfig reads the userconfig of a user and returns the namespace
-// specified in it.
+// specified in it or with the --namespace flag.
 func GetNamespaceFromUserConfig(cmd *cobra.Command) (string, error) {
+	return getNamespace(getClientConfig(cmd, ""), "")
+}
 
-	path, err := getKubeconfigPath(cmd)
+// SetNamespaceInUserConfig changes the namespace of the current context in the kubeconfig of the user. Only the
+// file defining the context is rewritten.
+func SetNamespaceInUserConfig(cmd *cobra.Command, namespace string) error {
+	clientConfig := getClientConfig(cmd, "")
+	cfg, err := clientConfig.RawConfig()
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
-	loadingRules.Precedence[0] = path
-	cfg, err := loadingRules.Load()
-	if err != nil {
-		return "", err
-	} else if cfg.Contexts[cfg.CurrentContext] != nil {
-		return cfg.Contexts[cfg.CurrentContext].Namespace, nil
-	} else {
-		return "", errors.New("Config not found or bad format.")
+	contextName := cfg.CurrentContext
+	if kubeconfigOverrides.CurrentContext != "" {
+		contextName = kubeconfigOverrides.CurrentContext
 	}
+	if cfg.Contexts[contextName] == nil {
+		return errors.New("Config not found or bad format.")
+	}
+	cfg.Contexts[contextName].Namespace = namespace
 
+	return clientcmd.ModifyConfig(clientConfig.ConfigAccess(), cfg, false)
 }
 
-// getKubeconfigPath returns the path of the kubeconfig stored in a cobra command.
-func getKubeconfigPath(cmd *cobra.Command) (string, error) {
-	var kubeLoc string
+// getNamespace returns the namespace of a context of the kubeconfig. If context is empty, the current context is
+// used. Unlike clientcmd.ClientConfig.Namespace(), a missing namespace is not replaced with "default", as the
+// namespace selects the tenant.
+func getNamespace(clientConfig clientcmd.ClientConfig, context string) (string, error) {
+	if kubeconfigOverrides.Context.Namespace != "" {
+		return kubeconfigOverrides.Context.Namespace, nil
+	}
 
-	kubeLoc, err := cmd.Flags().GetString("kubeconfig")
+	cfg, err := clientConfig.RawConfig()
 	if err != nil {
 		return "", err
 	}
-	if kubeLoc == "" {
-		kubeLoc = homedir.HomeDir() + "/.kube/config"
+	if context == "" {
+		context = cfg.CurrentContext
+		if kubeconfigOverrides.CurrentContext != "" {
+			context = kubeconfigOverrides.CurrentContext
+		}
 	}
+	if cfg.Contexts[context] == nil {
+		return "", errors.New("Config not found or bad format.")
+	}
+	return cfg.Contexts[context].Namespace, nil
+}
 
-	return kubeLoc, nil
+// getClientConfig returns the kubeconfig of the user along with the overrides of the kubectl flags. The kubeconfig is
+// read from the --kubeconfig flag, the paths in $KUBECONFIG or $HOME/.kube/config. If context is not empty, it
+// replaces the current context.
+func getClientConfig(cmd *cobra.Command, context string) clientcmd.ClientConfig {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if cmd.Flags().Lookup("kubeconfig") != nil {
+		loadingRules.ExplicitPath, _ = cmd.Flags().GetString("kubeconfig")
+	}
+
+	overrides := *kubeconfigOverrides
+	if context != "" {
+		overrides.CurrentContext = context
+	}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &overrides)
 }
@@ -0,0 +1,149 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package tools
+
+import (
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeKubeconfig writes a kubeconfig with a single context to dir and returns its path.
+func writeKubeconfig(t *testing.T, dir string, context string, namespace string, current bool) string {
+	t.Helper()
+
+	currentContext := ""
+	if current {
+		currentContext = context
+	}
+	path := filepath.Join(dir, context+".kubeconfig")
+	content := `apiVersion: v1
+kind: Config
+clusters:
+- name: ` + context + `
+  cluster:
+    server: https://` + context + `.test:6443
+users:
+- name: ` + context + `
+  user:
+    token: token-` + context + `
+contexts:
+- name: ` + context + `
+  context:
+    cluster: ` + context + `
+    user: ` + context + `
+    namespace: ` + namespace + `
+current-context: "` + currentContext + `"
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("write kubeconfig: %v", err)
+	}
+	return path
+}
+
+// newKubeconfigCmd creates a command with the kubeconfig flags and parses args.
+func newKubeconfigCmd(t *testing.T, args ...string) *cobra.Command {
+	t.Helper()
+
+	cmd := &cobra.Command{}
+	AddKubeconfigFlags(cmd.Flags())
+	cmd.Flags().StringSliceP("clusters", "", nil, "")
+	if err := cmd.ParseFlags(args); err != nil {
+		t.Fatalf("parse flags: %v", err)
+	}
+	return cmd
+}
+
+func TestKubeconfigFromEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	prod := writeKubeconfig(t, dir, "prod", "tenant1-w1", false)
+	staging := writeKubeconfig(t, dir, "staging", "tenant1-w2", true)
+	t.Setenv("KUBECONFIG", prod+string(os.PathListSeparator)+staging)
+
+	namespace, err := GetNamespaceFromUserConfig(newKubeconfigCmd(t))
+	if err != nil {
+		t.Fatalf("GetNamespaceFromUserConfig: %v", err)
+	}
+	if namespace != "tenant1-w2" {
+		t.Errorf("expected namespace of the current context tenant1-w2, got %s", namespace)
+	}
+
+	_, config, err := GetUserClient(newKubeconfigCmd(t, "--context", "prod", "--as", "admin"))
+	if err != nil {
+		t.Fatalf("GetUserClient: %v", err)
+	}
+	if config.Host != "https://prod.test:6443" || config.Impersonate.UserName != "admin" {
+		t.Errorf("expected the prod cluster impersonating admin, got %s as %q", config.Host, config.Impersonate.UserName)
+	}
+
+	namespace, _ = GetNamespaceFromUserConfig(newKubeconfigCmd(t, "--context", "prod"))
+	if namespace != "tenant1-w1" {
+		t.Errorf("expected namespace of the prod context tenant1-w1, got %s", namespace)
+	}
+	namespace, _ = GetNamespaceFromUserConfig(newKubeconfigCmd(t, "-n", "tenant2-w1"))
+	if namespace != "tenant2-w1" {
+		t.Errorf("expected the namespace of the flag tenant2-w1, got %s", namespace)
+	}
+
+	clusters, err := GetClusterNames(newKubeconfigCmd(t, "--clusters", "all"))
+	if err != nil {
+		t.Fatalf("GetClusterNames: %v", err)
+	}
+	if !reflect.DeepEqual(clusters, []string{"prod", "staging"}) {
+		t.Errorf("expected the contexts of both files, got %v", clusters)
+	}
+}
+
+func TestKubeconfigFlagOverridesEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("KUBECONFIG", writeKubeconfig(t, dir, "prod", "tenant1-w1", true))
+	explicit := writeKubeconfig(t, dir, "staging", "tenant1-w2", true)
+
+	namespace, err := GetNamespaceFromUserConfig(newKubeconfigCmd(t, "-k", explicit))
+	if err != nil {
+		t.Fatalf("GetNamespaceFromUserConfig: %v", err)
+	}
+	if namespace != "tenant1-w2" {
+		t.Errorf("expected namespace of the explicit kubeconfig tenant1-w2, got %s", namespace)
+	}
+}
+
+func TestSetNamespaceInUserConfig(t *testing.T) {
+	dir := t.TempDir()
+	prod := writeKubeconfig(t, dir, "prod", "tenant1-w1", true)
+	staging := writeKubeconfig(t, dir, "staging", "tenant1-w1", false)
+	t.Setenv("KUBECONFIG", prod+string(os.PathListSeparator)+staging)
+
+	if err := SetNamespaceInUserConfig(newKubeconfigCmd(t), "tenant1-w2"); err != nil {
+		t.Fatalf("SetNamespaceInUserConfig: %v", err)
+	}
+	if namespace, _ := GetNamespaceFromUserConfig(newKubeconfigCmd(t)); namespace != "tenant1-w2" {
+		t.Errorf("expected namespace tenant1-w2 in the current context, got %s", namespace)
+	}
+	if namespace, _ := GetNamespaceFromUserConfig(newKubeconfigCmd(t, "--context", "staging")); namespace != "tenant1-w1" {
+		t.Errorf("expected the other context to be unchanged, got %s", namespace)
+	}
+}
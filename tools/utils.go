@@ -25,12 +25,10 @@ package tools
 
 import (
 	"bufio"
-	"context"
 	"fmt"
 	"github.com/briandowns/spinner"
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/clientcmd/api"
 	"os"
@@ -58,17 +56,61 @@ const KUFAST_NODE_GROUP_LABEL = "kufast.group/"
 // KUFAST_TENANT_LABEL returns the default label for a tenant object
 const KUFAST_TENANT_LABEL = "kufast/tenant"
 
-// HandleError prints the error message given to it, prints the cobra commands help and exits the program
+// KUFAST_TENANT_CREDENTIALS_ROTATED_ANNOTATION returns the annotation recording when the credentials of a tenant
+// have been rotated or revoked last
+const KUFAST_TENANT_CREDENTIALS_ROTATED_ANNOTATION = "kufast/credentials-rotated"
+
+// KUFAST_NAMESPACE_NODE_SELECTOR_ANNOTATION returns the annotation used by the PodNodeSelector admission plugin
+// to restrict a namespace to a set of nodes
+const KUFAST_NAMESPACE_NODE_SELECTOR_ANNOTATION = "scheduler.alpha.kubernetes.io/node-selector"
+
+// KUFAST_NAMESPACE_LIMITS_ANNOTATION returns the annotation recording the limits a tenant-target has been created or
+// updated with, so changes of its quota can be detected
+const KUFAST_NAMESPACE_LIMITS_ANNOTATION = "kufast/limits"
+
+// KUFAST_REQUEST_LABEL returns the label marking config maps, which store requests for tenant-targets
+const KUFAST_REQUEST_LABEL = "kufast/request"
+
+// KUFAST_REQUEST_STATE_LABEL returns the label storing the state of a request for a tenant-target
+const KUFAST_REQUEST_STATE_LABEL = "kufast/request-state"
+
+// KUFAST_API_GROUP returns the API group of the kufast custom resources
+const KUFAST_API_GROUP = "kufast.io"
+
+// KUFAST_API_VERSION returns the API version of the kufast custom resources
+const KUFAST_API_VERSION = "v1alpha1"
+
+// KUFAST_FINALIZER returns the finalizer the kufast controller uses to clean up the objects of deleted custom resources
+const KUFAST_FINALIZER = "kufast.io/cleanup"
+
+// HandleError prints the output of a dry run and the error message given to it and exits the program with the exit
+// code of the error. The help of the cobra command is only printed for usage errors, like a wrong number of arguments.
 func HandleError(err error, cmd *cobra.Command) {
-	fmt.Println("\n\n" + err.Error() + "\n\n")
-	_ = cmd.Help()
-	os.Exit(1)
+	FlushDryRunOutput(os.Stdout)
+	fmt.Fprintln(os.Stderr, "\n\n"+err.Error()+"\n\n")
+	if GetErrorKind(err) == ERROR_KIND_USAGE {
+		_ = cmd.Help()
+	}
+	os.Exit(GetExitCode(err))
 }
 
-// HandleErrorWithoutHelp prints the error message given to it and exits the program
+// HandleErrorWithoutHelp prints the output of a dry run and the error message given to it and exits the program with
+// the exit code of the error
 func HandleErrorWithoutHelp(err error) {
-	fmt.Println("\n\n" + err.Error() + "\n\n")
-	os.Exit(1)
+	FlushDryRunOutput(os.Stdout)
+	fmt.Fprintln(os.Stderr, "\n\n"+err.Error()+"\n\n")
+	os.Exit(GetExitCode(err))
+}
+
+// ExitWithErrors exits the program with the exit code of the first error, if any. The errors are not printed, so
+// commands can report the errors of several operations as they complete.
+func ExitWithErrors(errs []error) {
+	for _, err := range errs {
+		if err != nil {
+			FlushDryRunOutput(os.Stdout)
+			os.Exit(GetExitCode(err))
+		}
+	}
 }
 
 // GetDialogAnswer prints the given question to the user and expects and input to return
@@ -81,6 +123,32 @@ func GetDialogAnswer(question string) string {
 	return answer
 }
 
+// ConfirmOperation prints the message and the objects affected by a destructive operation and asks the user to
+// confirm it. The user is not asked, if the operation has been confirmed with --yes or is a dry run. If stdin is not
+// a terminal, nobody can answer, so an error is returned instead of waiting for an answer.
+func ConfirmOperation(cmd *cobra.Command, message string, objects []string) (bool, error) {
+	out := cmd.OutOrStdout()
+	fmt.Fprintln(out, message)
+	for _, object := range objects {
+		fmt.Fprintln(out, "  - "+object)
+	}
+
+	yes, _ := cmd.Flags().GetBool("yes")
+	if mode, _ := GetDryRunMode(cmd); yes || mode != DRY_RUN_NONE {
+		return true, nil
+	}
+
+	in := cmd.InOrStdin()
+	if file, ok := in.(*os.File); ok && !term.IsTerminal(int(file.Fd())) {
+		return false, NewError(ERROR_KIND_USAGE, ERROR_NO_TERMINAL)
+	}
+
+	fmt.Fprintln(out, "Continue (yes/No)?")
+	fmt.Fprint(out, ">> ")
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	return strings.TrimSpace(answer) == "yes", nil
+}
+
 // GetPasswordAnswer prints the given question to the user and expects and input to return.
 // The input is not shown on the command line
 func GetPasswordAnswer(question string) string {
@@ -90,66 +158,23 @@ func GetPasswordAnswer(question string) string {
 	return strings.TrimSpace(string(password))
 }
 
-// WriteNewUserYamlToFile writes the credentials of a tenant to file. If the tenant has no tenant-target yet,
-// the default namespace is set to the tenant-target user.
-func WriteNewUserYamlToFile(tenantName string, cmd *cobra.Command, s *spinner.Spinner) error {
-
-	clientset, clientConfig, err := GetUserClient(cmd)
-	if err != nil {
-		return err
-	}
-
-	tenant, errUser := clientset.CoreV1().ServiceAccounts("default").Get(context.TODO(), tenantName+"-user", metav1.GetOptions{})
-	if errUser != nil {
-		return err
-	}
-
-	secret, errSecret := clientset.CoreV1().Secrets("default").Get(context.TODO(), tenant.Secrets[0].Name, metav1.GetOptions{})
-	if errSecret != nil {
-		return err
-	}
-
-	out, _ := cmd.Flags().GetString("output")
-
-	newConfig := api.Config{
-		Kind:       "Config",
-		APIVersion: "v1",
-		Clusters: map[string]*api.Cluster{
-			"default-cluster": {
-				Server:                   clientConfig.Host,
-				CertificateAuthorityData: secret.Data["ca.crt"],
-			},
-		},
-		AuthInfos: map[string]*api.AuthInfo{
-			tenantName + "-user": {
-				Token: string(secret.Data["token"]),
-			},
-		},
-		Contexts: map[string]*api.Context{
-			"default-context": {
-				Cluster:   "default-cluster",
-				Namespace: tenantName,
-				AuthInfo:  tenantName + "-user",
-			},
-		},
-		CurrentContext: "default-context",
-	}
+// WriteNewUserYamlToFile writes the credentials of a tenant to a file in outputDir. If the tenant has no tenant-target yet,
+// a warning is printed.
+func WriteNewUserYamlToFile(tenantName string, config *api.Config, outputDir string, s *spinner.Spinner) error {
 
-	if tenant.ObjectMeta.Labels[KUFAST_TENANT_DEFAULT_LABEL] != "" {
-		newConfig.Contexts["default-context"].Namespace = tenantName + "-" + tenant.ObjectMeta.Labels[KUFAST_TENANT_DEFAULT_LABEL]
-	} else {
+	if config.Contexts[config.CurrentContext] != nil && config.Contexts[config.CurrentContext].Namespace == tenantName {
 		s.Stop()
 		fmt.Println("Warning: No tenant-target specified! Consider to regenerate the tenants credentials after you created one" +
 			" to avoid side effects!")
 		s.Start()
 	}
 
-	err = clientcmd.WriteToFile(newConfig, out+"/"+tenantName+".kubeconfig")
+	err := clientcmd.WriteToFile(*config, outputDir+"/"+tenantName+".kubeconfig")
 	if err != nil {
 		return err
 	} else {
 		s.Stop()
-		fmt.Println("Config for tenant " + tenantName + " written to " + out + "/" + tenantName + ".kubeconfig")
+		fmt.Println("Config for tenant " + tenantName + " written to " + outputDir + "/" + tenantName + ".kubeconfig")
 		s.Start()
 	}
 	return nil
@@ -0,0 +1,93 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package tools
+
+import (
+	"bytes"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+	"testing"
+)
+
+// newConfirmTestCmd creates a command with the flags read by ConfirmOperation. The command reads the given input.
+func newConfirmTestCmd(t *testing.T, input string, args ...string) (*cobra.Command, *bytes.Buffer) {
+	t.Helper()
+
+	cmd := &cobra.Command{}
+	cmd.Flags().BoolP("yes", "y", false, "")
+	AddDryRunFlags(cmd)
+	if err := cmd.ParseFlags(args); err != nil {
+		t.Fatalf("parse %v: %v", args, err)
+	}
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetIn(strings.NewReader(input))
+	return cmd, &out
+}
+
+func TestConfirmOperation(t *testing.T) {
+	objects := []string{"namespace/tenant1-w1", "pod/nginx (tenant1-w1)"}
+
+	cmd, out := newConfirmTestCmd(t, "yes\n")
+	confirmed, err := ConfirmOperation(cmd, "The following objects will be deleted:", objects)
+	if err != nil || !confirmed {
+		t.Fatalf("expected the operation to be confirmed, got %v (%v)", confirmed, err)
+	}
+	expected := "The following objects will be deleted:\n  - namespace/tenant1-w1\n  - pod/nginx (tenant1-w1)\nContinue (yes/No)?\n>> "
+	if out.String() != expected {
+		t.Errorf("expected prompt %q, got %q", expected, out.String())
+	}
+
+	cmd, _ = newConfirmTestCmd(t, "y\n")
+	if confirmed, _ := ConfirmOperation(cmd, "", objects); confirmed {
+		t.Errorf("expected only 'yes' to confirm the operation")
+	}
+
+	for _, args := range [][]string{{"--yes"}, {"--dry-run=server"}} {
+		cmd, out = newConfirmTestCmd(t, "", args...)
+		confirmed, err = ConfirmOperation(cmd, "The following objects will be deleted:", objects)
+		if err != nil || !confirmed {
+			t.Errorf("%v: expected the operation to be confirmed without asking, got %v (%v)", args, confirmed, err)
+		}
+		if strings.Contains(out.String(), "Continue") || !strings.Contains(out.String(), "namespace/tenant1-w1") {
+			t.Errorf("%v: expected the objects to be listed without a question, got %q", args, out.String())
+		}
+	}
+}
+
+func TestConfirmOperationWithoutTerminal(t *testing.T) {
+	file, err := os.CreateTemp(t.TempDir(), "stdin")
+	if err != nil {
+		t.Fatalf("create stdin: %v", err)
+	}
+	defer file.Close()
+
+	cmd, _ := newConfirmTestCmd(t, "")
+	cmd.SetIn(file)
+	confirmed, err := ConfirmOperation(cmd, "The following objects will be deleted:", nil)
+	if confirmed || GetErrorKind(err) != ERROR_KIND_USAGE || err.Error() != ERROR_NO_TERMINAL {
+		t.Errorf("expected the operation to be refused, got %v (%v)", confirmed, err)
+	}
+}
@@ -0,0 +1,276 @@
+/*
+MIT License
+
+Copyright (c) 2023 Stefan Pawlowski
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package tools
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"strings"
+	"time"
+)
+
+// View is implemented by all kufast view structs. Views are the stable, machine-readable representation of kufast
+// objects and are printed by PrintViews.
+type View interface {
+	// ViewName returns the name of the view in the form <kind>/<name>.
+	ViewName() string
+}
+
+// ResourcesView represents an amount of CPU, memory, storage and pods. Missing values are left empty.
+type ResourcesView struct {
+	CPU     string `json:"cpu,omitempty"`
+	Memory  string `json:"memory,omitempty"`
+	Storage string `json:"storage,omitempty"`
+	Pods    string `json:"pods,omitempty"`
+}
+
+// TenantView represents a tenant and the targets it has access to.
+type TenantView struct {
+	Cluster       string    `json:"cluster,omitempty"`
+	Name          string    `json:"name"`
+	DefaultTarget string    `json:"defaultTarget,omitempty"`
+	Targets       []Target  `json:"targets"`
+	CreatedAt     time.Time `json:"createdAt"`
+	// CredentialsRotatedAt is the time the credentials of the tenant have been rotated or revoked last.
+	CredentialsRotatedAt string `json:"credentialsRotatedAt,omitempty"`
+}
+
+// TenantTargetView represents a tenant-target with its limits and their usage.
+type TenantTargetView struct {
+	Name         string        `json:"name"`
+	Tenant       string        `json:"tenant"`
+	Target       string        `json:"target"`
+	Status       string        `json:"status"`
+	NodeSelector string        `json:"nodeSelector,omitempty"`
+	Limits       ResourcesView `json:"limits"`
+	Requests     ResourcesView `json:"requests"`
+	Used         ResourcesView `json:"used"`
+	Pods         int           `json:"pods"`
+}
+
+// PodView represents a pod of a tenant.
+type PodView struct {
+	Cluster       string        `json:"cluster,omitempty"`
+	Name          string        `json:"name"`
+	TenantTarget  string        `json:"tenantTarget"`
+	Status        string        `json:"status"`
+	Message       string        `json:"message,omitempty"`
+	Node          string        `json:"node,omitempty"`
+	IP            string        `json:"ip,omitempty"`
+	Image         string        `json:"image"`
+	RestartPolicy string        `json:"restartPolicy,omitempty"`
+	Restarts      int32         `json:"restarts"`
+	Limits        ResourcesView `json:"limits"`
+	Requests      ResourcesView `json:"requests"`
+	Events        []EventView   `json:"events,omitempty"`
+	CreatedAt     time.Time     `json:"createdAt"`
+}
+
+// EventView represents an event that occurred to a kufast object.
+type EventView struct {
+	Time    time.Time `json:"time"`
+	Reason  string    `json:"reason"`
+	Message string    `json:"message"`
+}
+
+// SecretView represents a secret or deploy-secret of a tenant. The data is only populated for single secrets.
+type SecretView struct {
+	Name         string    `json:"name"`
+	TenantTarget string    `json:"tenantTarget"`
+	Type         string    `json:"type"`
+	Data         string    `json:"data,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// RequestView represents a request of a tenant for a tenant-target.
+type RequestView struct {
+	ID         string        `json:"id"`
+	Tenant     string        `json:"tenant"`
+	Target     string        `json:"target"`
+	Limits     ResourcesView `json:"limits"`
+	MinStorage string        `json:"minStorage,omitempty"`
+	State      string        `json:"state"`
+	Reason     string        `json:"reason,omitempty"`
+	CreatedAt  time.Time     `json:"createdAt"`
+}
+
+// ViewName returns the name of the tenant in the form tenant/<name>.
+func (v TenantView) ViewName() string {
+	return "tenant/" + v.Name
+}
+
+// ViewName returns the name of the tenant-target in the form tenant-target/<name>.
+func (v TenantTargetView) ViewName() string {
+	return "tenant-target/" + v.Name
+}
+
+// ViewName returns the name of the pod in the form pod/<name>.
+func (v PodView) ViewName() string {
+	return "pod/" + v.Name
+}
+
+// ViewName returns the name of the secret in the form secret/<name>.
+func (v SecretView) ViewName() string {
+	return "secret/" + v.Name
+}
+
+// ViewName returns the name of the request in the form request/<id>.
+func (v RequestView) ViewName() string {
+	return "request/" + v.ID
+}
+
+// NewTenantView creates the view of a tenant from its service account and its targets.
+func NewTenantView(tenant *v1.ServiceAccount, targets []Target) TenantView {
+	if targets == nil {
+		targets = []Target{}
+	}
+	return TenantView{
+		Name:          tenant.ObjectMeta.Labels[KUFAST_TENANT_LABEL],
+		DefaultTarget: tenant.ObjectMeta.Labels[KUFAST_TENANT_DEFAULT_LABEL],
+		Targets:       targets,
+		CreatedAt:     tenant.CreationTimestamp.Time,
+
+		CredentialsRotatedAt: tenant.ObjectMeta.Annotations[KUFAST_TENANT_CREDENTIALS_ROTATED_ANNOTATION],
+	}
+}
+
+// NewTenantTargetView creates the view of a tenant-target from its namespace, its quota and its pods.
+// The quota may be nil, if it is missing.
+func NewTenantTargetView(namespace *v1.Namespace, quota *v1.ResourceQuota, pods []v1.Pod) TenantTargetView {
+	tenantName := namespace.ObjectMeta.Labels[KUFAST_TENANT_LABEL]
+	if tenantName == "" {
+		tenantName = GetTenantFromNamespace(namespace.Name)
+	}
+
+	view := TenantTargetView{
+		Name:         namespace.Name,
+		Tenant:       tenantName,
+		Target:       strings.TrimPrefix(namespace.Name, tenantName+"-"),
+		Status:       string(namespace.Status.Phase),
+		NodeSelector: namespace.ObjectMeta.Annotations[KUFAST_NAMESPACE_NODE_SELECTOR_ANNOTATION],
+		Pods:         len(pods),
+	}
+
+	if quota != nil {
+		view.Limits = ResourcesView{
+			CPU:     quantityString(quota.Spec.Hard, "limits.cpu"),
+			Memory:  quantityString(quota.Spec.Hard, "limits.memory"),
+			Storage: quantityString(quota.Spec.Hard, "limits.ephemeral-storage"),
+			Pods:    quantityString(quota.Spec.Hard, "pods"),
+		}
+		view.Requests = ResourcesView{
+			CPU:     quantityString(quota.Spec.Hard, "requests.cpu"),
+			Memory:  quantityString(quota.Spec.Hard, "requests.memory"),
+			Storage: quantityString(quota.Spec.Hard, "requests.ephemeral-storage"),
+		}
+		view.Used = ResourcesView{
+			CPU:     quantityString(quota.Status.Used, "limits.cpu"),
+			Memory:  quantityString(quota.Status.Used, "limits.memory"),
+			Storage: quantityString(quota.Status.Used, "limits.ephemeral-storage"),
+			Pods:    quantityString(quota.Status.Used, "pods"),
+		}
+	}
+	return view
+}
+
+// NewPodView creates the view of a pod. Events are optional and may be nil.
+func NewPodView(pod *v1.Pod, events []v1.Event) PodView {
+	view := PodView{
+		Name:          pod.Name,
+		TenantTarget:  pod.Namespace,
+		Status:        string(pod.Status.Phase),
+		Message:       pod.Status.Message,
+		Node:          pod.Spec.NodeName,
+		IP:            pod.Status.PodIP,
+		RestartPolicy: string(pod.Spec.RestartPolicy),
+		CreatedAt:     pod.CreationTimestamp.Time,
+	}
+
+	if len(pod.Spec.Containers) > 0 {
+		container := pod.Spec.Containers[0]
+		view.Image = container.Image
+		view.Limits = ResourcesView{
+			CPU:     quantityString(container.Resources.Limits, "cpu"),
+			Memory:  quantityString(container.Resources.Limits, "memory"),
+			Storage: quantityString(container.Resources.Limits, "ephemeral-storage"),
+		}
+		view.Requests = ResourcesView{
+			CPU:     quantityString(container.Resources.Requests, "cpu"),
+			Memory:  quantityString(container.Resources.Requests, "memory"),
+			Storage: quantityString(container.Resources.Requests, "ephemeral-storage"),
+		}
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		view.Restarts += status.RestartCount
+	}
+	for _, event := range events {
+		view.Events = append(view.Events, EventView{
+			Time:    event.CreationTimestamp.Time,
+			Reason:  event.Reason,
+			Message: event.Message,
+		})
+	}
+	return view
+}
+
+// NewSecretView creates the view of a secret. The data is only included if dataKey is not empty.
+func NewSecretView(secret *v1.Secret, dataKey string) SecretView {
+	view := SecretView{
+		Name:         secret.Name,
+		TenantTarget: secret.Namespace,
+		Type:         string(secret.Type),
+		CreatedAt:    secret.CreationTimestamp.Time,
+	}
+	if dataKey != "" {
+		view.Data = string(secret.Data[dataKey])
+	}
+	return view
+}
+
+// NewRequestView creates the view of a request for a tenant-target from the config map storing it.
+func NewRequestView(request *v1.ConfigMap) RequestView {
+	return RequestView{
+		ID:     request.Data["id"],
+		Tenant: request.Data["tenant"],
+		Target: request.Data["target"],
+		Limits: ResourcesView{
+			CPU:     request.Data["cpu"],
+			Memory:  request.Data["memory"],
+			Storage: request.Data["storage"],
+			Pods:    request.Data["pods"],
+		},
+		MinStorage: request.Data["minStorage"],
+		State:      request.Labels[KUFAST_REQUEST_STATE_LABEL],
+		Reason:     request.Data["reason"],
+		CreatedAt:  request.CreationTimestamp.Time,
+	}
+}
+
+// quantityString returns the quantity stored under name in the resource list or an empty string, if it does not exist.
+func quantityString(list v1.ResourceList, name v1.ResourceName) string {
+	qty, ok := list[name]
+	if !ok {
+		return ""
+	}
+	return qty.String()
+}